@@ -1,19 +1,23 @@
 package crumbler
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/ariel-frischer/claude-clean/display"
 	"github.com/ariel-frischer/claude-clean/parser"
-)
-
-const (
-	defaultChunkSize = 8 * 1024  // 8KB chunks
-	maxBufferSize    = 10 * 1024 * 1024 // 10MB max buffer
+	"github.com/fatih/color"
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/redact"
+	"golang.org/x/term"
 )
 
 // runClean handles the 'crumbler clean' command.
@@ -31,23 +35,138 @@ func runClean(args []string) error {
 		Verbose:     false,
 		ShowLineNum: false,
 	}
-	var showUsage bool
-	var inputFile string
+	opts := &cleanOptions{filter: newMessageFilter()}
+	var inputFile, teeFile, sessionFile string
+	format := "auto"
+	var output string
+	colorMode := "auto"
+	maxOutputLines := defaultMaxOutputLines
+	var follow, record, enforceBudget, noRedact, failOnError, live bool
 
 	// Simple flag parsing
-	for i, arg := range args {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
 		switch arg {
 		case "-s", "--style":
 			if i+1 < len(args) {
-				cfg.Style = display.OutputStyle(args[i+1])
+				i++
+				cfg.Style = display.OutputStyle(args[i])
 			}
 		case "-v", "--verbose":
 			cfg.Verbose = true
 		case "-l", "--line-numbers":
 			cfg.ShowLineNum = true
+		case "-f", "--follow":
+			follow = true
 		case "-V", "--usage":
-			showUsage = true
+			opts.showUsage = true
+		case "--record":
+			record = true
+		case "--enforce-budget":
+			enforceBudget = true
+		case "--no-redact":
+			noRedact = true
+		case "--fail-on-error":
+			failOnError = true
+		case "--live":
+			live = true
+		case "--summary":
+			opts.summary = newSessionSummary()
+		case "--summary=json":
+			opts.summary = newSessionSummary()
+			opts.summaryJSON = true
+		case "--summary=text":
+			opts.summary = newSessionSummary()
+		case "--changes", "--changes=text":
+			opts.changes = newChangeManifest()
+		case "--changes=json":
+			opts.changes = newChangeManifest()
+			opts.changesJSON = true
+		case "--format":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--format requires a format (e.g. codex)")
+			}
+			i++
+			format = args[i]
+		case "--output":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--output requires a format (md or html)")
+			}
+			i++
+			output = args[i]
+			if output == "markdown" {
+				output = "md"
+			}
+			if output != "md" && output != "html" {
+				return fmt.Errorf("unknown output format %q (use md or html)", args[i])
+			}
+		case "--color":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--color requires auto, always or never")
+			}
+			i++
+			colorMode = args[i]
+		case "--max-output-lines":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--max-output-lines requires a number of lines")
+			}
+			i++
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid --max-output-lines %q (use a number of lines, or 0 for all)", args[i])
+			}
+			maxOutputLines = n
+		case "--tee":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--tee requires a file")
+			}
+			i++
+			teeFile = args[i]
+		case "--session-file":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--session-file requires a file")
+			}
+			i++
+			sessionFile = args[i]
+		case "--only", "--exclude":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a list of kinds (e.g. assistant,tool)", arg)
+			}
+			i++
+			set := opts.filter.only
+			if arg == "--exclude" {
+				set = opts.filter.exclude
+			}
+			if err := addKinds(set, args[i]); err != nil {
+				return err
+			}
+		case "--tool":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--tool requires a tool name (e.g. Bash)")
+			}
+			i++
+			opts.filter.addTools(args[i])
+		case "--grep":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--grep requires a regular expression")
+			}
+			i++
+			re, err := regexp.Compile(args[i])
+			if err != nil {
+				return fmt.Errorf("invalid --grep pattern: %w", err)
+			}
+			opts.filter.grep = re
 		default:
+			if strings.HasPrefix(arg, "--summary=") {
+				return fmt.Errorf("unknown summary format %q (use text or json)", strings.TrimPrefix(arg, "--summary="))
+			}
+			if strings.HasPrefix(arg, "--color=") {
+				colorMode = strings.TrimPrefix(arg, "--color=")
+				continue
+			}
+			if strings.HasPrefix(arg, "--changes=") {
+				return fmt.Errorf("unknown changes format %q (use text or json)", strings.TrimPrefix(arg, "--changes="))
+			}
 			// If it's not a flag and doesn't start with -, treat as input file
 			if !strings.HasPrefix(arg, "-") && inputFile == "" {
 				inputFile = arg
@@ -55,9 +174,79 @@ func runClean(args []string) error {
 		}
 	}
 
+	if colorMode != "auto" && colorMode != "always" && colorMode != "never" {
+		return fmt.Errorf("unknown color mode %q (use auto, always or never)", colorMode)
+	}
+	if (opts.summaryJSON || opts.changesJSON) && opts.summary != nil && opts.changes != nil {
+		return fmt.Errorf("--summary and --changes can't be combined when either is json; run clean once for each")
+	}
+	if output != "" {
+		// The document has its own usage table
+		if opts.summary != nil {
+			return fmt.Errorf("--summary can't be combined with --output; the document includes the summary")
+		}
+		if opts.changes != nil {
+			return fmt.Errorf("--changes can't be combined with --output")
+		}
+		opts.summary = newSessionSummary()
+		opts.doc = newTranscriptDoc()
+	}
+
+	if failOnError || sessionFile != "" {
+		opts.session = newSessionInfo()
+	}
+
+	if record || enforceBudget {
+		root, err := getProjectRoot()
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(crumblerDir(root)); err != nil || !info.IsDir() {
+			return fmt.Errorf("--record and --enforce-budget require a crumbler project (no %s found)", crumb.CrumblerDir)
+		}
+		if record {
+			opts.recorder = newCostRecorder(root)
+		}
+		if enforceBudget {
+			if opts.budget, err = newBudgetTracker(root); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Hide secrets everywhere they'd be written, unless asked not to
+	if !noRedact {
+		root, err := findProjectRoot()
+		if err != nil {
+			return err
+		}
+		if opts.redactor, err = redact.Load(root); err != nil {
+			return err
+		}
+	}
+
+	reader, err := newTranscriptReader(format)
+	if err != nil {
+		return err
+	}
+	opts.reader = reader
+
 	// Determine input source
 	var input io.Reader
-	if inputFile != "" {
+	if follow {
+		if inputFile == "" {
+			return fmt.Errorf("--follow requires a file")
+		}
+		// Follow until interrupted, then finish like at the end of a file
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		file, err := openFollow(ctx, inputFile, followPollInterval)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	} else if inputFile != "" {
 		file, err := os.Open(inputFile)
 		if err != nil {
 			return fmt.Errorf("failed to open file: %w", err)
@@ -69,89 +258,147 @@ func runClean(args []string) error {
 		input = os.Stdin
 	}
 
-	// Process input stream
-	return processJSONStream(input, cfg, showUsage)
-}
-
-// skipWhitespace removes leading whitespace from buffer, handling CRLF, LF, CR, spaces, and tabs.
-// Returns the number of bytes skipped.
-func skipWhitespace(buffer []byte) int {
-	start := 0
-	for start < len(buffer) {
-		b := buffer[start]
-		if b == ' ' || b == '\t' {
-			start++
-		} else if b == '\r' {
-			// Handle CRLF (\r\n) as a unit, or standalone CR
-			if start+1 < len(buffer) && buffer[start+1] == '\n' {
-				start += 2 // Skip CRLF
-			} else {
-				start++ // Skip standalone CR
-			}
-		} else if b == '\n' {
-			start++ // Skip LF
-		} else {
-			break // Non-whitespace found
+	// Keep the original bytes when asked, whatever gets rendered
+	if teeFile != "" {
+		tee, err := os.Create(teeFile)
+		if err != nil {
+			return fmt.Errorf("failed to create tee file: %w", err)
+		}
+		defer tee.Close()
+		opts.tee = tee
+		if opts.redactor != nil {
+			opts.tee = newRedactWriter(tee, opts.redactor.String)
 		}
 	}
-	return start
-}
 
-// findCompleteJSON finds the first complete JSON object in the buffer by tracking brace depth.
-// Returns the start and end indices of the JSON object, or -1, -1 if no complete object found.
-// Handles strings and escape sequences correctly.
-func findCompleteJSON(buffer []byte) (start, end int) {
-	start = -1
-	end = -1
-	depth := 0
-	inString := false
-	escapeNext := false
-
-	for i := 0; i < len(buffer); i++ {
-		b := buffer[i]
-
-		if escapeNext {
-			escapeNext = false
-			continue
-		}
+	// Fit the output to the terminal, if it's one
+	terminal := term.IsTerminal(int(os.Stdout.Fd()))
+	color.NoColor = !useColor(colorMode, terminal, os.Getenv)
+	renderer := newTermRenderer(os.Stdout, cfg, terminalWidth)
+	renderer.maxLines = maxOutputLines
+	opts.renderer = renderer
+
+	// Pin a status line below the output, if it's a terminal
+	if live && terminal {
+		opts.live = newLiveStatus(os.Stdout, terminalWidth, liveCrumb())
+		opts.live.run(liveInterval)
+		defer opts.live.stop()
+	}
 
-		if b == '\\' {
-			escapeNext = true
-			continue
+	// Process input stream
+	if err := processJSONStream(input, cfg, opts); err != nil {
+		return err
+	}
+	if opts.live != nil {
+		opts.live.stop()
+	}
+	if w, ok := opts.tee.(*redactWriter); ok {
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("error writing tee file: %w", err)
 		}
-
-		if b == '"' {
-			inString = !inString
-			continue
+	}
+	if opts.recorder != nil {
+		if err := opts.recorder.finish(); err != nil {
+			return err
 		}
+	}
+	if sessionFile != "" {
+		if err := opts.session.writeFile(sessionFile); err != nil {
+			return err
+		}
+	}
 
-		if inString {
-			continue
+	// Write the reports even when the budget stops the run
+	if err := writeCleanReports(output, opts); err != nil {
+		return err
+	}
+	if opts.budget != nil {
+		if opts.budget.err != nil {
+			return opts.budget.err
+		}
+		if exceeded := opts.budget.exceeded; exceeded != nil {
+			exceeded.writeBanner(os.Stderr)
+			return &exitError{code: exitBudgetExceeded, err: fmt.Errorf("budget exceeded for %s", exceeded.budget.Crumb)}
 		}
+	}
+	if failOnError {
+		if err := opts.session.failure(); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return err
+		}
+	}
+	return nil
+}
 
-		// Track brace depth to find complete objects
-		if b == '{' {
-			if start == -1 {
-				start = i
-			}
-			depth++
-		} else if b == '}' {
-			depth--
-			if depth == 0 && start != -1 {
-				end = i + 1
-				break
-			}
+// writeCleanReports prints what clean collected at the end of the stream:
+// the exported document, or the changes and summary.
+func writeCleanReports(output string, opts *cleanOptions) error {
+	switch output {
+	case "md":
+		return opts.doc.writeMarkdown(os.Stdout, opts.summary)
+	case "html":
+		return opts.doc.writeHTML(os.Stdout, opts.summary)
+	}
+
+	if opts.changes != nil {
+		if opts.changesJSON {
+			return opts.changes.writeJSON(os.Stdout)
 		}
+		fmt.Println()
+		if err := opts.changes.writeText(os.Stdout); err != nil {
+			return err
+		}
+	}
+	if opts.summary != nil {
+		if opts.summaryJSON {
+			return opts.summary.writeJSON(os.Stdout)
+		}
+		fmt.Println()
+		return opts.summary.writeText(os.Stdout)
 	}
+	return nil
+}
 
-	return start, end
+// cleanOptions controls what processJSONStream does besides rendering.
+type cleanOptions struct {
+	showUsage   bool              // Show per-message usage
+	summary     *sessionSummary   // Aggregates the stream if set
+	summaryJSON bool              // Print only the summary, as JSON
+	changes     *changeManifest   // Collects changed files and commands if set
+	changesJSON bool              // Print only the changes, as JSON
+	session     *sessionInfo      // Tracks how the session ends if set
+	live        *liveStatus       // Keeps a status line below the output if set
+	renderer    messageRenderer   // Draws messages; os.Stdout in cfg's style if nil
+	filter      *messageFilter    // Decides which messages are rendered
+	reader      *transcriptReader // Converts objects from the input format
+	doc         *transcriptDoc    // Collects messages for export if set
+	tee         io.Writer         // Receives the original input bytes if set
+	recorder    *costRecorder     // Credits usage to crumbs if set
+	budget      *budgetTracker    // Stops the stream when a budget is exceeded if set
+	redactor    *redact.Redactor  // Hides secrets in the input if set
 }
 
-// processJSONStream processes a stream of JSON objects from the input reader.
-// It reads in chunks, accumulates complete JSON objects, and processes them.
-func processJSONStream(input io.Reader, cfg *display.Config, showUsage bool) (err error) {
-	// Top-level panic recovery to ensure we never crash with nonzero exit
-	// Even if the library panics, we want to exit successfully (exit code 0)
+// jsonOnly reports whether only a JSON report is printed, not the
+// transcript.
+func (opts *cleanOptions) jsonOnly() bool {
+	return opts.summaryJSON || opts.changesJSON
+}
+
+// processJSONStream processes a transcript from the input reader. JSON
+// values are decoded as they arrive and rendered as messages; lines that
+// aren't JSON are printed as they are.
+func processJSONStream(input io.Reader, cfg *display.Config, opts *cleanOptions) (err error) {
+	if opts == nil {
+		opts = &cleanOptions{}
+	}
+	if opts.reader == nil {
+		opts.reader = &transcriptReader{}
+	}
+	if opts.renderer == nil {
+		opts.renderer = newTermRenderer(os.Stdout, cfg, nil)
+	}
+
+	// A bug in handling one message shouldn't fail the whole stream
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Fprintf(os.Stderr, "warning: recovered from panic in processJSONStream: %v\n", r)
@@ -160,109 +407,159 @@ func processJSONStream(input io.Reader, cfg *display.Config, showUsage bool) (er
 		}
 	}()
 
-	buffer := make([]byte, 0, defaultChunkSize*2)
-	chunk := make([]byte, defaultChunkSize)
+	dec := newStreamDecoder(input, opts.tee)
+	if opts.redactor != nil {
+		dec.redact = opts.redactor.String
+	}
 	lineNum := 0
-
-	var readErr error
 	for {
-		// Read chunk from input
-		n, err := input.Read(chunk)
-		if n > 0 {
-			// Append chunk to buffer
-			buffer = append(buffer, chunk[:n]...)
-		}
-		
-		// Track EOF separately - Read can return data AND EOF
-		if err == io.EOF {
-			readErr = io.EOF
+		rec, err := dec.next()
+		var incomplete *incompleteError
+		if errors.As(err, &incomplete) {
+			if cfg.Verbose {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+			}
+			break
+		} else if err == io.EOF {
+			break
 		} else if err != nil {
 			return fmt.Errorf("error reading input: %w", err)
 		}
-
-		// Process complete JSON objects from buffer
-		processed := false
-		for {
-			// Skip leading whitespace
-			skip := skipWhitespace(buffer)
-			if skip > 0 {
-				buffer = buffer[skip:]
-			}
-
-			// Find complete JSON object
-			jsonStart, jsonEnd := findCompleteJSON(buffer)
-
-			// If we found a complete JSON object, parse and display it
-			if jsonEnd > 0 && jsonStart >= 0 {
-				jsonData := buffer[jsonStart:jsonEnd]
-
-				// Wrap processing in panic recovery to prevent crashes
-				func() {
-					defer func() {
-						if r := recover(); r != nil {
-							// Always log panic recovery - library crashes should be visible
-							fmt.Fprintf(os.Stderr, "warning: recovered from panic at offset %d: %v\n", jsonStart, r)
-							if cfg.Verbose {
-								// In verbose mode, also show the JSON that caused the panic
-								fmt.Fprintf(os.Stderr, "  JSON data: %s\n", string(jsonData))
-							}
-						}
-					}()
-
-					// Parse JSON object
-					var msg parser.StreamMessage
-					if err := json.Unmarshal(jsonData, &msg); err != nil {
-						// Skip invalid JSON
-						if cfg.Verbose {
-							fmt.Fprintf(os.Stderr, "warning: skipped invalid JSON at offset %d: %v\n", jsonStart, err)
-						}
-					} else {
-						lineNum++
-						// Display the message (may panic on unexpected formats)
-						display.DisplayMessage(&msg, lineNum, cfg)
-
-						// Show usage if requested (may panic)
-						if showUsage && msg.Usage != nil {
-							display.DisplayUsage(msg.Usage)
-						}
+		lineNum++
+
+		// Skip a record that can't be handled, and say so
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Fprintf(os.Stderr, "warning: recovered from panic at record %d: %v\n", lineNum, r)
+					if cfg.Verbose {
+						// In verbose mode, also show the JSON that caused the panic
+						fmt.Fprintf(os.Stderr, "  JSON data: %s\n", string(rec.value))
 					}
-				}()
+				}
+			}()
 
-				// Remove processed JSON from buffer
-				buffer = buffer[jsonEnd:]
-				processed = true
-				continue // Try to find another complete object
+			if rec.value == nil {
+				handleText(rec.text, opts)
+				return
+			}
+			// Parse JSON value into messages
+			msgs, err := opts.reader.read(rec.value)
+			if err != nil {
+				handleRaw(rec.value, lineNum, err, opts)
+				return
+			}
+			for i := range msgs {
+				handleMessage(&msgs[i], rec.value, lineNum, cfg, opts)
 			}
+		}()
 
-			// No complete object found, break and read more data
-			break
+		if opts.budget != nil && opts.budget.exceeded != nil {
+			return nil // Stop reading; the caller reports it
 		}
+	}
 
-		// If we've hit EOF and processed everything we can, exit
-		if readErr == io.EOF {
-			// Check if there's remaining data in buffer (incomplete JSON)
-			remaining := strings.TrimSpace(string(buffer))
-			if remaining != "" {
-				if cfg.Verbose {
-					fmt.Fprintf(os.Stderr, "warning: incomplete JSON at end of input (%d bytes)\n", len(buffer))
-				}
+	// Show anything the format held back for more input
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				fmt.Fprintf(os.Stderr, "warning: recovered from panic at end of input: %v\n", r)
 			}
-			break
+		}()
+		msgs := opts.reader.flush()
+		for i := range msgs {
+			handleMessage(&msgs[i], nil, lineNum, cfg, opts)
 		}
+	}()
+	return nil
+}
 
-		// If we didn't process anything and didn't read anything, we're stuck
-		if !processed && n == 0 && readErr == nil {
-			// This shouldn't happen with a well-behaved reader, but protect against infinite loops
-			break
-		}
+// handleText filters and prints a line of input that isn't JSON.
+func handleText(text string, opts *cleanOptions) {
+	if opts.jsonOnly() {
+		return
+	}
+	if opts.filter != nil && opts.filter.active() && !opts.filter.matchText(text) {
+		return
+	}
+	if opts.doc != nil {
+		opts.doc.addText(text)
+		return
+	}
+	if opts.live != nil {
+		opts.live.pause()
+		defer opts.live.resume()
+	}
+	fmt.Println(text)
+}
 
-		// Prevent buffer from growing unbounded
-		if len(buffer) > maxBufferSize {
-			return fmt.Errorf("error: buffer too large (max %dMB), possible malformed input", maxBufferSize/(1024*1024))
-		}
+// handleRaw filters and shows JSON that couldn't be read as a message, as
+// it is, like a line that isn't JSON.
+func handleRaw(raw []byte, lineNum int, reason error, opts *cleanOptions) {
+	if opts.jsonOnly() {
+		return
+	}
+	text := rawJSON(raw, nil, opts)
+	if opts.filter != nil && opts.filter.active() && !opts.filter.matchText(text) {
+		return
 	}
+	if opts.doc != nil {
+		opts.doc.addText(text)
+		return
+	}
+	if opts.live != nil {
+		opts.live.pause()
+		defer opts.live.resume()
+	}
+	opts.renderer.renderRaw(text, lineNum, reason)
+}
 
-	return nil
+// handleMessage records, filters and renders one parsed message. raw is the
+// JSON object it came from, if any.
+func handleMessage(msg *parser.StreamMessage, raw []byte, lineNum int, cfg *display.Config, opts *cleanOptions) {
+	if opts.summary != nil {
+		opts.summary.add(msg, raw)
+	}
+	if opts.recorder != nil {
+		opts.recorder.add(msg)
+	}
+	if opts.budget != nil {
+		opts.budget.add(msg)
+	}
+	if opts.changes != nil {
+		opts.changes.add(msg)
+	}
+	if opts.session != nil {
+		opts.session.add(msg)
+	}
+	if opts.live != nil {
+		opts.live.add(msg)
+	}
+	if opts.jsonOnly() {
+		return // Only the summary or changes are printed
+	}
+	if opts.filter != nil && opts.filter.active() && !opts.filter.match(msg) {
+		return
+	}
+
+	if opts.doc != nil {
+		opts.doc.add(msg)
+		return
+	}
+
+	if opts.live != nil {
+		opts.live.pause()
+		defer opts.live.resume()
+	}
+
+	// Show what can't be rendered as it is
+	if err := opts.renderer.render(msg, lineNum); err != nil {
+		opts.renderer.renderRaw(rawJSON(raw, msg, opts), lineNum, err)
+		return
+	}
+	if opts.showUsage && msg.Usage != nil {
+		opts.renderer.renderUsage(msg.Usage)
+	}
 }
 
 // printCleanHelp prints help for the clean command.
@@ -278,7 +575,17 @@ DESCRIPTION:
     up verbose JSON logs from Claude Code commands.
 
     The command parses JSON lines and displays them in a formatted, colorized
-    way, making it easy to read Claude Code's output.
+    way, making it easy to read Claude Code's output. JSON may span lines or
+    be a top-level array, and tool output of any size is streamed. Lines that
+    aren't JSON, like stderr mixed into the log, are printed as they are.
+
+    Edit and MultiEdit calls are shown as colored diffs of the text they
+    replace, and Write calls as diffs adding a new file, in every style.
+    Each diff shows up to 60 lines.
+
+    On a terminal, text is wrapped to its width, and colored unless NO_COLOR
+    is set. JSON that can't be shown as a message, like a message of an
+    unknown type, is shown as it is under RAW JSON, with the reason.
 
 INPUT:
     If a file is provided, reads from that file. Otherwise, reads from stdin.
@@ -289,6 +596,52 @@ OPTIONS:
     -v, --verbose           Show system reminders
     -l, --line-numbers      Show source line numbers
     -V, --usage             Show token usage statistics
+    --color WHEN            Color the output: auto (default; only on a
+                            terminal, and not if NO_COLOR is set), always or
+                            never. Also --color=WHEN
+    --max-output-lines N    Show at most N lines of each tool's output: the
+                            first and last, with a count of the lines left
+                            out (default: 40; 0 shows everything)
+    -f, --follow            Keep reading the file as it grows, like tail -f,
+                            starting from the beginning. Follows truncation
+                            and rotation; Ctrl-C stops and prints the
+                            --summary if requested
+    --summary[=FORMAT]      Print session totals at the end of the stream:
+                            tokens, cost, turns, tool calls and errors, and
+                            duration. FORMAT is text (default) or json; json
+                            prints only the summary, for scripts
+    --changes[=FORMAT]      Print the files the agent wrote or edited (Write,
+                            Edit and MultiEdit calls, counted per file) and
+                            the commands it ran with their exit status.
+                            FORMAT is text (default) or json; json prints
+                            only the changes, for scripts
+    --fail-on-error         Exit with status 2 if the agent's final result is
+                            an error, or the stream ends without a result
+    --session-file FILE     Write the session ID, model, number of turns,
+                            status and final result text to FILE as JSON
+    --live                  Keep a status line at the bottom of the terminal:
+                            elapsed time, the tool running, tokens and cost
+                            so far, tool errors and the current crumb.
+                            Ignored when the output isn't a terminal
+    --tee FILE              Also write the original input bytes to FILE,
+                            with secrets redacted
+    --only KINDS            Show only messages of these kinds (comma-separated)
+    --exclude KINDS         Hide messages of these kinds (comma-separated)
+    --tool NAMES            Show only calls to and results from these tools
+                            (comma-separated, may be repeated)
+    --grep REGEX            Show only messages whose text, tool input or
+                            tool output matches REGEX
+    --record                Credit token usage and cost to the current crumb
+                            in the project's cost ledger (see 'crumbler cost')
+    --enforce-budget        Stop with exit status 3 and a banner as soon as
+                            usage exceeds a BUDGET of the current crumb or
+                            an ancestor (see BUDGETS)
+    --no-redact             Show secrets as they are (see REDACTION)
+    --format FORMAT         Input format: auto, claude, codex, gemini, aider
+                            or openai (default: auto)
+    --output FORMAT         Write the session as a document instead: md
+                            (Markdown) or html (a single offline page), with
+                            a usage table and collapsible tool calls
     -h, --help              Show this help message
 
 STYLES:
@@ -297,6 +650,56 @@ STYLES:
     minimal                 No boxes, still colored
     plain                   No colors, great for logs
 
+FORMATS:
+    Each format is converted to Claude stream-json messages before filtering
+    and rendering. auto picks the format from the first object it recognizes.
+    claude                  Claude Code --output-format stream-json
+    codex                   codex exec --json
+    gemini                  Gemini CLI --output-format stream-json or json
+    aider                   aider --analytics-log (model, commands, tokens
+                            and cost; aider doesn't log message text as JSON)
+    openai                  OpenAI-style chat JSON: messages, one per line or
+                            in a "messages" array, and chat completions
+
+FILTERS:
+    Filters decide what is rendered; --tee, --summary and --changes still
+    see every message. Kinds for --only and --exclude:
+    system, assistant, user, result
+                            Message types ("result" is the final result)
+    tool                    Messages with tool calls or tool results
+    error                   Failed results and tool errors
+    text                    Input lines that aren't JSON
+
+BUDGETS:
+    A BUDGET file next to a crumb's README.md limits what may be spent on
+    the crumb and everything below it; the root crumb's BUDGET limits the
+    whole project. One limit per line, # for comments:
+        tokens 2M               All tokens, including cache reads (k and M
+                                suffixes allowed)
+        cost 5.00               Dollars
+    Spending recorded earlier with --record counts toward the limits.
+    Tokens are checked on every message; dollars when the agent reports
+    them, which Claude Code does at the end of each session.
+
+EXIT STATUS:
+    0                       The stream was read
+    1                       clean failed, as with an unreadable file
+    2                       --fail-on-error: the session failed or has no
+                            result
+    3                       --enforce-budget: a budget was exceeded
+
+REDACTION:
+    Secrets are replaced by markers like [REDACTED aws-access-key] in
+    everything clean writes: the transcript, --tee files, --output
+    documents and --summary. Built-in detectors find AWS keys, GitHub
+    tokens, JWTs, private keys, and long random-looking strings such as API
+    keys. Message, tool call and session IDs are left as they are. A
+    project can list more regular expressions in .crumbler-redact in
+    its root, one per line (# for comments); if a pattern has a group, only
+    the group is redacted:
+        corp-[a-z]+\.internal
+        (?i)password=(\S+)
+
 EXAMPLES:
     # Read from stdin (pipe Claude Code output)
     claude -p "your prompt" --verbose --output-format stream-json | crumbler clean
@@ -310,6 +713,63 @@ EXAMPLES:
     # Show line numbers and usage stats
     crumbler clean -l -V logs.jsonl
 
+    # Show the transcript followed by session totals
+    crumbler clean --summary logs.jsonl
+
+    # Compare two agent runs
+    crumbler clean --summary=json run-a.jsonl > a.json
+    crumbler clean --summary=json run-b.jsonl > b.json
+
+    # Retry a failed session, resuming it
+    claude -p "$(crumbler prompt)" --verbose --output-format stream-json |
+        crumbler clean --fail-on-error --session-file session.json ||
+        claude -p "continue" --resume "$(jq -r .session_id session.json)" \
+            --verbose --output-format stream-json | crumbler clean
+
+    # Check the files an agent says it changed against the working tree
+    crumbler clean --changes=json logs.jsonl | jq -r '.files[].path' | sort > claimed
+    { git diff --name-only HEAD; git ls-files --others --exclude-standard; } | sort | diff claimed -
+
+    # Keep the raw stream while watching it
+    claude -p "your prompt" --verbose --output-format stream-json | crumbler clean --tee run.jsonl
+
+    # Show only Bash calls and their output
+    crumbler clean --tool Bash logs.jsonl
+
+    # Show assistant text and tool activity, but no final result
+    crumbler clean --only assistant,tool --exclude result logs.jsonl
+
+    # Find where a file was touched
+    crumbler clean --grep 'internal/crumb' logs.jsonl
+
+    # Watch an agent work, with a status line
+    claude -p "$(crumbler prompt)" --verbose --output-format stream-json | crumbler clean --live
+
+    # Watch a session being logged from another terminal
+    crumbler clean -f --summary logs.jsonl
+
+    # Export a session to attach to a pull request
+    crumbler clean --output html logs.jsonl > session.html
+    crumbler clean --output md logs.jsonl > session.md
+
+    # Track what each crumb costs while an agent works
+    crumbler prompt | claude -p --verbose --output-format stream-json | crumbler clean --record
+
+    # Stop an agent loop when a crumb goes over budget (clean exits 3, and
+    # the agent gets SIGPIPE once clean stops reading)
+    set -o pipefail
+    claude -p "$(crumbler prompt)" --verbose --output-format stream-json |
+        crumbler clean --record --enforce-budget || exit
+
+    # Read a log with secrets left in (only on a trusted terminal)
+    crumbler clean --no-redact logs.jsonl
+
+    # Read another agent's log
+    codex exec --json "your prompt" | crumbler clean --format codex
+
+    # Keep colors when paging, and show tool output in full
+    crumbler clean --color=always --max-output-lines 0 logs.jsonl | less -R
+
     # Minimal style with verbose output
     crumbler clean -s minimal -v logs.jsonl
 
@@ -328,6 +788,7 @@ OUTPUT TYPES:
     - RESULT      (Gray)    Tool output
     - ERROR       (Red)     Tool errors
     - USAGE       (Magenta) Token stats & costs
+    - RAW JSON    (Red)     Input that isn't a message clean can show
 
 FOR AI AGENTS:
     Use 'crumbler clean' to format Claude Code JSON output when you need to
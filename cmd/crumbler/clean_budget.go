@@ -0,0 +1,172 @@
+package crumbler
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/ledger"
+)
+
+// exitBudgetExceeded is clean's exit status when --enforce-budget stops a
+// stream, so a wrapper loop can tell it from other failures.
+const exitBudgetExceeded = 3
+
+// budgetTracker enforces BUDGET files for --enforce-budget. It credits
+// usage to crumbs like costRecorder, on top of what the ledger already
+// holds, and stops at the first budget exceeded.
+//
+// Tokens are checked on every message. Dollars are only known when the
+// agent reports them, which Claude Code does at the end of a session.
+type budgetTracker struct {
+	current  func() string                                  // Current crumb path, relative to the project root
+	budgets  func(crumbPath string) ([]crumb.Budget, error) // Budgets for a relative crumb path
+	spent    map[string]*ledger.Totals                      // Rolled-up totals, ledger plus stream
+	messages map[string]*crumbUsage                         // Usage credited per assistant message ID
+	weights  map[string]float64                             // Crumb shares of the next result's cost
+	seen     int
+	exceeded *budgetExceeded
+	err      error // First error reading budgets
+}
+
+// budgetExceeded describes the budget that stopped the stream.
+type budgetExceeded struct {
+	budget crumb.Budget
+	spent  ledger.Totals
+	tokens bool // The token limit was exceeded (otherwise cost)
+}
+
+// newBudgetTracker returns a tracker for the project at root, starting
+// from the totals in its ledger.
+func newBudgetTracker(root string) (*budgetTracker, error) {
+	entries, err := ledger.Read(root)
+	if err != nil {
+		return nil, err
+	}
+	return &budgetTracker{
+		current: func() string { return currentCrumbPath(root) },
+		budgets: func(crumbPath string) ([]crumb.Budget, error) {
+			return crumb.Budgets(root, filepath.Join(crumb.ProjectRoot(root), crumbPath))
+		},
+		spent:    ledger.Rollup(entries),
+		messages: make(map[string]*crumbUsage),
+		weights:  make(map[string]float64),
+	}, nil
+}
+
+// add records one message and checks the budgets it counts against.
+func (b *budgetTracker) add(msg *parser.StreamMessage) {
+	if b.exceeded != nil {
+		return
+	}
+	switch msg.Type {
+	case "assistant":
+		if msg.Message == nil || msg.Message.Usage == nil {
+			return
+		}
+		b.seen++
+		id := msg.Message.ID
+		if id == "" {
+			id = fmt.Sprintf("#%d", b.seen)
+		}
+		u, ok := b.messages[id]
+		if !ok {
+			u = &crumbUsage{crumb: b.current()}
+			b.messages[id] = u
+		}
+		// Streamed messages repeat their ID with growing usage; add the difference
+		delta := ledger.Entry{Crumb: u.crumb}
+		addUsage(&delta, *msg.Message.Usage)
+		subUsage(&delta, u.usage)
+		b.weights[u.crumb] += usageWeight(*msg.Message.Usage) - usageWeight(u.usage)
+		u.usage = *msg.Message.Usage
+		b.credit(delta)
+	case "result":
+		// As in costRecorder, cost is split by the tokens since the last result
+		var total float64
+		for _, w := range b.weights {
+			total += w
+		}
+		if total == 0 {
+			e := ledger.Entry{Crumb: b.current(), CostUSD: msg.TotalCostUSD}
+			if len(b.messages) == 0 && msg.Usage != nil {
+				addUsage(&e, *msg.Usage)
+			}
+			b.credit(e)
+		}
+		paths := make([]string, 0, len(b.weights))
+		for path := range b.weights {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			if total > 0 && b.exceeded == nil {
+				b.credit(ledger.Entry{Crumb: path, CostUSD: msg.TotalCostUSD * b.weights[path] / total})
+			}
+		}
+		b.weights = make(map[string]float64)
+	}
+}
+
+// credit adds an entry to the crumb and its ancestors, then checks the
+// crumb's budgets.
+func (b *budgetTracker) credit(e ledger.Entry) {
+	for _, path := range ledger.Ancestors(e.Crumb) {
+		t, ok := b.spent[path]
+		if !ok {
+			t = &ledger.Totals{}
+			b.spent[path] = t
+		}
+		t.Add(e)
+	}
+
+	budgets, err := b.budgets(e.Crumb)
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	for _, budget := range budgets {
+		spent := ledger.Totals{}
+		if t := b.spent[budget.Crumb]; t != nil {
+			spent = *t
+		}
+		switch {
+		case budget.Tokens > 0 && spent.Tokens() > budget.Tokens:
+			b.exceeded = &budgetExceeded{budget: budget, spent: spent, tokens: true}
+		case budget.CostUSD > 0 && spent.CostUSD > budget.CostUSD:
+			b.exceeded = &budgetExceeded{budget: budget, spent: spent}
+		default:
+			continue
+		}
+		return
+	}
+}
+
+// writeBanner prints the exceeded budget.
+func (e *budgetExceeded) writeBanner(w io.Writer) {
+	limit := fmt.Sprintf("%s tokens (budget %s)", formatTokens(e.spent.Tokens()), formatTokens(e.budget.Tokens))
+	if !e.tokens {
+		limit = fmt.Sprintf("$%.4f (budget $%.4f)", e.spent.CostUSD, e.budget.CostUSD)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "========================================")
+	fmt.Fprintln(w, "  BUDGET EXCEEDED")
+	fmt.Fprintf(w, "  Crumb:  %s\n", e.budget.Crumb)
+	fmt.Fprintf(w, "  Spent:  %s\n", limit)
+	fmt.Fprintln(w, "  Stop the agent and review the crumb, or raise its")
+	fmt.Fprintf(w, "  %s, before continuing.\n", crumb.BudgetFile)
+	fmt.Fprintln(w, "========================================")
+}
+
+// subUsage subtracts usage from a ledger entry.
+func subUsage(e *ledger.Entry, u parser.Usage) {
+	e.InputTokens -= u.InputTokens
+	e.OutputTokens -= u.OutputTokens
+	e.CacheReadTokens -= u.CacheReadInputTokens
+	e.CacheCreationTokens -= u.CacheCreationInputTokens
+}
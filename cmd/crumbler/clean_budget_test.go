@@ -0,0 +1,109 @@
+package crumbler
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/ledger"
+)
+
+// testBudgetTracker returns a tracker with fixed budgets, starting from
+// the given ledger entries.
+func testBudgetTracker(current string, budgets []crumb.Budget, recorded ...ledger.Entry) *budgetTracker {
+	return &budgetTracker{
+		current: func() string { return current },
+		budgets: func(path string) ([]crumb.Budget, error) {
+			var applied []crumb.Budget
+			for _, b := range budgets {
+				if path == b.Crumb || strings.HasPrefix(path, b.Crumb+"/") {
+					applied = append(applied, b)
+				}
+			}
+			return applied, nil
+		},
+		spent:    ledger.Rollup(recorded),
+		messages: make(map[string]*crumbUsage),
+		weights:  make(map[string]float64),
+	}
+}
+
+func TestBudgetTracker(t *testing.T) {
+	t.Parallel()
+
+	stream := `{"type":"assistant","message":{"id":"m1","content":[],"usage":{"input_tokens":400,"output_tokens":10}}}
+{"type":"assistant","message":{"id":"m1","content":[],"usage":{"input_tokens":400,"output_tokens":50}}}
+{"type":"assistant","message":{"id":"m2","content":[],"usage":{"input_tokens":600,"output_tokens":50}}}
+{"type":"result","subtype":"success","total_cost_usd":2}
+`
+	tests := []struct {
+		name     string
+		budgets  []crumb.Budget
+		recorded []ledger.Entry
+		want     string // Budget crumb exceeded, if any
+		messages int    // Messages seen before stopping
+	}{
+		{name: "within budget", budgets: []crumb.Budget{{Crumb: ".crumbler/01-a", Tokens: 2000, CostUSD: 5}}, messages: 4},
+		{name: "tokens", budgets: []crumb.Budget{{Crumb: ".crumbler/01-a", Tokens: 1000}}, want: ".crumbler/01-a", messages: 3},
+		// m1 streams twice (450 tokens in all, within budget); m2 goes over
+		{name: "streamed usage counts once", budgets: []crumb.Budget{{Crumb: ".crumbler/01-a", Tokens: 455}}, want: ".crumbler/01-a", messages: 3},
+		{name: "project cost", budgets: []crumb.Budget{{Crumb: ".crumbler", CostUSD: 1.5}}, want: ".crumbler", messages: 4},
+		{
+			name:     "earlier sessions count",
+			budgets:  []crumb.Budget{{Crumb: ".crumbler/01-a", Tokens: 1000}},
+			recorded: []ledger.Entry{{Crumb: ".crumbler/01-a/01-old", InputTokens: 600}},
+			want:     ".crumbler/01-a",
+			messages: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			b := testBudgetTracker(".crumbler/01-a", tt.budgets, tt.recorded...)
+			opts := &cleanOptions{summary: newSessionSummary(), summaryJSON: true, budget: b}
+			if err := processJSONStream(strings.NewReader(stream), &display.Config{}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			got := ""
+			if b.exceeded != nil {
+				got = b.exceeded.budget.Crumb
+			}
+			if got != tt.want {
+				t.Errorf("exceeded = %q, want %q", got, tt.want)
+			}
+			if opts.summary.Messages != tt.messages {
+				t.Errorf("read %d messages, want %d (stop as soon as exceeded)", opts.summary.Messages, tt.messages)
+			}
+		})
+	}
+}
+
+func TestBudgetBanner(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	e := &budgetExceeded{budget: crumb.Budget{Crumb: ".crumbler/01-a", Tokens: 1000}, spent: ledger.Totals{InputTokens: 1500}, tokens: true}
+	e.writeBanner(&out)
+	for _, want := range []string{"BUDGET EXCEEDED", ".crumbler/01-a", "1.5k tokens (budget 1.0k)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("banner missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	if got := ExitCode(errors.New("boom")); got != 1 {
+		t.Errorf("ExitCode(plain error) = %d, want 1", got)
+	}
+	err := &exitError{code: exitBudgetExceeded, err: errors.New("over")}
+	if got := ExitCode(err); got != 3 {
+		t.Errorf("ExitCode(budget) = %d, want 3", got)
+	}
+}
@@ -0,0 +1,249 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// fileTools are the tools whose calls change files, and the input field
+// naming the file.
+var fileTools = map[string]string{
+	"Write":     "file_path",
+	"Edit":      "file_path",
+	"MultiEdit": "file_path",
+}
+
+// exitCodePattern finds the exit status Claude Code reports for a failed
+// Bash command.
+var exitCodePattern = regexp.MustCompile(`^(?:Error: )?Exit code (\d+)`)
+
+// changeManifest collects the files an agent changed and the commands it
+// ran, for --changes. Paths under the session's working directory are
+// shown relative to it, so they can be compared with git diff.
+type changeManifest struct {
+	Cwd      string          `json:"cwd,omitempty"`
+	Files    []*fileChange   `json:"files"`
+	Commands []*commandEntry `json:"commands"` // In the order first run
+
+	files    map[string]*fileChange   // By path as the agent gave it
+	commands map[string]*commandEntry // By command
+	calls    map[string]*changeCall   // Calls by tool_use ID, until their result
+}
+
+// fileChange counts the calls that changed one file.
+type fileChange struct {
+	Path       string         `json:"path"`
+	Operations map[string]int `json:"operations"` // Successful calls by tool
+	Failed     int            `json:"failed"`     // Calls that returned an error
+}
+
+// commandEntry is one command and how its runs went.
+type commandEntry struct {
+	Command  string `json:"command"`
+	Runs     int    `json:"runs"`
+	Failed   int    `json:"failed"`
+	Status   string `json:"status"`    // Of the last run: ok, failed or unknown
+	ExitCode *int   `json:"exit_code"` // Of the last run; null if unknown
+}
+
+// changeCall is a file or command call waiting for its result.
+type changeCall struct {
+	tool    string
+	file    *fileChange
+	command *commandEntry
+}
+
+// newChangeManifest returns an empty manifest.
+func newChangeManifest() *changeManifest {
+	return &changeManifest{
+		files:    make(map[string]*fileChange),
+		commands: make(map[string]*commandEntry),
+		calls:    make(map[string]*changeCall),
+	}
+}
+
+// add records one message.
+func (m *changeManifest) add(msg *parser.StreamMessage) {
+	if msg.Type == "system" && msg.CWD != "" && m.Cwd == "" {
+		m.Cwd = msg.CWD
+	}
+	if msg.Message == nil {
+		return
+	}
+	for _, block := range msg.Message.Content {
+		switch block.Type {
+		case "tool_use":
+			m.addCall(block)
+		case "tool_result":
+			m.addResult(block)
+		}
+	}
+}
+
+// addCall records a tool call. Streamed messages repeat their calls, which
+// are counted once.
+func (m *changeManifest) addCall(block parser.ContentBlock) {
+	if _, seen := m.calls[block.ID]; seen && block.ID != "" {
+		return
+	}
+	call := &changeCall{tool: block.Name}
+	if key, ok := fileTools[block.Name]; ok {
+		path, _ := block.Input[key].(string)
+		if path == "" {
+			return
+		}
+		f, ok := m.files[path]
+		if !ok {
+			f = &fileChange{Path: path, Operations: make(map[string]int)}
+			m.files[path] = f
+		}
+		f.Operations[block.Name]++
+		call.file = f
+	} else if block.Name == "Bash" {
+		command, _ := block.Input["command"].(string)
+		if command == "" {
+			return
+		}
+		c, ok := m.commands[command]
+		if !ok {
+			c = &commandEntry{Command: command}
+			m.commands[command] = c
+			m.Commands = append(m.Commands, c)
+		}
+		c.Runs++
+		c.Status, c.ExitCode = "unknown", nil
+		call.command = c
+	} else {
+		return
+	}
+	if block.ID != "" {
+		m.calls[block.ID] = call
+	}
+}
+
+// addResult records how a call went.
+func (m *changeManifest) addResult(block parser.ContentBlock) {
+	call, ok := m.calls[block.ToolUseID]
+	if !ok {
+		return
+	}
+	delete(m.calls, block.ToolUseID)
+
+	switch {
+	case call.file != nil && block.IsError:
+		// The file wasn't changed
+		call.file.Operations[call.tool]--
+		call.file.Failed++
+	case call.command != nil && block.IsError:
+		call.command.Failed++
+		call.command.Status = "failed"
+		if match := exitCodePattern.FindStringSubmatch(toolOutputText(block.Content)); match != nil {
+			code, _ := strconv.Atoi(match[1])
+			call.command.ExitCode = &code
+		}
+	case call.command != nil:
+		code := 0
+		call.command.Status, call.command.ExitCode = "ok", &code
+	}
+}
+
+// finish merges the paths that name the same file and sorts the files.
+func (m *changeManifest) finish() {
+	byPath := make(map[string]*fileChange)
+	m.Files = m.Files[:0]
+	for _, f := range m.files {
+		path := m.displayPath(f.Path)
+		merged, ok := byPath[path]
+		if !ok {
+			merged = &fileChange{Path: path, Operations: make(map[string]int)}
+			byPath[path] = merged
+			m.Files = append(m.Files, merged)
+		}
+		for tool, n := range f.Operations {
+			if n > 0 {
+				merged.Operations[tool] += n
+			}
+		}
+		merged.Failed += f.Failed
+	}
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+}
+
+// displayPath returns a path relative to the working directory if it is
+// under it.
+func (m *changeManifest) displayPath(path string) string {
+	path = filepath.Clean(path)
+	if m.Cwd == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(m.Cwd, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return rel
+}
+
+// writeText prints the manifest for people.
+func (m *changeManifest) writeText(w io.Writer) error {
+	m.finish()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANGES")
+	fmt.Fprintf(tw, "  Files:\t%d\n", len(m.Files))
+	for _, f := range m.Files {
+		tools := make([]string, 0, len(f.Operations))
+		for tool := range f.Operations {
+			tools = append(tools, tool)
+		}
+		sort.Strings(tools)
+		var ops []string
+		for _, tool := range tools {
+			ops = append(ops, fmt.Sprintf("%s %d", tool, f.Operations[tool]))
+		}
+		if f.Failed > 0 {
+			ops = append(ops, fmt.Sprintf("%d failed", f.Failed))
+		}
+		fmt.Fprintf(tw, "    %s\t%s\n", f.Path, strings.Join(ops, ", "))
+	}
+	fmt.Fprintf(tw, "  Commands:\t%d\n", len(m.Commands))
+	for _, c := range m.Commands {
+		status := c.Status
+		if c.ExitCode != nil {
+			status = fmt.Sprintf("exit %d", *c.ExitCode)
+		}
+		line := fmt.Sprintf("    %s\t%s", status, oneLine(c.Command))
+		if c.Runs > 1 {
+			line += fmt.Sprintf(" (%d runs, %d failed)", c.Runs, c.Failed)
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+// writeJSON prints the manifest for scripts.
+func (m *changeManifest) writeJSON(w io.Writer) error {
+	m.finish()
+	if m.Files == nil {
+		m.Files = []*fileChange{}
+	}
+	if m.Commands == nil {
+		m.Commands = []*commandEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(m)
+}
+
+// oneLine collapses a multi-line command onto one line.
+func oneLine(command string) string {
+	return strings.Join(strings.Fields(command), " ")
+}
@@ -0,0 +1,138 @@
+package crumbler
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// changesLog writes one file, edits another twice (once failing) under two
+// spellings of its path, edits a file outside the working directory, and
+// runs three commands, one of them twice.
+const changesLog = `{"type":"system","subtype":"init","session_id":"s1","cwd":"/repo"}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/repo/a.go","content":"package a\n"}}]}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/repo/a.go","content":"package a\n"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"File created"}]}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","id":"t2","name":"Edit","input":{"file_path":"/repo/b/b.go","old_string":"x","new_string":"y"}},{"type":"tool_use","id":"t3","name":"MultiEdit","input":{"file_path":"/repo/./b/b.go","edits":[]}},{"type":"tool_use","id":"t4","name":"Edit","input":{"file_path":"/tmp/notes.md"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":"String not found","is_error":true},{"type":"tool_result","tool_use_id":"t3","content":"ok"},{"type":"tool_result","tool_use_id":"t4","content":"ok"}]}}
+{"type":"assistant","message":{"id":"m3","content":[{"type":"tool_use","id":"t5","name":"Bash","input":{"command":"go test ./..."}},{"type":"tool_use","id":"t6","name":"Bash","input":{"command":"go vet ./..."}},{"type":"tool_use","id":"t7","name":"Read","input":{"file_path":"/repo/c.go"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t5","content":"Exit code 1\nFAIL","is_error":true},{"type":"tool_result","tool_use_id":"t6","content":"Permission denied","is_error":true},{"type":"tool_result","tool_use_id":"t7","content":"package c"}]}}
+{"type":"assistant","message":{"id":"m4","content":[{"type":"tool_use","id":"t8","name":"Bash","input":{"command":"go test ./..."}},{"type":"tool_use","id":"t9","name":"Bash","input":{"command":"git status"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t8","content":"ok"}]}}
+`
+
+func TestChangeManifest(t *testing.T) {
+	t.Parallel()
+
+	changes := newChangeManifest()
+	opts := &cleanOptions{changes: changes, changesJSON: true}
+	if err := processJSONStream(strings.NewReader(changesLog), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := changes.writeJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Cwd   string
+		Files []struct {
+			Path       string
+			Operations map[string]int
+			Failed     int
+		}
+		Commands []struct {
+			Command  string
+			Runs     int
+			Failed   int
+			Status   string
+			ExitCode *int `json:"exit_code"`
+		}
+	}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+
+	if got.Cwd != "/repo" {
+		t.Errorf("cwd = %q, want /repo", got.Cwd)
+	}
+	type file struct {
+		path   string
+		ops    map[string]int
+		failed int
+	}
+	var files []file
+	for _, f := range got.Files {
+		files = append(files, file{f.Path, f.Operations, f.Failed})
+	}
+	wantFiles := []file{
+		{"/tmp/notes.md", map[string]int{"Edit": 1}, 0},
+		{"a.go", map[string]int{"Write": 1}, 0},
+		{"b/b.go", map[string]int{"MultiEdit": 1}, 1},
+	}
+	if !reflect.DeepEqual(files, wantFiles) {
+		t.Errorf("files = %+v, want %+v", files, wantFiles)
+	}
+
+	type command struct {
+		command, status string
+		runs, failed    int
+		exit            int // -1 for unknown
+	}
+	var commands []command
+	for _, c := range got.Commands {
+		exit := -1
+		if c.ExitCode != nil {
+			exit = *c.ExitCode
+		}
+		commands = append(commands, command{c.Command, c.Status, c.Runs, c.Failed, exit})
+	}
+	wantCommands := []command{
+		{"go test ./...", "ok", 2, 1, 0},
+		{"go vet ./...", "failed", 1, 1, -1},
+		{"git status", "unknown", 1, 0, -1},
+	}
+	if !reflect.DeepEqual(commands, wantCommands) {
+		t.Errorf("commands = %+v, want %+v", commands, wantCommands)
+	}
+}
+
+func TestChangeManifestText(t *testing.T) {
+	t.Parallel()
+
+	changes := newChangeManifest()
+	opts := &cleanOptions{changes: changes, changesJSON: true}
+	if err := processJSONStream(strings.NewReader(changesLog), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := changes.writeText(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"CHANGES",
+		"Files:",
+		"b/b.go         MultiEdit 1, 1 failed",
+		"Commands:",
+		"exit 0         go test ./... (2 runs, 1 failed)",
+		"failed         go vet ./...",
+		"unknown        git status",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("text missing %q:\n%s", want, out.String())
+		}
+	}
+
+	// An empty stream still has both lists in JSON
+	var empty bytes.Buffer
+	if err := newChangeManifest().writeJSON(&empty); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(empty.String(), `"files": []`) || !strings.Contains(empty.String(), `"commands": []`) {
+		t.Errorf("empty manifest = %s", empty.String())
+	}
+}
@@ -0,0 +1,604 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	defaultChunkSize = 8 * 1024    // 8KB reads
+	maxStringBytes   = 1024 * 1024 // Longest JSON string or text line kept
+	maxJSONDepth     = 512         // Deepest nesting decoded
+)
+
+// record is one item of a transcript: a JSON object (or other value), or a
+// line of text that isn't JSON.
+type record struct {
+	value []byte // The JSON value, re-encoded; nil for text
+	text  string
+}
+
+// errRestart reports that the input started over, as when a followed file
+// is truncated or rotated.
+var errRestart = errors.New("input restarted")
+
+// structuralKeys are the keys of values that identify or classify a
+// message rather than carry its text. They are never redacted: random IDs
+// would all become the same marker, and different messages and tool calls
+// would merge into one.
+var structuralKeys = map[string]bool{
+	"id":                 true,
+	"uuid":               true,
+	"parentUuid":         true,
+	"session_id":         true,
+	"sessionId":          true,
+	"tool_use_id":        true,
+	"parent_tool_use_id": true,
+	"type":               true,
+	"subtype":            true,
+	"role":               true,
+	"model":              true,
+	"name":               true,
+	"stop_reason":        true,
+}
+
+// syntaxError reports input that isn't JSON.
+type syntaxError struct {
+	msg string
+}
+
+func (e *syntaxError) Error() string { return e.msg }
+
+// incompleteError reports a JSON value cut off by the end of the input.
+type incompleteError struct {
+	bytes int
+}
+
+func (e *incompleteError) Error() string {
+	return fmt.Sprintf("incomplete JSON at end of input (%d bytes)", e.bytes)
+}
+
+// streamDecoder splits a transcript into records. JSON values may span
+// lines and follow each other on one line; the elements of a top-level
+// array are records of their own. Anything else is text: when a line turns
+// out not to be JSON, the rest of it is passed through and decoding resumes
+// at the next line.
+//
+// Values are decoded as they are read, so memory doesn't grow with the
+// input. Strings longer than maxString, such as huge tool outputs, keep
+// their head and tail with a note of how much was left out. If redact is
+// set, it is applied to every string value and text line, except those of
+// structuralKeys.
+type streamDecoder struct {
+	src       io.Reader
+	tee       io.Writer // Receives every byte read, if set
+	chunk     []byte
+	buf       []byte // Unconsumed bytes of the last read
+	readErr   error
+	empty     int // Consecutive reads that returned nothing
+	maxString int
+	redact    func(string) string
+
+	inArray bool // Inside a top-level array
+	more    bool // An element was read; a comma or ] comes next
+
+	raw     []byte // The current record's bytes, up to maxString
+	dropped int    // Bytes of the current record beyond maxString
+}
+
+// newStreamDecoder returns a decoder reading from src.
+func newStreamDecoder(src io.Reader, tee io.Writer) *streamDecoder {
+	return &streamDecoder{src: src, tee: tee, chunk: make([]byte, defaultChunkSize), maxString: maxStringBytes}
+}
+
+// next returns the next record. At the end of the input it returns io.EOF,
+// or an *incompleteError if a value was cut off.
+func (d *streamDecoder) next() (record, error) {
+	for {
+		d.raw, d.dropped = d.raw[:0], 0
+		rec, err := d.record()
+		if err == errRestart {
+			d.inArray = false // Drop whatever was partly read
+			continue
+		}
+		return rec, err
+	}
+}
+
+// record reads one record.
+func (d *streamDecoder) record() (record, error) {
+	if d.inArray {
+		return d.element()
+	}
+
+	c, err := d.skipSpace(false)
+	if err != nil {
+		return record{}, err
+	}
+	switch c {
+	case '{':
+		v, err := d.value(0)
+		if err != nil {
+			return d.fail(err)
+		}
+		return d.encode(v)
+	case '[':
+		d.read()
+		d.inArray, d.more = true, false
+		return d.element()
+	default:
+		return d.textLine()
+	}
+}
+
+// element reads the next element of a top-level array.
+func (d *streamDecoder) element() (record, error) {
+	c, err := d.skipSpace(true)
+	if err != nil {
+		return d.fail(err)
+	}
+	if c == ']' {
+		d.read()
+		d.inArray = false
+		d.raw, d.dropped = d.raw[:0], 0
+		return d.record()
+	}
+	if d.more {
+		if c != ',' {
+			return d.fail(&syntaxError{fmt.Sprintf("expected , or ] in array, found %q", c)})
+		}
+		d.read()
+		if c, err = d.skipSpace(true); err != nil {
+			return d.fail(err)
+		}
+	} else if c != '{' && c != '[' {
+		// Lines like "[INFO] ..." are text, not arrays
+		return d.fail(&syntaxError{fmt.Sprintf("expected object in array, found %q", c)})
+	}
+
+	v, err := d.value(0)
+	if err != nil {
+		return d.fail(err)
+	}
+	d.more = true
+	return d.encode(v)
+}
+
+// fail recovers from an error in the middle of a record. After a syntax
+// error, the record's bytes through the end of the line become text.
+func (d *streamDecoder) fail(err error) (record, error) {
+	d.inArray = false
+	var serr *syntaxError
+	switch {
+	case errors.As(err, &serr):
+		return d.textLine()
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return record{}, &incompleteError{bytes: len(d.raw) + d.dropped}
+	}
+	return record{}, err
+}
+
+// textLine reads through the end of the line, returning the record's bytes
+// as text.
+func (d *streamDecoder) textLine() (record, error) {
+	for {
+		c, err := d.peek()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return record{}, err
+		}
+		if c == '\n' {
+			d.buf = d.buf[1:]
+			break
+		}
+		d.read()
+	}
+	text := strings.TrimRight(strings.TrimLeft(string(d.raw), " \t"), "\r")
+	if d.dropped > 0 {
+		text += fmt.Sprintf(" ... (%d bytes omitted)", d.dropped)
+	}
+	return record{text: d.redacted(text, true)}, nil
+}
+
+// encode returns a record for a decoded value.
+func (d *streamDecoder) encode(v interface{}) (record, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return d.fail(&syntaxError{err.Error()})
+	}
+	return record{value: data}, nil
+}
+
+// value decodes the JSON value at the current position.
+func (d *streamDecoder) value(depth int) (interface{}, error) {
+	if depth > maxJSONDepth {
+		return nil, &syntaxError{"JSON nested too deeply"}
+	}
+	c, err := d.skipSpace(true)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch {
+	case c == '{':
+		return d.object(depth)
+	case c == '[':
+		return d.array(depth)
+	case c == '"':
+		return d.str(true)
+	case c == '-' || c >= '0' && c <= '9':
+		return d.number()
+	case c == 't':
+		return true, d.literal("true")
+	case c == 'f':
+		return false, d.literal("false")
+	case c == 'n':
+		return nil, d.literal("null")
+	}
+	return nil, &syntaxError{fmt.Sprintf("unexpected %q", c)}
+}
+
+// object decodes an object.
+func (d *streamDecoder) object(depth int) (interface{}, error) {
+	d.read() // {
+	obj := make(map[string]interface{})
+	c, err := d.skipSpace(true)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if c == '}' {
+		d.read()
+		return obj, nil
+	}
+	for {
+		if c != '"' {
+			return nil, &syntaxError{fmt.Sprintf("expected object key, found %q", c)}
+		}
+		key, err := d.str(true)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = d.skipSpace(true); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if c != ':' {
+			return nil, &syntaxError{fmt.Sprintf("expected : after object key, found %q", c)}
+		}
+		d.read()
+		if c, err = d.skipSpace(true); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if c == '"' && structuralKeys[key] {
+			obj[key], err = d.str(false)
+		} else {
+			obj[key], err = d.value(depth + 1)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if c, err = d.skipSpace(true); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		switch c {
+		case '}':
+			d.read()
+			return obj, nil
+		case ',':
+			d.read()
+		default:
+			return nil, &syntaxError{fmt.Sprintf("expected , or } in object, found %q", c)}
+		}
+		if c, err = d.skipSpace(true); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+}
+
+// array decodes an array.
+func (d *streamDecoder) array(depth int) (interface{}, error) {
+	d.read() // [
+	arr := []interface{}{}
+	c, err := d.skipSpace(true)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if c == ']' {
+		d.read()
+		return arr, nil
+	}
+	for {
+		v, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+
+		if c, err = d.skipSpace(true); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		switch c {
+		case ']':
+			d.read()
+			return arr, nil
+		case ',':
+			d.read()
+		default:
+			return nil, &syntaxError{fmt.Sprintf("expected , or ] in array, found %q", c)}
+		}
+	}
+}
+
+// str decodes a string, keeping its head and tail if it is too long, and
+// redacting it if redact is true.
+func (d *streamDecoder) str(redact bool) (string, error) {
+	d.read() // "
+	half := d.maxString / 2
+	var head, tail []byte
+	omitted := 0
+	add := func(p []byte) {
+		if len(head) < half {
+			head = append(head, p...)
+			return
+		}
+		tail = append(tail, p...)
+		if len(tail) > 2*half {
+			omitted += len(tail) - half
+			tail = append(tail[:0], tail[len(tail)-half:]...)
+		}
+	}
+
+	var utf [utf8.UTFMax]byte
+	addRune := func(r rune) {
+		add(utf[:utf8.EncodeRune(utf[:], r)])
+	}
+
+	// A \u escape of a high surrogate pairs only with a low surrogate
+	// escaped right after it. Unpaired halves become U+FFFD, as with
+	// encoding/json.
+	var high rune
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return "", unexpectedEOF(err)
+		}
+		if high != 0 && c != '\\' {
+			addRune(utf8.RuneError)
+			high = 0
+		}
+		switch {
+		case c == '"':
+			d.read()
+			if len(head)+len(tail) > d.maxString {
+				omitted += len(tail) - half
+				tail = tail[len(tail)-half:]
+			}
+			if omitted > 0 {
+				// Cut at rune boundaries so the result stays valid UTF-8
+				for len(tail) > 0 && !utf8.RuneStart(tail[0]) {
+					tail, omitted = tail[1:], omitted+1
+				}
+				return d.redacted(fmt.Sprintf("%s\n... (%d bytes omitted) ...\n%s", head, omitted, tail), redact), nil
+			}
+			return d.redacted(string(head)+string(tail), redact), nil
+		case c == '\\':
+			d.read()
+			r, err := d.escape()
+			if err != nil {
+				return "", err
+			}
+			if high != 0 {
+				if isLowSurrogate(r) {
+					addRune(utf16.DecodeRune(high, r))
+					high = 0
+					continue
+				}
+				addRune(utf8.RuneError)
+				high = 0
+			}
+			switch {
+			case isHighSurrogate(r):
+				high = r
+			case isLowSurrogate(r):
+				addRune(utf8.RuneError)
+			default:
+				addRune(r)
+			}
+		case c < 0x20:
+			// Not consumed, so a raw newline ends the line
+			return "", &syntaxError{"control character in string"}
+		default:
+			d.read()
+			add([]byte{c})
+		}
+	}
+}
+
+// redacted applies redact to a decoded string, if set and wanted.
+func (d *streamDecoder) redacted(s string, want bool) string {
+	if d.redact == nil || !want {
+		return s
+	}
+	return d.redact(s)
+}
+
+// escape decodes the escape sequence after a backslash. A \u escape may
+// return half of a surrogate pair; str pairs them up.
+func (d *streamDecoder) escape() (rune, error) {
+	c, err := d.read()
+	if err != nil {
+		return 0, unexpectedEOF(err)
+	}
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		return d.hex4()
+	}
+	return 0, &syntaxError{fmt.Sprintf("invalid escape \\%c", c)}
+}
+
+// isHighSurrogate reports whether r is the first half of a surrogate pair.
+func isHighSurrogate(r rune) bool {
+	return 0xd800 <= r && r < 0xdc00
+}
+
+// isLowSurrogate reports whether r is the second half of a surrogate pair.
+func isLowSurrogate(r rune) bool {
+	return 0xdc00 <= r && r < 0xe000
+}
+
+// hex4 decodes the four hex digits of a \u escape.
+func (d *streamDecoder) hex4() (rune, error) {
+	var digits [4]byte
+	for i := range digits {
+		c, err := d.read()
+		if err != nil {
+			return 0, unexpectedEOF(err)
+		}
+		digits[i] = c
+	}
+	n, err := strconv.ParseUint(string(digits[:]), 16, 32)
+	if err != nil {
+		return 0, &syntaxError{"invalid \\u escape"}
+	}
+	return rune(n), nil
+}
+
+// number decodes a number, keeping its text.
+func (d *streamDecoder) number() (interface{}, error) {
+	var lit []byte
+	for {
+		c, err := d.peek()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if !(c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == 'e' || c == 'E') {
+			break
+		}
+		d.read()
+		lit = append(lit, c)
+	}
+	if !json.Valid(lit) {
+		return nil, &syntaxError{fmt.Sprintf("invalid number %q", lit)}
+	}
+	return json.Number(lit), nil
+}
+
+// literal consumes word (true, false or null).
+func (d *streamDecoder) literal(word string) error {
+	for i := 0; i < len(word); i++ {
+		c, err := d.peek()
+		if err != nil {
+			return unexpectedEOF(err)
+		}
+		if c != word[i] {
+			return &syntaxError{fmt.Sprintf("invalid literal, expected %q", word)}
+		}
+		d.read()
+	}
+	return nil
+}
+
+// skipSpace consumes whitespace and returns the next byte without
+// consuming it. Whitespace between records isn't part of any record.
+func (d *streamDecoder) skipSpace(keep bool) (byte, error) {
+	for {
+		c, err := d.peek()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return c, nil
+		}
+		if keep {
+			d.read()
+		} else {
+			d.buf = d.buf[1:]
+		}
+	}
+}
+
+// peek returns the next byte without consuming it.
+func (d *streamDecoder) peek() (byte, error) {
+	for len(d.buf) == 0 {
+		if err := d.fill(); err != nil {
+			return 0, err
+		}
+	}
+	return d.buf[0], nil
+}
+
+// read consumes the next byte, adding it to the current record.
+func (d *streamDecoder) read() (byte, error) {
+	c, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+	d.buf = d.buf[1:]
+	if len(d.raw) < d.maxString {
+		d.raw = append(d.raw, c)
+	} else {
+		d.dropped++
+	}
+	return c, nil
+}
+
+// fill reads more input once the buffer is used up.
+func (d *streamDecoder) fill() error {
+	if d.readErr != nil {
+		return d.readErr
+	}
+	n, err := d.src.Read(d.chunk)
+	d.buf = d.chunk[:n]
+	if n > 0 && d.tee != nil {
+		if _, err := d.tee.Write(d.buf); err != nil {
+			return fmt.Errorf("error writing tee file: %w", err)
+		}
+	}
+	if err != nil {
+		d.readErr = err
+	}
+	if r, ok := d.src.(*followReader); ok && r.restarted() {
+		return errRestart
+	}
+
+	if n == 0 && err == nil {
+		if d.empty++; d.empty >= 100 {
+			d.readErr = io.ErrNoProgress
+		}
+		return nil
+	}
+	d.empty = 0
+	if n > 0 {
+		return nil
+	}
+	return err
+}
+
+// unexpectedEOF turns io.EOF in the middle of a value into
+// io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+package crumbler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// decodeAll returns the records in input, JSON as "json: ..." and text as
+// "text: ...", followed by the final error if it isn't io.EOF.
+func decodeAll(dec *streamDecoder) []string {
+	var got []string
+	for {
+		rec, err := dec.next()
+		if err == io.EOF {
+			return got
+		}
+		if err != nil {
+			return append(got, "error: "+err.Error())
+		}
+		if rec.value != nil {
+			got = append(got, "json: "+string(rec.value))
+		} else {
+			got = append(got, "text: "+rec.text)
+		}
+	}
+}
+
+func TestStreamDecoder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "whitespace", input: " \t\r\n\n", want: nil},
+		{name: "lines", input: "{\"a\":1}\r\n{\"b\":2}\n", want: []string{`json: {"a":1}`, `json: {"b":2}`}},
+		{name: "same line", input: `{"a":1}{"b":2} {}`, want: []string{`json: {"a":1}`, `json: {"b":2}`, `json: {}`}},
+		{name: "multi-line", input: "{\n  \"a\": [1, 2],\n  \"b\": {\"c\": null}\n}\n", want: []string{`json: {"a":[1,2],"b":{"c":null}}`}},
+		{name: "braces in strings", input: `{"s":"}{\"\\"}`, want: []string{`json: {"s":"}{\"\\"}`}},
+		{name: "escapes", input: `{"s":"é😀\n\/"}`, want: []string{`json: {"s":"é😀\n/"}`}},
+		{name: "values", input: `{"t":true,"f":false,"n":-1.5e3}`, want: []string{`json: {"f":false,"n":-1.5e3,"t":true}`}},
+		{name: "text", input: "Error: rate limited\n{\"a\":1}\n[INFO] retrying \"now\\\n", want: []string{
+			"text: Error: rate limited", `json: {"a":1}`, `text: [INFO] retrying "now\`,
+		}},
+		{name: "array", input: `[{"a":1}, {"b":2}]` + "\n" + `{"c":3}`, want: []string{`json: {"a":1}`, `json: {"b":2}`, `json: {"c":3}`}},
+		{name: "empty array", input: "[]\n[ ]", want: nil},
+		{name: "bad value resyncs", input: "{\"a\": nope}\n{\"b\":2}\n", want: []string{`text: {"a": nope}`, `json: {"b":2}`}},
+		{name: "unterminated string resyncs", input: "{\"a\":\"x\n{\"b\":2}\n", want: []string{`text: {"a":"x`, `json: {"b":2}`}},
+		{name: "bad array resyncs", input: "[{\"a\":1} {\"b\":2}]\n{\"c\":3}", want: []string{
+			`json: {"a":1}`, `text: {"b":2}]`, `json: {"c":3}`,
+		}},
+		{name: "incomplete", input: "{\"a\":1}\n{\"b\":", want: []string{`json: {"a":1}`, "error: incomplete JSON at end of input (5 bytes)"}},
+		{name: "deep nesting", input: strings.Repeat("[", maxJSONDepth+3) + "\n", want: []string{
+			"text: " + strings.Repeat("[", maxJSONDepth+3),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// One byte per read exercises every boundary
+			for _, input := range []io.Reader{strings.NewReader(tt.input), &chunkedReader{data: []byte(tt.input), chunk: 1}} {
+				got := decodeAll(newStreamDecoder(input, nil))
+				if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+					t.Errorf("records = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamDecoderSurrogates(t *testing.T) {
+	t.Parallel()
+
+	// Strings decode exactly as encoding/json decodes them
+	for _, escaped := range []string{
+		`\ud83d\ude00`,
+		`a\ud83d\ude00b`,
+		`\udc00`,
+		`\udc00\u0041`,
+		`\udc00\ud83d\ude00`,
+		`\ud800`,
+		`\ud800x`,
+		`\ud800\n`,
+		`\ud800\u0041`,
+		`\ud800\ud800\udc00`,
+		`\ud800\udbff`,
+		`\udfff\udc00`,
+	} {
+		input := `{"s":"` + escaped + `"}`
+		var want struct{ S string }
+		if err := json.Unmarshal([]byte(input), &want); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error: %v", input, err)
+		}
+
+		rec, err := newStreamDecoder(strings.NewReader(input), nil).next()
+		if err != nil {
+			t.Errorf("next(%s) error: %v", input, err)
+			continue
+		}
+		var got struct{ S string }
+		if err := json.Unmarshal(rec.value, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error: %v", rec.value, err)
+		}
+		if got.S != want.S {
+			t.Errorf("%s decoded to %q, want %q", input, got.S, want.S)
+		}
+	}
+}
+
+func TestStreamDecoderLargeString(t *testing.T) {
+	t.Parallel()
+
+	output := "head" + strings.Repeat("x", 100000) + "tail"
+	input := `{"type":"user","content":"` + output + `"}` + "\n" + strings.Repeat("y", 300) + "\n"
+	var tee bytes.Buffer
+	dec := newStreamDecoder(strings.NewReader(input), &tee)
+	dec.maxString = 100
+
+	got := decodeAll(dec)
+	if len(got) != 2 {
+		t.Fatalf("records = %q, want 2", got)
+	}
+	want := `json: {"content":"head` + strings.Repeat("x", 46) + `\n... (99908 bytes omitted) ...\n` + strings.Repeat("x", 46) + `tail","type":"user"}`
+	if got[0] != want {
+		t.Errorf("record = %s, want %s", got[0], want)
+	}
+	if want := "text: " + strings.Repeat("y", 100) + " ... (200 bytes omitted)"; got[1] != want {
+		t.Errorf("record = %s, want %s", got[1], want)
+	}
+	// The tee still sees everything
+	if tee.String() != input {
+		t.Errorf("tee got %d bytes, want %d", tee.Len(), len(input))
+	}
+}
+
+func TestStreamDecoderRestart(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte(`{"a":"cut`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := openFollow(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		if err := os.Truncate(path, 0); err != nil {
+			t.Error(err)
+		}
+		time.Sleep(30 * time.Millisecond)
+		appendFile(t, path, "{\"b\":2}\n")
+		time.Sleep(30 * time.Millisecond)
+		cancel()
+	}()
+
+	got := decodeAll(newStreamDecoder(f, nil))
+	if len(got) != 1 || got[0] != `json: {"b":2}` {
+		t.Errorf("records = %q, want only the object written after truncation", got)
+	}
+}
+
+func TestCleanTextLines(t *testing.T) {
+	t.Parallel()
+
+	input := "npm WARN deprecated\n" + strings.SplitAfter(sessionLog, "\n")[0] + "Traceback: boom\n"
+	tests := []struct {
+		name string
+		opts func(*cleanOptions) error
+		want []string
+	}{
+		{name: "all", opts: func(*cleanOptions) error { return nil }, want: []string{"npm WARN deprecated", "Traceback: boom"}},
+		{name: "only text", opts: func(o *cleanOptions) error { return addKinds(o.filter.only, "text") }, want: []string{"npm WARN deprecated", "Traceback: boom"}},
+		{name: "exclude text", opts: func(o *cleanOptions) error { return addKinds(o.filter.exclude, "text") }, want: nil},
+		{name: "grep", opts: func(o *cleanOptions) error { o.filter.grep = regexp.MustCompile("boom"); return nil }, want: []string{"Traceback: boom"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts := &cleanOptions{filter: newMessageFilter(), doc: newTranscriptDoc()}
+			if err := tt.opts(opts); err != nil {
+				t.Fatal(err)
+			}
+			if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range opts.doc.entries {
+				if e.kind == "text" {
+					got = append(got, strings.Split(e.text, "\n")...)
+				}
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("text lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Text lines are shown in exports
+	doc, summary := exportDoc(t, "warn: a\nwarn: b\n")
+	var out bytes.Buffer
+	if err := doc.writeMarkdown(&out, summary); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "```\nwarn: a\nwarn: b\n```") {
+		t.Errorf("Markdown missing text lines:\n%s", out.String())
+	}
+}
+
+func TestStreamDecoderReadError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	err := processJSONStream(io.MultiReader(strings.NewReader(`{"a":`), iotest.ErrReader(boom)), &display.Config{}, nil)
+	if !errors.Is(err, boom) {
+		t.Errorf("processJSONStream() error = %v, want %v", err, boom)
+	}
+}
@@ -0,0 +1,287 @@
+package crumbler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/ariel-frischer/claude-clean/parser"
+	"github.com/fatih/color"
+)
+
+const (
+	maxDiffLines   = 60      // Diff lines shown per tool call
+	diffContext    = 3       // Unchanged lines shown around each change
+	maxDiffCompare = 1 << 20 // Largest old × new line count diffed line by line
+)
+
+// diffHeader colors the file names above a diff.
+var diffHeader = color.New(color.Bold)
+
+// diffLine is one line of a diff: ' ' unchanged, '-' removed, '+' added,
+// or '@' for a hunk header.
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// fileDiff returns the diff for a file-changing tool call. ok is false for
+// other blocks, and for calls whose input isn't as expected, which are
+// rendered as usual.
+func fileDiff(block parser.ContentBlock) (lines []diffLine, ok bool) {
+	if block.Type != "tool_use" {
+		return nil, false
+	}
+	if _, isFileTool := fileTools[block.Name]; !isFileTool {
+		return nil, false
+	}
+	if path, _ := block.Input["file_path"].(string); path == "" {
+		return nil, false
+	}
+
+	switch block.Name {
+	case "Write":
+		content, ok := block.Input["content"].(string)
+		if !ok {
+			return nil, false
+		}
+		added := splitLines(content)
+		lines = append(lines, diffLine{'@', fmt.Sprintf("@@ -0,0 +1,%d @@", len(added))})
+		for _, line := range added {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return lines, true
+	case "Edit":
+		return editDiff(block.Input)
+	case "MultiEdit":
+		edits, ok := block.Input["edits"].([]interface{})
+		if !ok {
+			return nil, false
+		}
+		for _, e := range edits {
+			input, ok := e.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			edit, ok := editDiff(input)
+			if !ok {
+				return nil, false
+			}
+			lines = append(lines, edit...)
+		}
+		return lines, true
+	}
+	return nil, false
+}
+
+// editDiff returns the diff for one replacement. Edits don't say where in
+// the file they are, so hunk headers have no line numbers.
+func editDiff(input map[string]interface{}) ([]diffLine, bool) {
+	oldText, ok1 := input["old_string"].(string)
+	newText, ok2 := input["new_string"].(string)
+	if !ok1 || !ok2 {
+		return nil, false
+	}
+	header := "@@"
+	if all, _ := input["replace_all"].(bool); all {
+		header += " every occurrence @@"
+	}
+
+	var lines []diffLine
+	for _, hunk := range diffHunks(lineDiff(splitLines(oldText), splitLines(newText)), diffContext) {
+		lines = append(lines, diffLine{'@', header})
+		lines = append(lines, hunk...)
+	}
+	return lines, true
+}
+
+// splitLines splits text into lines, without a final empty line.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// lineDiff returns the lines of a and b as unchanged, removed and added,
+// using their longest common subsequence. Inputs too large to compare are
+// shown as all removed, then all added.
+func lineDiff(a, b []string) []diffLine {
+	// Common ends don't need comparing
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, line := range a[:prefix] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	if len(midA)*len(midB) > maxDiffCompare {
+		for _, line := range midA {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range midB {
+			lines = append(lines, diffLine{'+', line})
+		}
+	} else {
+		lines = append(lines, lcsDiff(midA, midB)...)
+	}
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', line})
+	}
+	return lines
+}
+
+// lcsDiff diffs a and b with a longest common subsequence table.
+func lcsDiff(a, b []string) []diffLine {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []diffLine
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+// diffHunks groups changes with up to context unchanged lines around them.
+// Changes closer than twice the context share a hunk.
+func diffHunks(lines []diffLine, context int) [][]diffLine {
+	var hunks [][]diffLine
+	start, end := -1, -1 // The current hunk's changed lines
+	flush := func() {
+		if start >= 0 {
+			hunks = append(hunks, lines[max(start-context, 0):min(end+1+context, len(lines))])
+		}
+	}
+	for i, line := range lines {
+		if line.op == ' ' {
+			continue
+		}
+		if start >= 0 && i-end > 2*context {
+			flush()
+			start = -1
+		}
+		if start < 0 {
+			start = i
+		}
+		end = i
+	}
+	flush()
+	return hunks
+}
+
+// writeFileDiff renders a file-changing tool call as a diff in the
+// configured style, showing at most maxDiffLines lines.
+func (r *termRenderer) writeFileDiff(w io.Writer, block parser.ContentBlock, lines []diffLine, lineNum int) {
+	cfg := r.cfg
+	path, _ := block.Input["file_path"].(string)
+	paint := r.paint
+
+	added, removed := 0, 0
+	for _, line := range lines {
+		switch line.op {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	stat := fmt.Sprintf("+%d -%d", added, removed)
+
+	// Header, and the prefix for the lines below it
+	prefix := "  "
+	switch cfg.Style {
+	case display.StyleCompact:
+		fmt.Fprintf(w, "%s%s %s %s %s\n", paint(display.BoldYellow, "TOOL"),
+			paint(display.Gray, display.FormatLineNumCompact(lineNum, cfg.ShowLineNum)),
+			paint(display.Yellow, block.Name), path, paint(display.Gray, stat))
+	case display.StyleMinimal, display.StylePlain:
+		fmt.Fprintf(w, "%s%s\n", paint(display.BoldYellow, "TOOL: "+block.Name),
+			paint(display.Gray, display.FormatLineNum(lineNum, cfg.ShowLineNum)))
+	default:
+		fmt.Fprintf(w, "%s%s\n", paint(display.BoldYellow, "┌─ TOOL: "+block.Name),
+			paint(display.Gray, display.FormatLineNum(lineNum, cfg.ShowLineNum)))
+		prefix = paint(display.Yellow, "│ ")
+	}
+	if cfg.Verbose && cfg.Style != display.StyleCompact {
+		fmt.Fprintf(w, "%s%s\n", prefix, paint(display.Yellow, "ID: "+block.ID))
+	}
+
+	if cfg.Style != display.StyleCompact {
+		oldPath := path
+		if block.Name == "Write" {
+			oldPath = "/dev/null"
+		}
+		fmt.Fprintf(w, "%s%s\n", prefix, paint(diffHeader, "--- "+oldPath))
+		fmt.Fprintf(w, "%s%s %s\n", prefix, paint(diffHeader, "+++ "+path), paint(display.Gray, stat))
+	}
+
+	// Long lines wrap below the text, past the +/- column
+	width := 0
+	if r.cols > 0 {
+		width = max(r.cols-3, minWrapWidth)
+	}
+	for i, line := range lines {
+		if i == maxDiffLines {
+			fmt.Fprintf(w, "%s%s\n", prefix, paint(display.Gray, fmt.Sprintf("... (%d more lines)", len(lines)-i)))
+			break
+		}
+		if line.op == '@' {
+			fmt.Fprintf(w, "%s%s\n", prefix, paint(display.Cyan, line.text))
+			continue
+		}
+		var c *color.Color
+		switch line.op {
+		case '-':
+			c = display.Red
+		case '+':
+			c = display.Green
+		}
+		for j, part := range wrapText(line.text, width) {
+			op := " "
+			if j == 0 {
+				op = string(line.op)
+			}
+			fmt.Fprintf(w, "%s%s\n", prefix, paint(c, op+part))
+		}
+	}
+
+	switch cfg.Style {
+	case display.StyleCompact:
+	case display.StyleMinimal, display.StylePlain:
+		fmt.Fprintln(w)
+	default:
+		fmt.Fprintln(w, paint(display.Yellow, "└─"))
+	}
+}
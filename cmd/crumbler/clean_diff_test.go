@@ -0,0 +1,167 @@
+package crumbler
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// diffText renders diff lines one per line, like a unified diff body.
+func diffText(lines []diffLine) string {
+	var b strings.Builder
+	for _, line := range lines {
+		if line.op == '@' {
+			b.WriteString(line.text + "\n")
+		} else {
+			b.WriteString(string(line.op) + line.text + "\n")
+		}
+	}
+	return b.String()
+}
+
+func TestFileDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		tool  string
+		input map[string]interface{}
+		want  string // "" if not shown as a diff
+	}{
+		{
+			name:  "edit",
+			tool:  "Edit",
+			input: map[string]interface{}{"file_path": "/r/a.go", "old_string": "a\nb\nc\n", "new_string": "a\nB\nc\nd\n"},
+			want:  "@@\n a\n-b\n+B\n c\n+d\n",
+		},
+		{
+			name:  "replace all",
+			tool:  "Edit",
+			input: map[string]interface{}{"file_path": "/r/a.go", "old_string": "x", "new_string": "y", "replace_all": true},
+			want:  "@@ every occurrence @@\n-x\n+y\n",
+		},
+		{
+			name: "multi edit",
+			tool: "MultiEdit",
+			input: map[string]interface{}{"file_path": "/r/a.go", "edits": []interface{}{
+				map[string]interface{}{"old_string": "one", "new_string": "1"},
+				map[string]interface{}{"old_string": "two", "new_string": "2"},
+			}},
+			want: "@@\n-one\n+1\n@@\n-two\n+2\n",
+		},
+		{
+			name:  "write",
+			tool:  "Write",
+			input: map[string]interface{}{"file_path": "/r/new.txt", "content": "hello\nworld\n"},
+			want:  "@@ -0,0 +1,2 @@\n+hello\n+world\n",
+		},
+		{name: "edit without strings", tool: "Edit", input: map[string]interface{}{"file_path": "/r/a.go"}},
+		{name: "other tool", tool: "Read", input: map[string]interface{}{"file_path": "/r/a.go"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lines, ok := fileDiff(parser.ContentBlock{Type: "tool_use", Name: tt.tool, Input: tt.input})
+			if !ok {
+				if tt.want != "" {
+					t.Errorf("not shown as a diff, want:\n%s", tt.want)
+				}
+				return
+			}
+			if got := diffText(lines); got != tt.want {
+				t.Errorf("diff =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineDiffHunks(t *testing.T) {
+	t.Parallel()
+
+	// Two changes far apart become two hunks with three lines of context
+	var before, after []string
+	for i := 1; i <= 20; i++ {
+		before = append(before, fmt.Sprint(i))
+		after = append(after, fmt.Sprint(i))
+	}
+	after[1], after[17] = "two", "eighteen"
+
+	hunks := diffHunks(lineDiff(before, after), diffContext)
+	if len(hunks) != 2 {
+		t.Fatalf("got %d hunks, want 2", len(hunks))
+	}
+	want := []string{" 1\n-2\n+two\n 3\n 4\n 5\n", " 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n"}
+	for i, hunk := range hunks {
+		if got := diffText(hunk); got != want[i] {
+			t.Errorf("hunk %d =\n%s\nwant:\n%s", i+1, got, want[i])
+		}
+	}
+
+	// Too large to compare: everything is replaced
+	big := make([]string, 1100)
+	for i := range big {
+		big[i] = fmt.Sprint(i)
+	}
+	changed := append([]string{"x"}, big[1:len(big)-1]...)
+	changed = append(changed, "y")
+	lines := lineDiff(big, changed)
+	if len(lines) != 2*len(big) {
+		t.Errorf("large diff has %d lines, want %d", len(lines), 2*len(big))
+	}
+	if !reflect.DeepEqual(lineDiff(nil, []string{"a"}), []diffLine{{'+', "a"}}) {
+		t.Errorf("diff from nothing = %v", lineDiff(nil, []string{"a"}))
+	}
+}
+
+func TestWriteFileDiff(t *testing.T) {
+	t.Parallel()
+
+	edit := parser.ContentBlock{Type: "tool_use", ID: "t1", Name: "Edit", Input: map[string]interface{}{
+		"file_path": "/r/a.go", "old_string": "old", "new_string": "new",
+	}}
+	tests := []struct {
+		style display.OutputStyle
+		want  []string
+	}{
+		{display.StyleDefault, []string{"┌─ TOOL: Edit\n", "│ --- /r/a.go\n", "│ +++ /r/a.go +1 -1\n", "│ -old\n", "│ +new\n", "└─\n"}},
+		{display.StyleCompact, []string{"TOOL Edit /r/a.go +1 -1\n", "  -old\n", "  +new\n"}},
+		{display.StyleMinimal, []string{"TOOL: Edit\n", "  --- /r/a.go\n", "  -old\n", "  +new\n"}},
+		{display.StylePlain, []string{"TOOL: Edit\n", "  --- /r/a.go\n", "  -old\n", "  +new\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			t.Parallel()
+			lines, _ := fileDiff(edit)
+			var out bytes.Buffer
+			newTermRenderer(&out, &display.Config{Style: tt.style}, nil).writeFileDiff(&out, edit, lines, 1)
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+
+	t.Run("line cap", func(t *testing.T) {
+		t.Parallel()
+		write := parser.ContentBlock{Type: "tool_use", Name: "Write", Input: map[string]interface{}{
+			"file_path": "/r/big.txt", "content": strings.Repeat("line\n", 100),
+		}}
+		lines, _ := fileDiff(write)
+		var out bytes.Buffer
+		newTermRenderer(&out, &display.Config{Style: display.StylePlain}, nil).writeFileDiff(&out, write, lines, 1)
+		if got := strings.Count(out.String(), "+line"); got != maxDiffLines-1 {
+			t.Errorf("showed %d lines, want %d", got, maxDiffLines-1)
+		}
+		if !strings.Contains(out.String(), "... (41 more lines)") {
+			t.Errorf("missing omitted line count:\n%s", out.String())
+		}
+	})
+}
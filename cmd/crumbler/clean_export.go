@@ -0,0 +1,667 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// transcriptDoc collects messages for export as a Markdown or HTML document.
+// Tool results are attached to the calls that made them, so each call and
+// its output render as one collapsible section.
+type transcriptDoc struct {
+	entries []*docEntry
+	calls   map[string]*docEntry // Tool calls by ID
+	model   string
+	session string
+	cwd     string
+}
+
+// docEntry is one section of an exported transcript.
+type docEntry struct {
+	kind      string // system, user, assistant, thinking, tool, result or text
+	text      string
+	tool      string
+	input     map[string]interface{}
+	output    string
+	hasOutput bool
+	isError   bool
+}
+
+// newTranscriptDoc returns an empty document.
+func newTranscriptDoc() *transcriptDoc {
+	return &transcriptDoc{calls: make(map[string]*docEntry)}
+}
+
+// add records one message.
+func (d *transcriptDoc) add(msg *parser.StreamMessage) {
+	var blocks []parser.ContentBlock
+	if msg.Message != nil {
+		blocks = msg.Message.Content
+		if d.model == "" {
+			d.model = msg.Message.Model
+		}
+	}
+
+	switch msg.Type {
+	case "system":
+		if msg.Subtype == "init" {
+			d.model = firstNonEmpty(msg.Model, d.model)
+			d.session = firstNonEmpty(msg.SessionID, d.session)
+			d.cwd = firstNonEmpty(msg.CWD, d.cwd)
+		}
+		for _, block := range blocks {
+			if block.Type == "text" && block.Text != "" {
+				d.entries = append(d.entries, &docEntry{kind: "system", text: block.Text})
+			}
+		}
+	case "assistant", "user":
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				if strings.TrimSpace(block.Text) != "" {
+					d.entries = append(d.entries, &docEntry{kind: msg.Type, text: block.Text})
+				}
+			case "thinking":
+				if strings.TrimSpace(block.Text) != "" {
+					d.entries = append(d.entries, &docEntry{kind: "thinking", text: block.Text})
+				}
+			case "tool_use":
+				entry := &docEntry{kind: "tool", tool: block.Name, input: block.Input}
+				d.entries = append(d.entries, entry)
+				if block.ID != "" {
+					d.calls[block.ID] = entry
+				}
+			case "tool_result":
+				entry, ok := d.calls[block.ToolUseID]
+				if !ok || entry.hasOutput {
+					entry = &docEntry{kind: "tool", tool: "result"}
+					d.entries = append(d.entries, entry)
+				}
+				entry.output = toolOutputText(block.Content)
+				entry.hasOutput = true
+				entry.isError = block.IsError
+			}
+		}
+	case "result":
+		d.entries = append(d.entries, &docEntry{kind: "result", text: msg.Result, isError: msg.IsError})
+	}
+}
+
+// addText records a line of input that isn't JSON. Consecutive lines
+// share one entry.
+func (d *transcriptDoc) addText(text string) {
+	if n := len(d.entries); n > 0 && d.entries[n-1].kind == "text" {
+		d.entries[n-1].text += "\n" + text
+		return
+	}
+	d.entries = append(d.entries, &docEntry{kind: "text", text: text})
+}
+
+// title returns the document title.
+func (d *transcriptDoc) title() string {
+	if d.model != "" {
+		return "Agent session (" + d.model + ")"
+	}
+	return "Agent session"
+}
+
+// details returns the session details shown under the title.
+func (d *transcriptDoc) details() [][2]string {
+	var rows [][2]string
+	for _, row := range [][2]string{{"Model", d.model}, {"Session", d.session}, {"Working directory", d.cwd}} {
+		if row[1] != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// usageRows returns the usage table for a finished summary.
+func usageRows(s *sessionSummary) [][2]string {
+	return [][2]string{
+		{"Duration", (time.Duration(s.DurationMS) * time.Millisecond).String()},
+		{"Turns", fmt.Sprint(s.Turns)},
+		{"Input tokens", fmt.Sprint(s.InputTokens)},
+		{"Output tokens", fmt.Sprint(s.OutputTokens)},
+		{"Cache read tokens", fmt.Sprint(s.CacheReadTokens)},
+		{"Cache write tokens", fmt.Sprint(s.CacheCreationTokens)},
+		{"Cost", fmt.Sprintf("$%.4f", s.CostUSD)},
+		{"Tool calls", fmt.Sprintf("%d (%s)", s.ToolCalls, plural(s.ToolErrors, "error"))},
+	}
+}
+
+// writeMarkdown writes the document as GitHub-flavored Markdown. Tool calls
+// are <details> blocks, which GitHub renders collapsed.
+func (d *transcriptDoc) writeMarkdown(w io.Writer, s *sessionSummary) error {
+	s.finish()
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", d.title())
+	for _, row := range d.details() {
+		fmt.Fprintf(&b, "- **%s:** `%s`\n", row[0], row[1])
+	}
+
+	b.WriteString("\n## Usage\n\n| | |\n|---|---|\n")
+	for _, row := range usageRows(s) {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], row[1])
+	}
+	if len(s.Tools) > 0 {
+		b.WriteString("\n| Tool | Calls | Errors |\n|---|---:|---:|\n")
+		for _, t := range s.Tools {
+			fmt.Fprintf(&b, "| %s | %d | %d |\n", strings.ReplaceAll(t.Name, "|", `\|`), t.Calls, t.Errors)
+		}
+	}
+
+	b.WriteString("\n## Transcript\n")
+	prev := ""
+	for _, e := range d.entries {
+		switch e.kind {
+		case "system", "user", "assistant":
+			if e.kind != prev {
+				fmt.Fprintf(&b, "\n### %s\n", strings.ToUpper(e.kind[:1])+e.kind[1:])
+			}
+			fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(e.text))
+		case "thinking":
+			fmt.Fprintf(&b, "\n<details>\n<summary>Thinking</summary>\n\n%s\n\n</details>\n", strings.TrimSpace(e.text))
+		case "text":
+			fmt.Fprintf(&b, "\n%s\n", mdFence(e.text, ""))
+		case "tool":
+			fmt.Fprintf(&b, "\n<details>\n<summary>%s</summary>\n\n", html.EscapeString(toolSummary(e)))
+			for _, part := range toolInputParts(e) {
+				if part.lang == "-" {
+					fmt.Fprintf(&b, "- **%s:** %s\n", part.key, part.value)
+					continue
+				}
+				fmt.Fprintf(&b, "**%s:**\n\n%s\n", part.key, mdFence(part.value, part.lang))
+			}
+			if e.hasOutput {
+				label := "Output"
+				if e.isError {
+					label = "Error"
+				}
+				fmt.Fprintf(&b, "\n**%s:**\n\n%s\n", label, mdFence(e.output, outputLang(e)))
+			}
+			b.WriteString("\n</details>\n")
+		case "result":
+			status := "Success"
+			if e.isError {
+				status = "Error"
+			}
+			fmt.Fprintf(&b, "\n### Result: %s\n", status)
+			if text := strings.TrimSpace(e.text); text != "" {
+				fmt.Fprintf(&b, "\n%s\n", text)
+			}
+		}
+		prev = e.kind
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeHTML writes the document as a single HTML page with inline styles
+// and pre-highlighted code, so it works offline. Tool calls are <details>
+// elements, collapsed until clicked.
+func (d *transcriptDoc) writeHTML(w io.Writer, s *sessionSummary) error {
+	s.finish()
+	var b strings.Builder
+	esc := html.EscapeString
+
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n"+
+		"<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n"+
+		"<title>%s</title>\n<style>%s</style>\n</head>\n<body>\n<main>\n", esc(d.title()), docCSS)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", esc(d.title()))
+	if rows := d.details(); len(rows) > 0 {
+		b.WriteString("<ul class=\"details\">\n")
+		for _, row := range rows {
+			fmt.Fprintf(&b, "<li><strong>%s:</strong> <code>%s</code></li>\n", esc(row[0]), esc(row[1]))
+		}
+		b.WriteString("</ul>\n")
+	}
+
+	b.WriteString("<h2>Usage</h2>\n<div class=\"usage\">\n<table>\n")
+	for _, row := range usageRows(s) {
+		fmt.Fprintf(&b, "<tr><th>%s</th><td>%s</td></tr>\n", esc(row[0]), esc(row[1]))
+	}
+	b.WriteString("</table>\n")
+	if len(s.Tools) > 0 {
+		b.WriteString("<table>\n<tr><th>Tool</th><th>Calls</th><th>Errors</th></tr>\n")
+		for _, t := range s.Tools {
+			fmt.Fprintf(&b, "<tr><td>%s</td><td class=\"num\">%d</td><td class=\"num\">%d</td></tr>\n", esc(t.Name), t.Calls, t.Errors)
+		}
+		b.WriteString("</table>\n")
+	}
+	b.WriteString("</div>\n<h2>Transcript</h2>\n")
+
+	for _, e := range d.entries {
+		switch e.kind {
+		case "system", "user", "assistant":
+			fmt.Fprintf(&b, "<section class=\"%s\">\n<h3>%s</h3>\n%s</section>\n", e.kind, strings.ToUpper(e.kind[:1])+e.kind[1:], markdownHTML(e.text))
+		case "thinking":
+			fmt.Fprintf(&b, "<details class=\"thinking\">\n<summary>Thinking</summary>\n%s</details>\n", markdownHTML(e.text))
+		case "text":
+			b.WriteString(codeHTML(e.text, ""))
+		case "tool":
+			class := "tool"
+			if e.isError {
+				class += " error"
+			}
+			fmt.Fprintf(&b, "<details class=\"%s\">\n<summary>%s</summary>\n", class, esc(toolSummary(e)))
+			var fields []string
+			for _, part := range toolInputParts(e) {
+				if part.lang == "-" {
+					fields = append(fields, fmt.Sprintf("<li><strong>%s:</strong> %s</li>", esc(part.key), markdownInline(part.value)))
+					continue
+				}
+				fmt.Fprintf(&b, "<p class=\"label\">%s</p>\n%s", esc(part.key), codeHTML(part.value, part.lang))
+			}
+			if len(fields) > 0 {
+				fmt.Fprintf(&b, "<ul>\n%s\n</ul>\n", strings.Join(fields, "\n"))
+			}
+			if e.hasOutput {
+				label := "Output"
+				if e.isError {
+					label = "Error"
+				}
+				fmt.Fprintf(&b, "<p class=\"label\">%s</p>\n%s", label, codeHTML(e.output, outputLang(e)))
+			}
+			b.WriteString("</details>\n")
+		case "result":
+			status, class := "Success", "result"
+			if e.isError {
+				status, class = "Error", "result error"
+			}
+			fmt.Fprintf(&b, "<section class=\"%s\">\n<h3>Result: %s</h3>\n%s</section>\n", class, status, markdownHTML(e.text))
+		}
+	}
+
+	b.WriteString("</main>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// docCSS styles exported HTML, following the reader's light or dark theme.
+const docCSS = `
+:root { --fg: #1f2328; --bg: #fff; --muted: #59636e; --border: #d1d9e0; --code: #f6f8fa;
+  --assistant: #1a7f37; --tool: #9a6700; --error: #cf222e; --result: #0969da;
+  --k: #cf222e; --s: #0a3069; --c: #59636e; --n: #0550ae; }
+@media (prefers-color-scheme: dark) {
+  :root { --fg: #e6edf3; --bg: #0d1117; --muted: #9198a1; --border: #3d444d; --code: #151b23;
+    --assistant: #3fb950; --tool: #d29922; --error: #f85149; --result: #4493f8;
+    --k: #ff7b72; --s: #a5d6ff; --c: #9198a1; --n: #79c0ff; }
+}
+body { margin: 0; background: var(--bg); color: var(--fg);
+  font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; }
+main { max-width: 960px; margin: 0 auto; padding: 24px; }
+h1 { font-size: 1.6em; } h2 { border-bottom: 1px solid var(--border); padding-bottom: 4px; }
+h3 { font-size: 1em; margin: 0 0 8px; text-transform: uppercase; letter-spacing: .04em; }
+ul.details { list-style: none; padding: 0; color: var(--muted); }
+.usage { display: flex; flex-wrap: wrap; gap: 24px; align-items: flex-start; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid var(--border); padding: 4px 10px; text-align: left; }
+td.num { text-align: right; }
+section, details { border-left: 3px solid var(--border); margin: 12px 0; padding: 8px 12px; }
+section.assistant { border-color: var(--assistant); } section.assistant h3 { color: var(--assistant); }
+section.result { border-color: var(--result); } section.result h3 { color: var(--result); }
+details.tool { border-color: var(--tool); }
+details.error, section.error { border-color: var(--error); } section.error h3 { color: var(--error); }
+summary { cursor: pointer; font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: .9em; }
+details.error summary { color: var(--error); }
+details.thinking { color: var(--muted); }
+p.label { margin: 8px 0 4px; font-weight: 600; font-size: .85em; color: var(--muted); }
+pre { background: var(--code); padding: 10px; overflow-x: auto; border-radius: 6px; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: .85em; }
+p code, li code { background: var(--code); padding: 1px 4px; border-radius: 4px; }
+.k { color: var(--k); } .s { color: var(--s); } .c { color: var(--c); font-style: italic; } .n { color: var(--n); }
+`
+
+// inputPart is one field of a tool input. lang is the code block language,
+// or "-" for a short value shown inline.
+type inputPart struct {
+	key, value, lang string
+}
+
+// toolInputParts splits a tool input into display fields. Multi-line
+// strings become code blocks, highlighted by the file's extension; other
+// structured values become JSON.
+func toolInputParts(e *docEntry) []inputPart {
+	keys := make([]string, 0, len(e.input))
+	for key := range e.input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fileLang := ""
+	if path, ok := e.input["file_path"].(string); ok {
+		fileLang = langForPath(path)
+	}
+
+	var parts []inputPart
+	for _, key := range keys {
+		switch v := e.input[key].(type) {
+		case string:
+			switch {
+			case key == "command":
+				parts = append(parts, inputPart{key, v, "bash"})
+			case strings.Contains(v, "\n"):
+				parts = append(parts, inputPart{key, v, fileLang})
+			default:
+				parts = append(parts, inputPart{key, "`" + v + "`", "-"})
+			}
+		case nil:
+		default:
+			data, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				continue
+			}
+			if len(data) <= 60 && !strings.Contains(string(data), "\n") {
+				parts = append(parts, inputPart{key, "`" + string(data) + "`", "-"})
+			} else {
+				parts = append(parts, inputPart{key, string(data), "json"})
+			}
+		}
+	}
+	return parts
+}
+
+// toolSummary returns a one-line description of a tool call.
+func toolSummary(e *docEntry) string {
+	summary := e.tool
+	for _, key := range []string{"command", "file_path", "path", "pattern", "query", "url", "description", "prompt"} {
+		if v, ok := e.input[key].(string); ok && v != "" {
+			v = strings.Join(strings.Fields(v), " ")
+			if utf8.RuneCountInString(v) > 80 {
+				v = string([]rune(v)[:77]) + "..."
+			}
+			summary += ": " + v
+			break
+		}
+	}
+	if e.isError {
+		summary += " (error)"
+	}
+	return summary
+}
+
+// outputLang returns the language of a tool's output: a Read of a source
+// file is highlighted as that file, everything else as plain text.
+func outputLang(e *docEntry) string {
+	if e.tool == "Read" {
+		if path, ok := e.input["file_path"].(string); ok {
+			return langForPath(path)
+		}
+	}
+	return ""
+}
+
+// toolOutputText returns a tool result's content as text.
+func toolOutputText(content interface{}) string {
+	switch c := content.(type) {
+	case nil:
+		return ""
+	case string:
+		return c
+	case []interface{}:
+		if text := openaiText(c); text != "" {
+			return text
+		}
+	}
+	data, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return fmt.Sprint(content)
+	}
+	return string(data)
+}
+
+// langForPath returns the code block language for a file, or "".
+func langForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js", ".mjs", ".cjs", ".jsx":
+		return "javascript"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".sh", ".bash", ".zsh":
+		return "bash"
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".rs":
+		return "rust"
+	case ".rb":
+		return "ruby"
+	case ".java":
+		return "java"
+	case ".c", ".h":
+		return "c"
+	case ".cc", ".cpp", ".hpp":
+		return "cpp"
+	case ".sql":
+		return "sql"
+	case ".css":
+		return "css"
+	case ".html":
+		return "html"
+	case ".md":
+		return "markdown"
+	}
+	return ""
+}
+
+// mdFence wraps code in a fence longer than any backtick run inside it.
+func mdFence(code, lang string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(code, "\n") + "\n" + fence
+}
+
+var (
+	fenceRe      = regexp.MustCompile("(?s)```([\\w+-]*)\\n(.*?)```")
+	inlineCodeRe = regexp.MustCompile("`([^`\n]+)`")
+	boldRe       = regexp.MustCompile(`\*\*([^*\n]+)\*\*`)
+	paragraphRe  = regexp.MustCompile(`\n\s*\n`)
+)
+
+// markdownHTML converts the Markdown agents write to HTML: fenced code
+// blocks, paragraphs, line breaks, inline code and bold.
+func markdownHTML(text string) string {
+	var b strings.Builder
+	for {
+		loc := fenceRe.FindStringSubmatchIndex(text)
+		if loc == nil {
+			break
+		}
+		b.WriteString(paragraphsHTML(text[:loc[0]]))
+		b.WriteString(codeHTML(text[loc[4]:loc[5]], text[loc[2]:loc[3]]))
+		text = text[loc[1]:]
+	}
+	b.WriteString(paragraphsHTML(text))
+	return b.String()
+}
+
+// paragraphsHTML converts text without code blocks to paragraphs.
+func paragraphsHTML(text string) string {
+	var b strings.Builder
+	for _, para := range paragraphRe.Split(strings.TrimSpace(text), -1) {
+		if para = strings.TrimSpace(para); para != "" {
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.ReplaceAll(markdownInline(para), "\n", "<br>\n"))
+		}
+	}
+	return b.String()
+}
+
+// markdownInline escapes text and converts inline code and bold.
+func markdownInline(text string) string {
+	text = html.EscapeString(text)
+	text = inlineCodeRe.ReplaceAllString(text, "<code>$1</code>")
+	return boldRe.ReplaceAllString(text, "<strong>$1</strong>")
+}
+
+// codeHTML returns a highlighted <pre> block.
+func codeHTML(code, lang string) string {
+	return "<pre><code>" + highlight(strings.TrimRight(code, "\n"), lang) + "</code></pre>\n"
+}
+
+// syntax describes a language for highlight.
+type syntax struct {
+	keywords     map[string]bool
+	lineComment  []string
+	blockComment bool // Supports /* ... */
+	backticks    bool // Backticks quote strings
+	singleQuotes bool // Single quotes quote strings
+}
+
+// syntaxes are the languages highlight knows, by code block language.
+var syntaxes = func() map[string]*syntax {
+	words := func(s string) map[string]bool {
+		m := make(map[string]bool)
+		for _, w := range strings.Fields(s) {
+			m[w] = true
+		}
+		return m
+	}
+	cLike := []string{"//"}
+	hash := []string{"#"}
+	js := &syntax{keywords: words("async await break case catch class const continue default delete do else export extends false finally for from function if import in instanceof interface let new null return switch this throw true try type typeof undefined var void while yield"), lineComment: cLike, blockComment: true, backticks: true, singleQuotes: true}
+	m := map[string]*syntax{
+		"go":         {keywords: words("break case chan const continue default defer else fallthrough false for func go goto if import interface iota map nil package range return select struct switch true type var"), lineComment: cLike, blockComment: true, backticks: true, singleQuotes: true},
+		"python":     {keywords: words("and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield"), lineComment: hash, singleQuotes: true},
+		"javascript": js,
+		"typescript": js,
+		"bash":       {keywords: words("case do done elif else esac export fi for function if in local return then until while"), lineComment: hash, singleQuotes: true},
+		"json":       {keywords: words("true false null")},
+		"yaml":       {keywords: words("true false null"), lineComment: hash, singleQuotes: true},
+		"toml":       {keywords: words("true false"), lineComment: hash, singleQuotes: true},
+		"rust":       {keywords: words("as async await break const continue crate else enum false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while"), lineComment: cLike, blockComment: true},
+		"ruby":       {keywords: words("begin class def do else elsif end ensure false for if in module next nil not or rescue return self then true unless until when while yield"), lineComment: hash, singleQuotes: true},
+		"java":       {keywords: words("abstract boolean break case catch class continue default do double else extends false final finally float for if implements import int interface long new null package private protected public return static super switch this throw throws true try void while"), lineComment: cLike, blockComment: true},
+		"c":          {keywords: words("break case char const continue default do double else enum extern float for goto if int long return short signed sizeof static struct switch typedef union unsigned void while"), lineComment: cLike, blockComment: true, singleQuotes: true},
+		"sql":        {keywords: words("select from where insert into values update set delete create table drop alter and or not null join left right inner on group by order limit as SELECT FROM WHERE INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER AND OR NOT NULL JOIN LEFT RIGHT INNER ON GROUP BY ORDER LIMIT AS"), lineComment: []string{"--"}, singleQuotes: true},
+	}
+	m["cpp"], m["sh"], m["shell"], m["js"], m["ts"], m["py"] = m["c"], m["bash"], m["bash"], js, js, m["python"]
+	return m
+}()
+
+// highlight escapes code for HTML, wrapping keywords, strings, comments and
+// numbers in spans when the language is known.
+func highlight(code, lang string) string {
+	syn, ok := syntaxes[strings.ToLower(lang)]
+	if !ok {
+		return html.EscapeString(code)
+	}
+
+	var b strings.Builder
+	span := func(class, text string) {
+		fmt.Fprintf(&b, "<span class=\"%s\">%s</span>", class, html.EscapeString(text))
+	}
+	isWord := func(c byte) bool {
+		return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	}
+
+	for i := 0; i < len(code); {
+		c := code[i]
+		rest := code[i:]
+
+		if syn.blockComment && strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			n := len(rest)
+			if end >= 0 {
+				n = end + 4
+			}
+			span("c", rest[:n])
+			i += n
+			continue
+		}
+		if comment := lineCommentAt(syn, code, i); comment {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			span("c", rest[:n])
+			i += n
+			continue
+		}
+		if c == '"' || (c == '\'' && syn.singleQuotes) || (c == '`' && syn.backticks) {
+			n := 1
+			for n < len(rest) && rest[n] != c {
+				if rest[n] == '\n' && c != '`' {
+					break // Unterminated
+				}
+				if rest[n] == '\\' && c != '`' && n+1 < len(rest) {
+					n++ // Skip the escaped character
+				}
+				n++
+			}
+			if n < len(rest) && rest[n] == c {
+				n++
+			}
+			span("s", rest[:n])
+			i += n
+			continue
+		}
+		if isWord(c) && (i == 0 || !isWord(code[i-1])) {
+			n := 0
+			for n < len(rest) && isWord(rest[n]) {
+				n++
+			}
+			word := rest[:n]
+			switch {
+			case syn.keywords[word]:
+				span("k", word)
+			case word[0] >= '0' && word[0] <= '9':
+				span("n", word)
+			default:
+				b.WriteString(html.EscapeString(word))
+			}
+			i += n
+			continue
+		}
+		_, n := utf8.DecodeRuneInString(rest)
+		b.WriteString(html.EscapeString(rest[:n]))
+		i += n
+	}
+	return b.String()
+}
+
+// lineCommentAt reports whether a line comment starts at code[i]. Comments
+// marked with # must start a word, so shell arguments like a#b aren't
+// mistaken for one.
+func lineCommentAt(syn *syntax, code string, i int) bool {
+	for _, marker := range syn.lineComment {
+		if !strings.HasPrefix(code[i:], marker) {
+			continue
+		}
+		if marker != "#" || i == 0 || code[i-1] == ' ' || code[i-1] == '\t' || code[i-1] == '\n' {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty returns the first of values that isn't empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
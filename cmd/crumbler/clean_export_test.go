@@ -0,0 +1,184 @@
+package crumbler
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// exportLog adds assistant Markdown, a Write call and a system init to
+// exercise the document renderers.
+const exportLog = `{"type":"system","subtype":"init","session_id":"s2","model":"claude-x","cwd":"/repo"}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Here is **the plan**:\n\n` + "```go\\nfunc main() { // start\\n\\tprintln(\\\"<b>\\\", 42)\\n}\\n```" + `\nDone with ` + "`x < y`" + `."},{"type":"tool_use","id":"t1","name":"Write","input":{"file_path":"/repo/a.py","content":"def f():\n    return 'x'  # c\n"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":[{"type":"text","text":"File written"}]}]}}
+{"type":"result","subtype":"success","result":"All good","num_turns":1,"total_cost_usd":0.5}
+`
+
+// exportDoc runs input through clean's export path.
+func exportDoc(t *testing.T, input string) (*transcriptDoc, *sessionSummary) {
+	t.Helper()
+	opts := &cleanOptions{summary: newSessionSummary(), doc: newTranscriptDoc()}
+	if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	return opts.doc, opts.summary
+}
+
+func TestTranscriptDocMarkdown(t *testing.T) {
+	t.Parallel()
+
+	doc, summary := exportDoc(t, exportLog)
+	var out bytes.Buffer
+	if err := doc.writeMarkdown(&out, summary); err != nil {
+		t.Fatal(err)
+	}
+	md := out.String()
+
+	for _, want := range []string{
+		"# Agent session (claude-x)",
+		"- **Working directory:** `/repo`",
+		"| Cost | $0.5000 |",
+		"| Write | 1 | 0 |",
+		"### Assistant\n\nHere is **the plan**:",
+		"<summary>Write: /repo/a.py</summary>",
+		"```python\ndef f():\n    return 'x'  # c\n```",
+		"**Output:**\n\n```\nFile written\n```",
+		"### Result: Success\n\nAll good",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+	// Tool calls and their output share one collapsible section
+	if strings.Count(md, "<details>") != 1 || strings.Count(md, "</details>") != 1 {
+		t.Errorf("want one <details> section:\n%s", md)
+	}
+}
+
+func TestTranscriptDocHTML(t *testing.T) {
+	t.Parallel()
+
+	doc, summary := exportDoc(t, exportLog+`{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"orphan","content":"<script>alert(1)</script>","is_error":true}]}}`)
+	var out bytes.Buffer
+	if err := doc.writeHTML(&out, summary); err != nil {
+		t.Fatal(err)
+	}
+	page := out.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Agent session (claude-x)</title>",
+		"<tr><th>Cost</th><td>$0.5000</td></tr>",
+		"<p>Here is <strong>the plan</strong>:</p>",
+		`<span class="k">func</span> main() { <span class="c">// start</span>`,
+		`<span class="s">&#34;&lt;b&gt;&#34;</span>, <span class="n">42</span>`,
+		"<code>x &lt; y</code>",
+		`<details class="tool">` + "\n<summary>Write: /repo/a.py</summary>",
+		`<details class="tool error">`,
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<script") {
+		t.Error("HTML must escape tool output")
+	}
+	// Works offline: nothing is loaded from elsewhere
+	if regexp.MustCompile(`(?i)(src|href)=|@import|url\(`).MatchString(page) {
+		t.Error("HTML must not reference external assets")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		code string
+		lang string
+		want string
+	}{
+		{code: "x < y", lang: "", want: "x &lt; y"},
+		{code: "return nil", lang: "go", want: `<span class="k">return</span> <span class="k">nil</span>`},
+		{code: `"a\"b" x`, lang: "go", want: `<span class="s">&#34;a\&#34;b&#34;</span> x`},
+		{code: "/* a\nb */ 1", lang: "c", want: "<span class=\"c\">/* a\nb */</span> <span class=\"n\">1</span>"},
+		{code: "echo a#b # note", lang: "bash", want: `echo a#b <span class="c"># note</span>`},
+		{code: `{"ok": true}`, lang: "json", want: `{<span class="s">&#34;ok&#34;</span>: <span class="k">true</span>}`},
+		{code: "x2 = 'open", lang: "python", want: `x2 = <span class="s">&#39;open</span>`},
+		{code: "naïve := café(π) // ünï", lang: "go", want: `naïve := café(π) <span class="c">// ünï</span>`},
+		{code: "echo 日本語 → ok", lang: "bash", want: "echo 日本語 → ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang+" "+tt.code, func(t *testing.T) {
+			t.Parallel()
+			if got := highlight(tt.code, tt.lang); got != tt.want {
+				t.Errorf("highlight() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptDocMultibyte(t *testing.T) {
+	t.Parallel()
+
+	// Long tool input is cut on a character, not in the middle of one
+	command := "echo " + strings.Repeat("é", 100)
+	input := `{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Voilà, the naïve fix: ` + "```go\\nx := \\\"über\\\" // größe\\nrésumé()\\n```" + `"},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"` + command + `"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"日本語"}]}}
+`
+	doc, summary := exportDoc(t, input)
+	var md, page bytes.Buffer
+	if err := doc.writeMarkdown(&md, summary); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.writeHTML(&page, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	summaryLine := "Bash: echo " + strings.Repeat("é", 72) + "..."
+	for name, out := range map[string]string{"Markdown": md.String(), "HTML": page.String()} {
+		if !utf8.ValidString(out) {
+			t.Errorf("%s is not valid UTF-8", name)
+		}
+		for _, want := range []string{"Voilà, the naïve fix:", summaryLine, "日本語"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s missing %q:\n%s", name, want, out)
+			}
+		}
+	}
+	for _, want := range []string{`<span class="c">// größe</span>`, "résumé()"} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("HTML missing %q:\n%s", want, page.String())
+		}
+	}
+}
+
+func TestMdFence(t *testing.T) {
+	t.Parallel()
+
+	if got := mdFence("a\n", "go"); got != "```go\na\n```" {
+		t.Errorf("mdFence() = %q", got)
+	}
+	if got := mdFence("x ``` y", ""); got != "````\nx ``` y\n````" {
+		t.Errorf("mdFence() with backticks = %q", got)
+	}
+}
+
+func TestCleanOutputFlags(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{"--output"},
+		{"--output", "pdf"},
+		{"--output", "md", "--summary"},
+	} {
+		if err := runClean(args); err == nil {
+			t.Errorf("runClean(%q) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// messageKinds are the names --only and --exclude accept. Besides the
+// message types, "tool" matches messages carrying tool calls or results,
+// "error" matches failed results and tool errors, and "text" matches input
+// lines that aren't JSON.
+var messageKinds = []string{"system", "assistant", "user", "result", "tool", "error", "text"}
+
+// messageFilter decides which parsed messages are rendered.
+type messageFilter struct {
+	only    map[string]bool // Kinds to show; empty shows all
+	exclude map[string]bool // Kinds to hide
+	tools   map[string]bool // Tool names to show; empty shows all
+	grep    *regexp.Regexp  // Pattern the message text must match
+
+	toolNames map[string]string // Tool name by tool_use ID, for results
+}
+
+// newMessageFilter returns a filter that shows every message.
+func newMessageFilter() *messageFilter {
+	return &messageFilter{
+		only:      make(map[string]bool),
+		exclude:   make(map[string]bool),
+		tools:     make(map[string]bool),
+		toolNames: make(map[string]string),
+	}
+}
+
+// active reports whether the filter can hide anything.
+func (f *messageFilter) active() bool {
+	return len(f.only) > 0 || len(f.exclude) > 0 || len(f.tools) > 0 || f.grep != nil
+}
+
+// addKinds adds a comma-separated list of kinds to set.
+func addKinds(set map[string]bool, list string) error {
+	for _, kind := range strings.Split(list, ",") {
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if kind == "" {
+			continue
+		}
+		known := false
+		for _, k := range messageKinds {
+			known = known || k == kind
+		}
+		if !known {
+			return fmt.Errorf("unknown message kind %q (use %s)", kind, strings.Join(messageKinds, ", "))
+		}
+		set[kind] = true
+	}
+	return nil
+}
+
+// addTools adds a comma-separated list of tool names.
+func (f *messageFilter) addTools(list string) {
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			f.tools[name] = true
+		}
+	}
+}
+
+// match reports whether msg should be rendered. It must see every message
+// in order, so tool results can be matched to the calls that made them.
+func (f *messageFilter) match(msg *parser.StreamMessage) bool {
+	var blocks []parser.ContentBlock
+	if msg.Message != nil {
+		blocks = msg.Message.Content
+	}
+	for _, block := range blocks {
+		if block.Type == "tool_use" && block.ID != "" {
+			f.toolNames[block.ID] = block.Name
+		}
+	}
+
+	kinds := messageKindsOf(msg, blocks)
+	if len(f.only) > 0 && !anyKind(f.only, kinds) {
+		return false
+	}
+	if anyKind(f.exclude, kinds) {
+		return false
+	}
+
+	if len(f.tools) > 0 {
+		used := false
+		for _, block := range blocks {
+			switch block.Type {
+			case "tool_use":
+				used = used || f.tools[block.Name]
+			case "tool_result":
+				used = used || f.tools[f.toolNames[block.ToolUseID]]
+			}
+		}
+		if !used {
+			return false
+		}
+	}
+
+	if f.grep != nil && !f.grep.MatchString(messageText(msg, blocks)) {
+		return false
+	}
+	return true
+}
+
+// matchText reports whether a line of input that isn't JSON should be
+// printed. Such lines have kind "text" and never match --tool.
+func (f *messageFilter) matchText(text string) bool {
+	kinds := []string{"text"}
+	if len(f.only) > 0 && !anyKind(f.only, kinds) {
+		return false
+	}
+	if anyKind(f.exclude, kinds) || len(f.tools) > 0 {
+		return false
+	}
+	return f.grep == nil || f.grep.MatchString(text)
+}
+
+// messageKindsOf returns the kinds a message belongs to.
+func messageKindsOf(msg *parser.StreamMessage, blocks []parser.ContentBlock) []string {
+	kinds := []string{msg.Type}
+	if msg.IsError {
+		kinds = append(kinds, "error")
+	}
+	for _, block := range blocks {
+		switch block.Type {
+		case "tool_use":
+			kinds = append(kinds, "tool")
+		case "tool_result":
+			kinds = append(kinds, "tool")
+			if block.IsError {
+				kinds = append(kinds, "error")
+			}
+		}
+	}
+	return kinds
+}
+
+// anyKind reports whether any of kinds is in set.
+func anyKind(set map[string]bool, kinds []string) bool {
+	for _, kind := range kinds {
+		if set[kind] {
+			return true
+		}
+	}
+	return false
+}
+
+// messageText returns the text --grep searches: message text, tool names,
+// inputs and results, and the final result.
+func messageText(msg *parser.StreamMessage, blocks []parser.ContentBlock) string {
+	var b strings.Builder
+	b.WriteString(msg.Result)
+	for _, block := range blocks {
+		b.WriteString("\n")
+		b.WriteString(block.Text)
+		if block.Name != "" {
+			b.WriteString("\n" + block.Name)
+		}
+		if len(block.Input) > 0 {
+			if input, err := json.Marshal(block.Input); err == nil {
+				b.WriteString("\n")
+				b.Write(input)
+			}
+		}
+		switch content := block.Content.(type) {
+		case nil:
+		case string:
+			b.WriteString("\n" + content)
+		default:
+			if data, err := json.Marshal(content); err == nil {
+				b.WriteString("\n")
+				b.Write(data)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,138 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+func TestMessageFilter(t *testing.T) {
+	t.Parallel()
+
+	// Messages in sessionLog: system, assistant text, assistant Bash+Read
+	// calls, user results (Bash failed), assistant Bash call, result
+	tests := []struct {
+		name    string
+		only    string
+		exclude string
+		tools   string
+		grep    string
+		want    []int
+	}{
+		{name: "no filter", want: []int{0, 1, 2, 3, 4, 5}},
+		{name: "only assistant", only: "assistant", want: []int{1, 2, 4}},
+		{name: "only assistant and tool", only: "assistant,tool", want: []int{1, 2, 3, 4}},
+		{name: "only errors", only: "error", want: []int{3}},
+		{name: "exclude result", exclude: "result", want: []int{0, 1, 2, 3, 4}},
+		{name: "exclude tool", exclude: "tool", want: []int{0, 1, 5}},
+		{name: "tool Bash", tools: "Bash", want: []int{2, 3, 4}},
+		{name: "tool Read", tools: "Read", want: []int{2, 3}},
+		{name: "tool missing", tools: "Write", want: nil},
+		{name: "grep tool input", grep: `"command":"pwd"`, want: []int{4}},
+		{name: "grep tool output", grep: "boom", want: []int{3}},
+		{name: "grep text", grep: "(?i)looking", want: []int{1}},
+		{name: "combined", only: "assistant", tools: "Bash", grep: "ls", want: []int{2}},
+	}
+
+	lines := strings.Split(strings.TrimSpace(sessionLog), "\n")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newMessageFilter()
+			if err := addKinds(f.only, tt.only); err != nil {
+				t.Fatal(err)
+			}
+			if err := addKinds(f.exclude, tt.exclude); err != nil {
+				t.Fatal(err)
+			}
+			f.addTools(tt.tools)
+			if tt.grep != "" {
+				f.grep = regexp.MustCompile(tt.grep)
+			}
+
+			var got []int
+			for i, line := range lines {
+				var msg parser.StreamMessage
+				if err := json.Unmarshal([]byte(line), &msg); err != nil {
+					t.Fatal(err)
+				}
+				if f.match(&msg) {
+					got = append(got, i)
+				}
+			}
+			if !equalInts(got, tt.want) {
+				t.Errorf("matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddKinds(t *testing.T) {
+	t.Parallel()
+
+	set := make(map[string]bool)
+	if err := addKinds(set, "Assistant, tool,"); err != nil || !set["assistant"] || !set["tool"] || len(set) != 2 {
+		t.Errorf("addKinds() = %v, %v", set, err)
+	}
+	if err := addKinds(set, "assistant,bogus"); err == nil {
+		t.Error("addKinds() should reject unknown kinds")
+	}
+}
+
+func TestCleanTee(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.jsonl")
+	raw := sessionLog + `{"truncated":` // Invalid trailing bytes are kept too
+	if err := os.WriteFile(input, []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	teeFile := filepath.Join(dir, "tee.jsonl")
+	if err := runClean([]string{"--tee", teeFile, "--only", "error", "-s", "plain", input}); err != nil {
+		t.Fatalf("runClean() error: %v", err)
+	}
+	data, err := os.ReadFile(teeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != raw {
+		t.Errorf("tee file differs from input:\n%s", data)
+	}
+}
+
+func TestCleanFlagErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{"--tee"},
+		{"--only"},
+		{"--only", "nonsense"},
+		{"--tool"},
+		{"--grep", "("},
+		{"--summary=xml"},
+	} {
+		if err := runClean(args); err == nil {
+			t.Errorf("runClean(%q) should fail", args)
+		}
+	}
+}
+
+// equalInts reports whether two int slices hold the same values.
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package crumbler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// followPollInterval is how often a followed file is checked for new data.
+const followPollInterval = 250 * time.Millisecond
+
+// followReader reads a file like tail -f: at the end of the file it waits
+// for more data instead of returning io.EOF. When the file shrinks
+// (truncation) it starts over from the beginning, and when the path is
+// replaced by a new file (rotation) it finishes the old file and switches
+// to the new one. It returns io.EOF once ctx is done.
+type followReader struct {
+	ctx      context.Context
+	path     string
+	interval time.Duration
+	file     *os.File
+	offset   int64
+	restart  bool // Set when the stream started over
+}
+
+// openFollow opens path for following.
+func openFollow(ctx context.Context, path string, interval time.Duration) (*followReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &followReader{ctx: ctx, path: path, interval: interval, file: file}, nil
+}
+
+// Read reads available data, waiting for more at the end of the file.
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.file.Read(p)
+		f.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		// At the end of the file: see if it was truncated or replaced
+		switched, err := f.check()
+		if err != nil {
+			return 0, err
+		}
+		if switched {
+			continue
+		}
+
+		select {
+		case <-f.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(f.interval):
+		}
+	}
+}
+
+// check reopens or rewinds the file if it was rotated or truncated,
+// reporting whether it did.
+func (f *followReader) check() (bool, error) {
+	info, err := os.Stat(f.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil // Mid-rotation; wait for the new file
+	} else if err != nil {
+		return false, err
+	}
+	current, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	if !os.SameFile(info, current) {
+		if current.Size() > f.offset {
+			return true, nil // Finish what was written to the old file first
+		}
+		file, err := os.Open(f.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		f.file.Close()
+		f.file, f.offset, f.restart = file, 0, true
+		return true, nil
+	}
+
+	if info.Size() < f.offset {
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		f.offset, f.restart = 0, true
+		return true, nil
+	}
+	return false, nil
+}
+
+// restarted reports whether the stream started over since the last call.
+func (f *followReader) restarted() bool {
+	restart := f.restart
+	f.restart = false
+	return restart
+}
+
+// Close closes the current file.
+func (f *followReader) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,148 @@
+package crumbler
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// readUntil reads from r until the data read ends with want.
+func readUntil(t *testing.T, r io.Reader, want string) {
+	t.Helper()
+	var got []byte
+	buf := make([]byte, 64)
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.HasSuffix(string(got), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("read %q, want it to end with %q", got, want)
+		}
+		n, err := r.Read(buf)
+		if err != nil {
+			t.Fatalf("Read() error: %v (read %q)", err, got)
+		}
+		got = append(got, buf[:n]...)
+	}
+}
+
+func TestFollowReader(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	if err := os.WriteFile(path, []byte("first\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	f, err := openFollow(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	readUntil(t, f, "first\n")
+
+	// Appended data is read once written
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		appendFile(t, path, "second\n")
+	}()
+	readUntil(t, f, "second\n")
+	if f.restarted() {
+		t.Error("appending should not restart the stream")
+	}
+
+	// Truncation starts over
+	if err := os.WriteFile(path, []byte("new\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, f, "new\n")
+	if !f.restarted() {
+		t.Error("truncation should restart the stream")
+	}
+
+	// Rotation finishes the old file, then reads the new one
+	appendFile(t, path, "old tail\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("rotated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	readUntil(t, f, "old tail\n")
+	readUntil(t, f, "rotated\n")
+	if !f.restarted() {
+		t.Error("rotation should restart the stream")
+	}
+
+	// Cancelling ends the stream
+	cancel()
+	if n, err := f.Read(make([]byte, 8)); n != 0 || err != io.EOF {
+		t.Errorf("Read() after cancel = %d, %v; want 0, io.EOF", n, err)
+	}
+}
+
+func TestFollowPartialJSON(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	lines := strings.SplitAfter(strings.TrimSpace(sessionLog), "\n")
+	// Start mid-object, and leave a partial object that is then truncated away
+	if err := os.WriteFile(path, []byte(lines[0]+lines[1][:40]), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := openFollow(ctx, path, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	summary := newSessionSummary()
+	done := make(chan error, 1)
+	go func() {
+		done <- processJSONStream(f, &display.Config{}, &cleanOptions{summary: summary, summaryJSON: true})
+	}()
+
+	time.Sleep(30 * time.Millisecond)
+	appendFile(t, path, lines[1][40:]) // Completes the object across reads
+	time.Sleep(30 * time.Millisecond)
+	appendFile(t, path, `{"type":"assistant","message":{"id":"cut`)
+	time.Sleep(30 * time.Millisecond)
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	appendFile(t, path, strings.Join(lines[2:], ""))
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("processJSONStream() error: %v", err)
+	}
+	summary.finish()
+	if summary.Messages != len(lines) || summary.ToolCalls != 3 || summary.Turns != 3 {
+		t.Errorf("summary = %d messages, %d tool calls, %d turns; want %d, 3, 3",
+			summary.Messages, summary.ToolCalls, summary.Turns, len(lines))
+	}
+}
+
+// appendFile appends data to the file at path.
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer file.Close()
+	if _, err := file.WriteString(data); err != nil {
+		t.Error(err)
+	}
+}
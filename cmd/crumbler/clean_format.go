@@ -0,0 +1,671 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// transcriptFormat normalizes one agent CLI's JSON output into
+// parser.StreamMessage values, the event model everything in clean consumes:
+// the renderer, the filters and the summary. Claude's stream-json is the
+// richest of the formats, so the others map onto it: text and tool calls
+// become assistant content blocks, tool output becomes tool_result blocks in
+// user messages, and totals and failures become result messages.
+type transcriptFormat interface {
+	// detect reports whether a JSON object looks like this format.
+	detect(obj map[string]json.RawMessage) bool
+	// convert returns the messages for one JSON object, possibly none.
+	convert(data []byte) ([]parser.StreamMessage, error)
+	// flush returns messages held back waiting for more input.
+	flush() []parser.StreamMessage
+}
+
+// transcriptFormats lists the formats in auto-detection order.
+var transcriptFormats = []struct {
+	name string
+	new  func() transcriptFormat
+}{
+	{"claude", func() transcriptFormat { return claudeFormat{} }},
+	{"codex", func() transcriptFormat { return &codexFormat{started: make(map[string]bool)} }},
+	{"gemini", func() transcriptFormat { return &geminiFormat{} }},
+	{"aider", func() transcriptFormat { return aiderFormat{} }},
+	{"openai", func() transcriptFormat { return openaiFormat{} }},
+}
+
+// transcriptReader converts JSON objects using a chosen or detected format.
+type transcriptReader struct {
+	format transcriptFormat // nil until detected
+}
+
+// newTranscriptReader returns a reader for the named format, or one that
+// detects the format when name is "auto" or empty.
+func newTranscriptReader(name string) (*transcriptReader, error) {
+	if name == "" || name == "auto" {
+		return &transcriptReader{}, nil
+	}
+	var names []string
+	for _, f := range transcriptFormats {
+		if f.name == name {
+			return &transcriptReader{format: f.new()}, nil
+		}
+		names = append(names, f.name)
+	}
+	return nil, fmt.Errorf("unknown format %q (use auto, %s)", name, strings.Join(names, ", "))
+}
+
+// read converts one JSON object. When detecting, the first object a format
+// recognizes selects it for the rest of the stream; objects before that are
+// read as Claude stream-json, as clean always has.
+func (r *transcriptReader) read(data []byte) ([]parser.StreamMessage, error) {
+	if r.format == nil {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return nil, err
+		}
+		for _, f := range transcriptFormats {
+			if format := f.new(); format.detect(obj) {
+				r.format = format
+				break
+			}
+		}
+		if r.format == nil {
+			return claudeFormat{}.convert(data)
+		}
+	}
+	return r.format.convert(data)
+}
+
+// flush returns any messages the format is still holding.
+func (r *transcriptReader) flush() []parser.StreamMessage {
+	if r.format == nil {
+		return nil
+	}
+	return r.format.flush()
+}
+
+// claudeFormat reads Claude Code stream-json, which needs no conversion.
+type claudeFormat struct{}
+
+// claudeTypes are the message types Claude Code streams.
+var claudeTypes = map[string]bool{"system": true, "assistant": true, "user": true, "result": true, "stream_event": true}
+
+func (claudeFormat) detect(obj map[string]json.RawMessage) bool {
+	if !claudeTypes[jsonString(obj, "type")] {
+		return false
+	}
+	return hasKey(obj, "message") || hasKey(obj, "subtype") || hasKey(obj, "session_id")
+}
+
+func (claudeFormat) convert(data []byte) ([]parser.StreamMessage, error) {
+	var msg parser.StreamMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return []parser.StreamMessage{msg}, nil
+}
+
+func (claudeFormat) flush() []parser.StreamMessage { return nil }
+
+// codexFormat reads the events of 'codex exec --json': thread, turn and
+// item events, where items are messages, reasoning, commands, file changes,
+// MCP tool calls, web searches and todo lists.
+type codexFormat struct {
+	started map[string]bool // Items whose tool call was already shown
+}
+
+type codexEvent struct {
+	Type     string      `json:"type"`
+	ThreadID string      `json:"thread_id"`
+	Message  string      `json:"message"`
+	Usage    *codexUsage `json:"usage"`
+	Error    *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Item *codexItem `json:"item"`
+}
+
+type codexUsage struct {
+	InputTokens       int `json:"input_tokens"`
+	CachedInputTokens int `json:"cached_input_tokens"`
+	OutputTokens      int `json:"output_tokens"`
+}
+
+type codexItem struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	Text             string `json:"text"`
+	Message          string `json:"message"`
+	Command          string `json:"command"`
+	AggregatedOutput string `json:"aggregated_output"`
+	ExitCode         *int   `json:"exit_code"`
+	Status           string `json:"status"`
+	Changes          []struct {
+		Path string `json:"path"`
+		Kind string `json:"kind"`
+	} `json:"changes"`
+	Server    string      `json:"server"`
+	Tool      string      `json:"tool"`
+	Arguments interface{} `json:"arguments"`
+	Result    interface{} `json:"result"`
+	Error     *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Query string `json:"query"`
+	Items []struct {
+		Text      string `json:"text"`
+		Completed bool   `json:"completed"`
+	} `json:"items"`
+}
+
+func (*codexFormat) detect(obj map[string]json.RawMessage) bool {
+	t := jsonString(obj, "type")
+	return strings.HasPrefix(t, "thread.") || strings.HasPrefix(t, "turn.") || strings.HasPrefix(t, "item.")
+}
+
+func (f *codexFormat) convert(data []byte) ([]parser.StreamMessage, error) {
+	var ev codexEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, err
+	}
+
+	switch ev.Type {
+	case "thread.started":
+		return []parser.StreamMessage{{Type: "system", Subtype: "init", SessionID: ev.ThreadID}}, nil
+	case "turn.completed":
+		msg := resultMessage(false, "")
+		msg.NumTurns = 1
+		if ev.Usage != nil {
+			// Codex counts cached tokens as input; Claude counts them apart
+			msg.Usage = &parser.Usage{
+				InputTokens:          ev.Usage.InputTokens - ev.Usage.CachedInputTokens,
+				OutputTokens:         ev.Usage.OutputTokens,
+				CacheReadInputTokens: ev.Usage.CachedInputTokens,
+			}
+		}
+		return []parser.StreamMessage{msg}, nil
+	case "turn.failed":
+		text := "turn failed"
+		if ev.Error != nil {
+			text = ev.Error.Message
+		}
+		return []parser.StreamMessage{resultMessage(true, text)}, nil
+	case "error":
+		return []parser.StreamMessage{resultMessage(true, ev.Message)}, nil
+	case "item.started", "item.updated", "item.completed":
+		if ev.Item == nil {
+			return nil, nil
+		}
+		return f.convertItem(ev.Item, ev.Type == "item.completed"), nil
+	}
+	return nil, nil
+}
+
+// convertItem converts an item event. Tool calls are shown when their item
+// starts and their output when it completes.
+func (f *codexFormat) convertItem(item *codexItem, completed bool) []parser.StreamMessage {
+	var call *parser.StreamMessage
+	switch item.Type {
+	case "agent_message":
+		if completed {
+			return []parser.StreamMessage{textMessage("assistant", "text", item.Text)}
+		}
+		return nil
+	case "reasoning":
+		if completed {
+			return []parser.StreamMessage{textMessage("assistant", "thinking", item.Text)}
+		}
+		return nil
+	case "error":
+		if completed {
+			return []parser.StreamMessage{resultMessage(true, item.Message)}
+		}
+		return nil
+	case "command_execution":
+		msg := toolUseMessage(item.ID, "Bash", map[string]interface{}{"command": item.Command})
+		call = &msg
+	case "file_change":
+		var changes []interface{}
+		for _, c := range item.Changes {
+			changes = append(changes, c.Kind+" "+c.Path)
+		}
+		msg := toolUseMessage(item.ID, "apply_patch", map[string]interface{}{"changes": changes})
+		call = &msg
+	case "mcp_tool_call":
+		input, ok := item.Arguments.(map[string]interface{})
+		if !ok && item.Arguments != nil {
+			input = map[string]interface{}{"arguments": item.Arguments}
+		}
+		msg := toolUseMessage(item.ID, "mcp__"+item.Server+"__"+item.Tool, input)
+		call = &msg
+	case "web_search":
+		msg := toolUseMessage(item.ID, "WebSearch", map[string]interface{}{"query": item.Query})
+		call = &msg
+	case "todo_list":
+		// Shown like Claude's TodoWrite, whose todos the renderer lists
+		var todos []interface{}
+		for _, todo := range item.Items {
+			status := "pending"
+			if todo.Completed {
+				status = "completed"
+			}
+			todos = append(todos, map[string]interface{}{"content": todo.Text, "status": status})
+		}
+		msg := toolUseMessage(item.ID, "TodoWrite", map[string]interface{}{"todos": todos})
+		call = &msg
+	default:
+		return nil
+	}
+
+	var out []parser.StreamMessage
+	// Todo lists are shown again as they change, like TodoWrite calls
+	if !f.started[item.ID] || (item.Type == "todo_list" && !completed) {
+		f.started[item.ID] = true
+		out = append(out, *call)
+	}
+	if !completed {
+		return out
+	}
+
+	switch item.Type {
+	case "command_execution":
+		failed := item.Status == "failed" || (item.ExitCode != nil && *item.ExitCode != 0)
+		out = append(out, toolResultMessage(item.ID, item.AggregatedOutput, failed))
+	case "file_change":
+		out = append(out, toolResultMessage(item.ID, item.Status, item.Status == "failed"))
+	case "mcp_tool_call":
+		if item.Error != nil {
+			out = append(out, toolResultMessage(item.ID, item.Error.Message, true))
+		} else {
+			out = append(out, toolResultMessage(item.ID, item.Result, item.Status == "failed"))
+		}
+	}
+	return out
+}
+
+func (*codexFormat) flush() []parser.StreamMessage { return nil }
+
+// geminiFormat reads Gemini CLI's --output-format stream-json events, and
+// the single object of --output-format json. Streamed assistant text
+// arrives in deltas, which are joined into one message.
+type geminiFormat struct {
+	pending strings.Builder // Assistant text deltas not yet shown
+}
+
+type geminiEvent struct {
+	Type       string                 `json:"type"`
+	SessionID  string                 `json:"session_id"`
+	Model      string                 `json:"model"`
+	Role       string                 `json:"role"`
+	Content    string                 `json:"content"`
+	Delta      bool                   `json:"delta"`
+	ToolName   string                 `json:"tool_name"`
+	ToolID     string                 `json:"tool_id"`
+	Parameters map[string]interface{} `json:"parameters"`
+	Status     string                 `json:"status"`
+	Output     string                 `json:"output"`
+	Message    string                 `json:"message"`
+	Error      *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	Stats    *geminiStats `json:"stats"`
+	Response *string      `json:"response"`
+}
+
+type geminiStats struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	Cached       int `json:"cached"`
+	DurationMS   int `json:"duration_ms"`
+	// Per-model totals from --output-format json
+	Models map[string]struct {
+		Tokens struct {
+			Prompt     int `json:"prompt"`
+			Candidates int `json:"candidates"`
+			Cached     int `json:"cached"`
+		} `json:"tokens"`
+	} `json:"models"`
+}
+
+// geminiTypes are the event types Gemini CLI streams.
+var geminiTypes = map[string]bool{"init": true, "message": true, "tool_use": true, "tool_result": true, "error": true, "result": true}
+
+func (*geminiFormat) detect(obj map[string]json.RawMessage) bool {
+	if hasKey(obj, "response") && hasKey(obj, "stats") {
+		return true
+	}
+	switch t := jsonString(obj, "type"); {
+	case t == "init" || t == "tool_use" || t == "tool_result":
+		return hasKey(obj, "session_id") || hasKey(obj, "tool_id")
+	case t == "message":
+		return hasKey(obj, "role") && !hasKey(obj, "message")
+	case geminiTypes[t]:
+		return hasKey(obj, "stats") || hasKey(obj, "severity")
+	}
+	return false
+}
+
+func (f *geminiFormat) convert(data []byte) ([]parser.StreamMessage, error) {
+	var ev geminiEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, err
+	}
+
+	if ev.Type == "message" && ev.Role == "assistant" && ev.Delta {
+		f.pending.WriteString(ev.Content)
+		return nil, nil
+	}
+	out := f.flush()
+
+	switch {
+	case ev.Response != nil:
+		out = append(out, textMessage("assistant", "text", *ev.Response))
+		msg := resultMessage(false, "")
+		msg.Usage = ev.Stats.usage()
+		out = append(out, msg)
+	case ev.Type == "init":
+		out = append(out, parser.StreamMessage{Type: "system", Subtype: "init", SessionID: ev.SessionID, Model: ev.Model})
+	case ev.Type == "message":
+		role := "user"
+		if ev.Role == "assistant" {
+			role = "assistant"
+		}
+		out = append(out, textMessage(role, "text", ev.Content))
+	case ev.Type == "tool_use":
+		out = append(out, toolUseMessage(ev.ToolID, ev.ToolName, ev.Parameters))
+	case ev.Type == "tool_result":
+		output := ev.Output
+		if ev.Error != nil && output == "" {
+			output = ev.Error.Message
+		}
+		out = append(out, toolResultMessage(ev.ToolID, output, ev.Status == "error"))
+	case ev.Type == "error":
+		out = append(out, resultMessage(true, ev.Message))
+	case ev.Type == "result":
+		text := ""
+		if ev.Error != nil {
+			text = ev.Error.Message
+		}
+		msg := resultMessage(ev.Status != "" && ev.Status != "success", text)
+		msg.Usage = ev.Stats.usage()
+		if ev.Stats != nil {
+			msg.DurationMS = ev.Stats.DurationMS
+		}
+		out = append(out, msg)
+	}
+	return out, nil
+}
+
+func (f *geminiFormat) flush() []parser.StreamMessage {
+	if f.pending.Len() == 0 {
+		return nil
+	}
+	msg := textMessage("assistant", "text", f.pending.String())
+	f.pending.Reset()
+	return []parser.StreamMessage{msg}
+}
+
+// usage returns the token totals, with cached tokens counted apart from
+// input as Claude does.
+func (s *geminiStats) usage() *parser.Usage {
+	if s == nil {
+		return nil
+	}
+	u := &parser.Usage{InputTokens: s.InputTokens - s.Cached, OutputTokens: s.OutputTokens, CacheReadInputTokens: s.Cached}
+	for _, m := range s.Models {
+		u.InputTokens += m.Tokens.Prompt - m.Tokens.Cached
+		u.OutputTokens += m.Tokens.Candidates
+		u.CacheReadInputTokens += m.Tokens.Cached
+	}
+	return u
+}
+
+// aiderFormat reads aider's --analytics-log. aider doesn't log message text
+// as JSON, so this shows the model, slash commands, and per-message tokens
+// and cost.
+type aiderFormat struct{}
+
+type aiderEvent struct {
+	Event      string `json:"event"`
+	Properties struct {
+		MainModel        string  `json:"main_model"`
+		PromptTokens     int     `json:"prompt_tokens"`
+		CompletionTokens int     `json:"completion_tokens"`
+		Cost             float64 `json:"cost"`
+	} `json:"properties"`
+}
+
+func (aiderFormat) detect(obj map[string]json.RawMessage) bool {
+	return jsonString(obj, "event") != "" && hasKey(obj, "properties")
+}
+
+func (aiderFormat) convert(data []byte) ([]parser.StreamMessage, error) {
+	var ev aiderEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case ev.Event == "cli session":
+		return []parser.StreamMessage{{Type: "system", Subtype: "init", Model: ev.Properties.MainModel}}, nil
+	case ev.Event == "message_send":
+		msg := resultMessage(false, "")
+		msg.NumTurns = 1
+		msg.TotalCostUSD = ev.Properties.Cost
+		msg.Usage = &parser.Usage{InputTokens: ev.Properties.PromptTokens, OutputTokens: ev.Properties.CompletionTokens}
+		return []parser.StreamMessage{msg}, nil
+	case strings.HasPrefix(ev.Event, "command_"):
+		return []parser.StreamMessage{toolUseMessage("", "/"+strings.TrimPrefix(ev.Event, "command_"), nil)}, nil
+	}
+	return nil, nil
+}
+
+func (aiderFormat) flush() []parser.StreamMessage { return nil }
+
+// openaiFormat reads OpenAI-style chat JSON: single messages (one per line,
+// or the elements of a messages array), requests with a "messages" array,
+// and chat completion responses with "choices".
+type openaiFormat struct{}
+
+type openaiMessage struct {
+	Role         string           `json:"role"`
+	Content      interface{}      `json:"content"`
+	ToolCallID   string           `json:"tool_call_id"`
+	ToolCalls    []openaiToolCall `json:"tool_calls"`
+	FunctionCall *openaiFunction  `json:"function_call"` // Before tool_calls
+	Name         string           `json:"name"`
+}
+
+type openaiToolCall struct {
+	ID       string         `json:"id"`
+	Function openaiFunction `json:"function"`
+}
+
+type openaiFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type openaiChat struct {
+	ID       string          `json:"id"`
+	Model    string          `json:"model"`
+	Messages []openaiMessage `json:"messages"`
+	Choices  []struct {
+		Message openaiMessage `json:"message"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens        int `json:"prompt_tokens"`
+		CompletionTokens    int `json:"completion_tokens"`
+		PromptTokensDetails *struct {
+			CachedTokens int `json:"cached_tokens"`
+		} `json:"prompt_tokens_details"`
+	} `json:"usage"`
+}
+
+func (openaiFormat) detect(obj map[string]json.RawMessage) bool {
+	if hasKey(obj, "messages") || hasKey(obj, "choices") {
+		return true
+	}
+	return jsonString(obj, "role") != "" && !hasKey(obj, "type")
+}
+
+func (openaiFormat) convert(data []byte) ([]parser.StreamMessage, error) {
+	var chat openaiChat
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return nil, err
+	}
+
+	var out []parser.StreamMessage
+	switch {
+	case chat.Messages != nil:
+		for _, m := range chat.Messages {
+			out = append(out, m.convert()...)
+		}
+	case chat.Choices != nil:
+		for _, choice := range chat.Choices {
+			msgs := choice.Message.convert()
+			for i := range msgs {
+				if msgs[i].Type == "assistant" {
+					msgs[i].Message.ID, msgs[i].Message.Model = chat.ID, chat.Model
+					if chat.Usage != nil {
+						cached := 0
+						if chat.Usage.PromptTokensDetails != nil {
+							cached = chat.Usage.PromptTokensDetails.CachedTokens
+						}
+						msgs[i].Message.Usage = &parser.Usage{
+							InputTokens:          chat.Usage.PromptTokens - cached,
+							OutputTokens:         chat.Usage.CompletionTokens,
+							CacheReadInputTokens: cached,
+						}
+					}
+				}
+			}
+			out = append(out, msgs...)
+		}
+	default:
+		var m openaiMessage
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		out = m.convert()
+	}
+	return out, nil
+}
+
+func (openaiFormat) flush() []parser.StreamMessage { return nil }
+
+// convert maps one chat message onto stream messages.
+func (m openaiMessage) convert() []parser.StreamMessage {
+	text := openaiText(m.Content)
+	switch m.Role {
+	case "system", "developer":
+		msg := textMessage("system", "text", text)
+		msg.Subtype = "prompt"
+		return []parser.StreamMessage{msg}
+	case "tool", "function":
+		id := m.ToolCallID
+		if id == "" {
+			id = m.Name
+		}
+		return []parser.StreamMessage{toolResultMessage(id, text, false)}
+	case "assistant":
+		msg := textMessage("assistant", "text", text)
+		if text == "" {
+			msg.Message.Content = nil
+		}
+		calls := m.ToolCalls
+		if m.FunctionCall != nil {
+			calls = append(calls, openaiToolCall{ID: m.FunctionCall.Name, Function: *m.FunctionCall})
+		}
+		for _, call := range calls {
+			var input map[string]interface{}
+			if json.Unmarshal([]byte(call.Function.Arguments), &input) != nil && call.Function.Arguments != "" {
+				input = map[string]interface{}{"arguments": call.Function.Arguments}
+			}
+			msg.Message.Content = append(msg.Message.Content, parser.ContentBlock{
+				Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input,
+			})
+		}
+		return []parser.StreamMessage{msg}
+	default:
+		return []parser.StreamMessage{textMessage("user", "text", text)}
+	}
+}
+
+// openaiText returns the text of a content string or array of parts.
+func openaiText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var parts []string
+		for _, part := range c {
+			if p, ok := part.(map[string]interface{}); ok {
+				if text, ok := p["text"].(string); ok {
+					parts = append(parts, text)
+				}
+			}
+		}
+		return strings.Join(parts, "\n")
+	}
+	return ""
+}
+
+// textMessage returns a message of type typ with one block of text.
+func textMessage(typ, blockType, text string) parser.StreamMessage {
+	return parser.StreamMessage{
+		Type: typ,
+		Message: &parser.MessageContent{
+			Role:    typ,
+			Content: []parser.ContentBlock{{Type: blockType, Text: text}},
+		},
+	}
+}
+
+// toolUseMessage returns an assistant message calling a tool.
+func toolUseMessage(id, name string, input map[string]interface{}) parser.StreamMessage {
+	return parser.StreamMessage{
+		Type: "assistant",
+		Message: &parser.MessageContent{
+			Role:    "assistant",
+			Content: []parser.ContentBlock{{Type: "tool_use", ID: id, Name: name, Input: input}},
+		},
+	}
+}
+
+// toolResultMessage returns a user message carrying a tool's output.
+func toolResultMessage(id string, content interface{}, isError bool) parser.StreamMessage {
+	return parser.StreamMessage{
+		Type: "user",
+		Message: &parser.MessageContent{
+			Role:    "user",
+			Content: []parser.ContentBlock{{Type: "tool_result", ToolUseID: id, Content: content, IsError: isError}},
+		},
+	}
+}
+
+// resultMessage returns a result message with the given outcome.
+func resultMessage(isError bool, text string) parser.StreamMessage {
+	subtype := "success"
+	if isError {
+		subtype = "error"
+	}
+	return parser.StreamMessage{Type: "result", Subtype: subtype, IsError: isError, Result: text}
+}
+
+// hasKey reports whether obj has key.
+func hasKey(obj map[string]json.RawMessage, key string) bool {
+	_, ok := obj[key]
+	return ok
+}
+
+// jsonString returns obj[key] if it is a string, or "".
+func jsonString(obj map[string]json.RawMessage, key string) string {
+	var s string
+	if raw, ok := obj[key]; ok {
+		json.Unmarshal(raw, &s)
+	}
+	return s
+}
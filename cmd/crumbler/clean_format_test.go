@@ -0,0 +1,265 @@
+package crumbler
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+func TestTranscriptFormats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   []string
+	}{
+		{
+			name:  "claude",
+			input: sessionLog,
+			want: []string{
+				"system/init",
+				"assistant text=Looking in=100 out=20 cache=1000",
+				"assistant tool_use=Bash tool_use=Read in=100 out=20 cache=1000",
+				"user tool_result=t1!error tool_result=t2",
+				"assistant tool_use=Bash in=10 out=5 cache=200",
+				"result/success turns=3",
+			},
+		},
+		{
+			name: "codex",
+			input: `{"type":"thread.started","thread_id":"th_1"}
+{"type":"turn.started"}
+{"type":"item.completed","item":{"id":"item_0","type":"reasoning","text":"Planning"}}
+{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"","status":"in_progress"}}
+{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","aggregated_output":"README.md\n","exit_code":0,"status":"completed"}}
+{"type":"item.completed","item":{"id":"item_2","type":"command_execution","command":"false","aggregated_output":"","exit_code":1,"status":"failed"}}
+{"type":"item.completed","item":{"id":"item_3","type":"file_change","changes":[{"path":"main.go","kind":"update"}],"status":"completed"}}
+{"type":"item.started","item":{"id":"item_4","type":"todo_list","items":[{"text":"Fix","completed":false}]}}
+{"type":"item.completed","item":{"id":"item_4","type":"todo_list","items":[{"text":"Fix","completed":true}]}}
+{"type":"item.completed","item":{"id":"item_5","type":"agent_message","text":"Done"}}
+{"type":"turn.completed","usage":{"input_tokens":1200,"cached_input_tokens":1000,"output_tokens":50}}`,
+			want: []string{
+				"system/init",
+				"assistant thinking=Planning",
+				"assistant tool_use=Bash",
+				"user tool_result=item_1",
+				"assistant tool_use=Bash",
+				"user tool_result=item_2!error",
+				"assistant tool_use=apply_patch",
+				"user tool_result=item_3",
+				"assistant tool_use=TodoWrite",
+				"assistant text=Done",
+				"result/success turns=1 in=200 out=50 cache=1000",
+			},
+		},
+		{
+			name: "codex failure",
+			input: `{"type":"thread.started","thread_id":"th_1"}
+{"type":"turn.failed","error":{"message":"quota exceeded"}}`,
+			want: []string{"system/init", "result/error!error quota exceeded"},
+		},
+		{
+			name: "gemini stream",
+			input: `{"type":"init","timestamp":"2026-01-02T10:00:00Z","session_id":"g1","model":"gemini-2.5-pro"}
+{"type":"message","role":"user","content":"List files"}
+{"type":"message","role":"assistant","content":"Let me ","delta":true}
+{"type":"message","role":"assistant","content":"look.","delta":true}
+{"type":"tool_use","tool_name":"run_shell_command","tool_id":"c1","parameters":{"command":"ls"}}
+{"type":"tool_result","tool_id":"c1","status":"error","error":{"message":"denied"}}
+{"type":"message","role":"assistant","content":"Denied.","delta":true}
+{"type":"result","status":"success","stats":{"input_tokens":30,"output_tokens":7,"duration_ms":900}}`,
+			want: []string{
+				"system/init",
+				"user text=List files",
+				"assistant text=Let me look.",
+				"assistant tool_use=run_shell_command",
+				"user tool_result=c1!error",
+				"assistant text=Denied.",
+				"result/success in=30 out=7",
+			},
+		},
+		{
+			name:  "gemini json",
+			input: `{"response":"All done","stats":{"models":{"gemini-2.5-pro":{"tokens":{"prompt":100,"candidates":20,"cached":40}}}}}`,
+			want:  []string{"assistant text=All done", "result/success in=60 out=20 cache=40"},
+		},
+		{
+			name:  "gemini trailing delta",
+			input: `{"type":"init","session_id":"g1"}{"type":"message","role":"assistant","content":"cut off","delta":true}`,
+			want:  []string{"system/init", "assistant text=cut off"},
+		},
+		{
+			name: "aider",
+			input: `{"event":"launched","properties":{},"user_id":"u","time":1700000000}
+{"event":"cli session","properties":{"main_model":"gpt-4o"},"user_id":"u","time":1700000001}
+{"event":"command_add","properties":{},"user_id":"u","time":1700000002}
+{"event":"message_send","properties":{"main_model":"gpt-4o","prompt_tokens":900,"completion_tokens":80,"cost":0.01},"user_id":"u","time":1700000003}`,
+			want: []string{"system/init", "assistant tool_use=/add", "result/success turns=1 in=900 out=80"},
+		},
+		{
+			name: "openai messages",
+			input: `{"messages":[{"role":"system","content":"Be brief"},{"role":"user","content":[{"type":"text","text":"Weather?"}]},
+{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Oslo\"}"}}]},
+{"role":"tool","tool_call_id":"call_1","content":"Rain"},{"role":"assistant","content":"It rains."}]}`,
+			want: []string{
+				"system/prompt text=Be brief",
+				"user text=Weather?",
+				"assistant tool_use=get_weather",
+				"user tool_result=call_1",
+				"assistant text=It rains.",
+			},
+		},
+		{
+			name: "openai lines",
+			input: `{"role":"user","content":"Hi"}
+{"role":"assistant","content":"Hello"}`,
+			want: []string{"user text=Hi", "assistant text=Hello"},
+		},
+		{
+			name:  "openai completion",
+			input: `{"id":"chatcmpl-1","object":"chat.completion","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"Sure"}}],"usage":{"prompt_tokens":12,"completion_tokens":3,"prompt_tokens_details":{"cached_tokens":2}}}`,
+			want:  []string{"assistant text=Sure in=10 out=3 cache=2"},
+		},
+		{
+			name:   "forced format",
+			format: "openai",
+			input:  `{"role":"user","content":"Hi","type":"note"}`,
+			want:   []string{"user text=Hi"},
+		},
+		{
+			name:  "unrecognized objects read as claude",
+			input: `{"type":"test1"}{"key":"value"}`,
+			want:  []string{"test1", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reader, err := newTranscriptReader(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, obj := range splitObjects(t, tt.input) {
+				msgs, err := reader.read(obj)
+				if err != nil {
+					t.Fatalf("read(%s) error: %v", obj, err)
+				}
+				for i := range msgs {
+					got = append(got, describeMessage(&msgs[i]))
+				}
+			}
+			for _, msg := range reader.flush() {
+				got = append(got, describeMessage(&msg))
+			}
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("messages:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestTranscriptFormatSummary(t *testing.T) {
+	t.Parallel()
+
+	// Formats without per-message usage are totalled from their results
+	input := `{"type":"thread.started","thread_id":"th_1"}
+{"type":"item.completed","item":{"id":"i1","type":"command_execution","command":"ls","aggregated_output":"","exit_code":2,"status":"failed"}}
+{"type":"turn.completed","usage":{"input_tokens":100,"cached_input_tokens":0,"output_tokens":10}}
+{"type":"turn.completed","usage":{"input_tokens":50,"cached_input_tokens":20,"output_tokens":5}}`
+
+	summary := newSessionSummary()
+	opts := &cleanOptions{summary: summary, summaryJSON: true}
+	if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	summary.finish()
+	if summary.Turns != 2 || summary.InputTokens != 130 || summary.OutputTokens != 15 || summary.CacheReadTokens != 20 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if summary.ToolCalls != 1 || summary.ToolErrors != 1 {
+		t.Errorf("tool calls = %d, errors = %d; want 1, 1", summary.ToolCalls, summary.ToolErrors)
+	}
+}
+
+func TestNewTranscriptReader(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "auto", "claude", "codex", "gemini", "aider", "openai"} {
+		if _, err := newTranscriptReader(name); err != nil {
+			t.Errorf("newTranscriptReader(%q) error: %v", name, err)
+		}
+	}
+	if _, err := newTranscriptReader("cursor"); err == nil {
+		t.Error("newTranscriptReader() should reject unknown formats")
+	}
+}
+
+// splitObjects splits input into its JSON objects the way clean does.
+func splitObjects(t *testing.T, input string) [][]byte {
+	t.Helper()
+	var objs [][]byte
+	dec := newStreamDecoder(strings.NewReader(input), nil)
+	for {
+		rec, err := dec.next()
+		if err == io.EOF {
+			return objs
+		}
+		if err != nil || rec.value == nil {
+			t.Fatalf("invalid test JSON: %q (%v)", rec.text, err)
+		}
+		objs = append(objs, rec.value)
+	}
+}
+
+// describeMessage renders a message's shape for comparison in tests.
+func describeMessage(msg *parser.StreamMessage) string {
+	out := msg.Type
+	if msg.Subtype != "" {
+		out += "/" + msg.Subtype
+	}
+	if msg.IsError {
+		out += "!error"
+	}
+	if msg.Message != nil {
+		for _, block := range msg.Message.Content {
+			switch block.Type {
+			case "text", "thinking":
+				out += fmt.Sprintf(" %s=%s", block.Type, block.Text)
+			case "tool_use":
+				out += " tool_use=" + block.Name
+			case "tool_result":
+				out += " tool_result=" + block.ToolUseID
+				if block.IsError {
+					out += "!error"
+				}
+			}
+		}
+	}
+	if msg.NumTurns > 0 {
+		out += fmt.Sprintf(" turns=%d", msg.NumTurns)
+	}
+	if msg.Result != "" {
+		out += " " + msg.Result
+	}
+	usage := msg.Usage
+	if msg.Message != nil && msg.Message.Usage != nil {
+		usage = msg.Message.Usage
+	}
+	if usage != nil {
+		out += fmt.Sprintf(" in=%d out=%d", usage.InputTokens, usage.OutputTokens)
+		if usage.CacheReadInputTokens > 0 {
+			out += fmt.Sprintf(" cache=%d", usage.CacheReadInputTokens)
+		}
+	}
+	return out
+}
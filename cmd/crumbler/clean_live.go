@@ -0,0 +1,248 @@
+package crumbler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/ariel-frischer/claude-clean/parser"
+	"golang.org/x/term"
+)
+
+const (
+	liveInterval = time.Second // How often the status line is redrawn
+	// clearLine returns to the start of the line and clears it.
+	clearLine = "\r\033[2K"
+)
+
+// liveStatus keeps a status line pinned below the transcript for --live.
+// The line is cleared while a message is printed and redrawn after it, and
+// redrawn every second so the elapsed time and crumb stay current.
+type liveStatus struct {
+	w     io.Writer
+	now   func() time.Time
+	width func() int    // Terminal width, or 0 if unknown
+	crumb func() string // Current crumb path, or "" outside a project
+
+	mu       sync.Mutex
+	start    time.Time
+	state    string            // Starting, Thinking, Done or Failed when no tool runs
+	pending  map[string]string // Running tool calls: description by tool_use ID
+	order    []string          // Running tool_use IDs, oldest first
+	usage    map[string]int    // Latest tokens per assistant message ID
+	tokens   int
+	cost     float64
+	errors   int
+	seen     int
+	crumbNow string
+	stopped  chan struct{}
+	done     chan struct{}
+}
+
+// newLiveStatus returns a status line writing to w.
+func newLiveStatus(w io.Writer, width func() int, crumb func() string) *liveStatus {
+	return &liveStatus{
+		w:       w,
+		now:     time.Now,
+		width:   width,
+		crumb:   crumb,
+		start:   time.Now(),
+		state:   "Starting",
+		pending: make(map[string]string),
+		usage:   make(map[string]int),
+	}
+}
+
+// run draws the status line and redraws it every interval until stop.
+func (l *liveStatus) run(interval time.Duration) {
+	l.stopped, l.done = make(chan struct{}), make(chan struct{})
+	l.mu.Lock()
+	l.refreshCrumb()
+	l.draw()
+	l.mu.Unlock()
+
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stopped:
+				return
+			case <-ticker.C:
+				l.mu.Lock()
+				l.refreshCrumb()
+				l.draw()
+				l.mu.Unlock()
+			}
+		}
+	}()
+}
+
+// stop stops redrawing and clears the status line, leaving the cursor
+// where the next output goes.
+func (l *liveStatus) stop() {
+	if l.stopped != nil {
+		close(l.stopped)
+		<-l.done
+		l.stopped = nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprint(l.w, clearLine)
+}
+
+// pause clears the status line so output can be printed; resume redraws
+// it below. The line isn't redrawn in between.
+func (l *liveStatus) pause() {
+	l.mu.Lock()
+	fmt.Fprint(l.w, clearLine)
+}
+
+// resume redraws the status line after output printed since pause.
+func (l *liveStatus) resume() {
+	l.draw()
+	l.mu.Unlock()
+}
+
+// add updates the totals from one message.
+func (l *liveStatus) add(msg *parser.StreamMessage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch msg.Type {
+	case "assistant":
+		if msg.Message == nil {
+			return
+		}
+		if len(l.order) == 0 {
+			l.state = "Thinking"
+		}
+		if u := msg.Message.Usage; u != nil {
+			// Streamed messages repeat their ID with growing usage
+			l.seen++
+			id := msg.Message.ID
+			if id == "" {
+				id = fmt.Sprintf("#%d", l.seen)
+			}
+			n := u.InputTokens + u.OutputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+			l.tokens += n - l.usage[id]
+			l.usage[id] = n
+		}
+		for _, block := range msg.Message.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+			if _, running := l.pending[block.ID]; !running {
+				l.order = append(l.order, block.ID)
+			}
+			l.pending[block.ID] = toolSummary(&docEntry{tool: block.Name, input: block.Input})
+		}
+	case "user":
+		if msg.Message == nil {
+			return
+		}
+		for _, block := range msg.Message.Content {
+			if block.Type != "tool_result" {
+				continue
+			}
+			if block.IsError {
+				l.errors++
+			}
+			if _, running := l.pending[block.ToolUseID]; running {
+				delete(l.pending, block.ToolUseID)
+				for i, id := range l.order {
+					if id == block.ToolUseID {
+						l.order = append(l.order[:i], l.order[i+1:]...)
+						break
+					}
+				}
+			}
+		}
+		if len(l.order) == 0 {
+			l.state = "Thinking"
+		}
+	case "result":
+		l.cost += msg.TotalCostUSD
+		l.pending, l.order = make(map[string]string), nil
+		l.state = "Done"
+		if msg.IsError {
+			l.state = "Failed"
+		}
+	}
+}
+
+// refreshCrumb looks up the current crumb.
+func (l *liveStatus) refreshCrumb() {
+	if l.crumb != nil {
+		l.crumbNow = l.crumb()
+	}
+}
+
+// draw writes the status line without a newline.
+func (l *liveStatus) draw() {
+	fmt.Fprint(l.w, clearLine+display.Gray.Sprint(l.line()))
+}
+
+// line returns the status line, cut to the terminal width.
+func (l *liveStatus) line() string {
+	activity := l.state
+	if n := len(l.order); n > 0 {
+		activity = l.pending[l.order[n-1]]
+		if n > 1 {
+			activity += fmt.Sprintf(" (+%d)", n-1)
+		}
+	}
+	parts := []string{
+		"● " + formatElapsed(l.now().Sub(l.start)),
+		activity,
+		formatTokens(l.tokens) + " tokens",
+	}
+	if l.cost > 0 {
+		parts[2] += fmt.Sprintf(" · $%.4f", l.cost)
+	}
+	parts = append(parts, plural(l.errors, "error"))
+	if l.crumbNow != "" {
+		parts = append(parts, l.crumbNow)
+	}
+	line := strings.Join(parts, " │ ")
+
+	// Writing the last column would wrap on some terminals
+	if width := l.width(); width > 1 && utf8.RuneCountInString(line) > width-1 {
+		runes := []rune(line)
+		line = string(runes[:width-2]) + "…"
+	}
+	return line
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 0.
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// liveCrumb returns a function looking up the current crumb for the status
+// line, or nil outside a crumbler project.
+func liveCrumb() func() string {
+	root, err := getProjectRoot()
+	if err != nil {
+		return nil
+	}
+	if info, err := os.Stat(crumblerDir(root)); err != nil || !info.IsDir() {
+		return nil
+	}
+	return func() string {
+		if _, err := os.Stat(crumblerDir(root)); err != nil {
+			return "" // The project is done
+		}
+		return currentCrumbPath(root)
+	}
+}
@@ -0,0 +1,97 @@
+package crumbler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// testLiveStatus returns a status line 90s into a session, on a terminal
+// of the given width.
+func testLiveStatus(out *bytes.Buffer, width int) *liveStatus {
+	l := newLiveStatus(out, func() int { return width }, func() string { return ".crumbler/01-api" })
+	start := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	l.start, l.now = start, func() time.Time { return start.Add(90 * time.Second) }
+	l.refreshCrumb()
+	return l
+}
+
+func TestLiveStatusLine(t *testing.T) {
+	t.Parallel()
+
+	const running = `{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"Testing"}],"usage":{"input_tokens":1000,"output_tokens":10}}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./..."}}],"usage":{"input_tokens":1000,"output_tokens":200}}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"tool_use","id":"t2","name":"Read","input":{"file_path":"/repo/a.go"}}],"usage":{"input_tokens":300,"output_tokens":0}}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t2","content":"no such file","is_error":true}]}}
+`
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{
+			name:  "starting",
+			width: 200,
+			want:  "● 1m30s │ Starting │ 0 tokens │ 0 errors │ .crumbler/01-api",
+		},
+		{
+			name:  "tool running",
+			input: running,
+			width: 200,
+			want:  "● 1m30s │ Bash: go test ./... │ 1.5k tokens │ 1 error │ .crumbler/01-api",
+		},
+		{
+			name: "done",
+			input: running + `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"ok"}]}}
+{"type":"result","subtype":"success","total_cost_usd":0.125}`,
+			width: 200,
+			want:  "● 1m30s │ Done │ 1.5k tokens · $0.1250 │ 1 error │ .crumbler/01-api",
+		},
+		{
+			name:  "narrow terminal",
+			input: running,
+			width: 30,
+			want:  "● 1m30s │ Bash: go test ./..…",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			l := testLiveStatus(&bytes.Buffer{}, tt.width)
+			opts := &cleanOptions{live: l, summaryJSON: true}
+			if err := processJSONStream(strings.NewReader(tt.input), &display.Config{}, opts); err != nil {
+				t.Fatal(err)
+			}
+			if got := l.line(); got != tt.want {
+				t.Errorf("line() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiveStatusRedraw(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	l := testLiveStatus(&out, 200)
+	l.run(time.Hour)
+	out.Reset()
+
+	// Output is printed on a cleared line, with the status line redrawn
+	// below it
+	l.pause()
+	out.WriteString("message\n")
+	l.resume()
+	l.stop()
+
+	got := strings.ReplaceAll(out.String(), clearLine, "|")
+	want := "|message\n|● 1m30s │ Starting │ 0 tokens │ 0 errors │ .crumbler/01-api|"
+	if got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,180 @@
+package crumbler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/ledger"
+)
+
+// costRecorder credits the usage in a stream to crumbs for --record.
+//
+// Each assistant message is credited to the crumb that is current when it
+// arrives: agents usually delete their crumb before the session ends, so
+// the crumb at the end isn't the one the work was for. Only result
+// messages carry dollars, so a result's cost is split over the crumbs
+// credited since the previous result, by their share of the tokens.
+//
+// Only a tool call, such as 'crumbler delete', can change the current
+// crumb, so it is looked up again only after a tool result.
+type costRecorder struct {
+	root    string
+	current func() string // Current crumb path, relative to the project root
+	crumb   string        // Cached current crumb
+	stale   bool          // crumb needs looking up again
+	now     func() time.Time
+	session string
+	pending map[string]*crumbUsage // Usage since the last result, by message ID
+	order   []string               // Message IDs in first-seen order
+	seen    int
+	err     error // First error writing the ledger
+}
+
+// crumbUsage is one assistant message's usage and the crumb it goes to.
+type crumbUsage struct {
+	crumb string
+	usage parser.Usage
+}
+
+// newCostRecorder returns a recorder for the project at root.
+func newCostRecorder(root string) *costRecorder {
+	return &costRecorder{
+		root:    root,
+		current: func() string { return currentCrumbPath(root) },
+		stale:   true,
+		now:     func() time.Time { return time.Now().UTC() },
+		pending: make(map[string]*crumbUsage),
+	}
+}
+
+// currentCrumbPath returns the path of the current crumb relative to the
+// project root, or the root crumb's path once the project is done.
+func currentCrumbPath(root string) string {
+	current, err := crumb.GetCurrent(root)
+	if err != nil || current == nil {
+		return relPath(root, crumblerDir(root))
+	}
+	return current.RelPath
+}
+
+// currentCrumb returns the current crumb, looking it up if it is stale.
+func (r *costRecorder) currentCrumb() string {
+	if r.stale {
+		r.crumb, r.stale = r.current(), false
+	}
+	return r.crumb
+}
+
+// add records one message.
+func (r *costRecorder) add(msg *parser.StreamMessage) {
+	switch msg.Type {
+	case "system":
+		if msg.SessionID != "" {
+			r.session = msg.SessionID
+		}
+	case "user":
+		r.stale = true // Tool results follow tool calls
+	case "assistant":
+		if msg.Message == nil || msg.Message.Usage == nil {
+			return
+		}
+		// Streamed messages repeat their ID; keep the latest usage
+		r.seen++
+		id := msg.Message.ID
+		if id == "" {
+			id = fmt.Sprintf("#%d", r.seen)
+		}
+		u, ok := r.pending[id]
+		if !ok {
+			u = &crumbUsage{crumb: r.currentCrumb()}
+			r.pending[id] = u
+			r.order = append(r.order, id)
+		}
+		u.usage = *msg.Message.Usage
+	case "result":
+		if msg.SessionID != "" {
+			r.session = msg.SessionID
+		}
+		r.flush(msg.TotalCostUSD, msg.Usage)
+	}
+}
+
+// finish writes what was credited after the last result.
+func (r *costRecorder) finish() error {
+	r.flush(0, nil)
+	if r.err != nil {
+		return fmt.Errorf("failed to record cost: %w", r.err)
+	}
+	return nil
+}
+
+// flush writes the pending usage to the ledger, splitting cost over it.
+// Formats without per-message usage report it in the result, which is
+// credited to the current crumb.
+func (r *costRecorder) flush(cost float64, usage *parser.Usage) {
+	var entries []ledger.Entry
+	index := make(map[string]int)
+	var weights []float64
+	for _, id := range r.order {
+		u := r.pending[id]
+		i, ok := index[u.crumb]
+		if !ok {
+			i = len(entries)
+			index[u.crumb] = i
+			entries = append(entries, ledger.Entry{Crumb: u.crumb})
+			weights = append(weights, 0)
+		}
+		addUsage(&entries[i], u.usage)
+		weights[i] += usageWeight(u.usage)
+	}
+	r.pending = make(map[string]*crumbUsage)
+	r.order = nil
+
+	if len(entries) == 0 {
+		if usage == nil && cost == 0 {
+			return
+		}
+		entries = append(entries, ledger.Entry{Crumb: r.currentCrumb()})
+		weights = append(weights, 0)
+		if usage != nil {
+			addUsage(&entries[0], *usage)
+		}
+	}
+
+	var total float64
+	for _, w := range weights {
+		total += w
+	}
+	now := r.now()
+	for i := range entries {
+		entries[i].Time, entries[i].Session = now, r.session
+		if total > 0 {
+			entries[i].CostUSD = cost * weights[i] / total
+		}
+	}
+	if total == 0 {
+		entries[len(entries)-1].CostUSD = cost
+	}
+
+	if err := ledger.Append(r.root, entries...); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// addUsage adds usage to a ledger entry.
+func addUsage(e *ledger.Entry, u parser.Usage) {
+	e.InputTokens += u.InputTokens
+	e.OutputTokens += u.OutputTokens
+	e.CacheReadTokens += u.CacheReadInputTokens
+	e.CacheCreationTokens += u.CacheCreationInputTokens
+}
+
+// usageWeight estimates the relative price of usage, using the ratios of
+// Claude's list prices: output costs 5x input, cache writes 1.25x and
+// cache reads 0.1x.
+func usageWeight(u parser.Usage) float64 {
+	return float64(u.InputTokens) + 5*float64(u.OutputTokens) +
+		1.25*float64(u.CacheCreationInputTokens) + 0.1*float64(u.CacheReadInputTokens)
+}
@@ -0,0 +1,160 @@
+package crumbler
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/ledger"
+)
+
+func TestCostRecorder(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	r := newCostRecorder(root)
+	// The agent finishes 01-a and deletes it mid-session
+	current, lookups := ".crumbler/01-a", 0
+	r.current = func() string {
+		lookups++
+		return current
+	}
+	r.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	input := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"id":"m1","content":[],"usage":{"input_tokens":100,"output_tokens":10}}}
+{"type":"assistant","message":{"id":"m1","content":[],"usage":{"input_tokens":100,"output_tokens":20}}}
+`
+	opts := &cleanOptions{summaryJSON: true, summary: newSessionSummary(), recorder: r}
+	if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	current = ".crumbler/02-b"
+	input = `{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"Deleted crumb: .crumbler/01-a"}]}}
+{"type":"assistant","message":{"id":"m2","content":[],"usage":{"input_tokens":200,"output_tokens":40,"cache_read_input_tokens":1000}}}
+{"type":"result","subtype":"success","session_id":"s1","total_cost_usd":0.6}
+{"type":"assistant","message":{"id":"m3","content":[],"usage":{"input_tokens":5}}}
+`
+	if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+	// Once at the start and once after the tool result
+	if lookups != 2 {
+		t.Errorf("looked up the current crumb %d times, want 2", lookups)
+	}
+
+	entries, err := ledger.Read(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ledger = %+v, want 3 entries", entries)
+	}
+	// Weights: 01-a 100+5*20 = 200, 02-b 200+5*40+0.1*1000 = 500
+	a, b, tail := entries[0], entries[1], entries[2]
+	if a.Crumb != ".crumbler/01-a" || a.InputTokens != 100 || a.OutputTokens != 20 || a.Session != "s1" || !near(a.CostUSD, 0.6*200/700) {
+		t.Errorf("first entry = %+v", a)
+	}
+	if b.Crumb != ".crumbler/02-b" || b.CacheReadTokens != 1000 || !near(b.CostUSD, 0.6*500/700) {
+		t.Errorf("second entry = %+v", b)
+	}
+	// Usage after the last result is still recorded, without cost
+	if tail.Crumb != ".crumbler/02-b" || tail.InputTokens != 5 || tail.CostUSD != 0 {
+		t.Errorf("last entry = %+v", tail)
+	}
+}
+
+func TestCostRecorderResultUsage(t *testing.T) {
+	t.Parallel()
+
+	// Formats without per-message usage credit the result to the current crumb
+	root := t.TempDir()
+	r := newCostRecorder(root)
+	r.current = func() string { return ".crumbler/01-a" }
+	opts := &cleanOptions{recorder: r, summaryJSON: true}
+	input := `{"type":"result","subtype":"success","total_cost_usd":0.5,"usage":{"input_tokens":7,"output_tokens":3}}`
+	if err := processJSONStream(strings.NewReader(input), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.finish(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := ledger.Read(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Crumb != ".crumbler/01-a" || entries[0].InputTokens != 7 || entries[0].CostUSD != 0.5 {
+		t.Errorf("ledger = %+v", entries)
+	}
+}
+
+func TestCostReport(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	for _, dir := range []string{"", "01-setup", "01-setup/02-api", "02-features"} {
+		path := filepath.Join(root, crumb.CrumblerDir, dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, crumb.ReadmeFile), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ledger.Append(root,
+		ledger.Entry{Crumb: ".crumbler/01-setup/01-database", InputTokens: 1000, CostUSD: 1},
+		ledger.Entry{Crumb: ".crumbler/01-setup/01-database/01-schema", InputTokens: 500, CostUSD: 0.5},
+		ledger.Entry{Crumb: ".crumbler/02-features", InputTokens: 10, CostUSD: 0.25},
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := loadCostReport(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := report.total(); total.Tokens() != 1510 || total.CostUSD != 1.75 {
+		t.Errorf("total() = %+v", total)
+	}
+	// Deleted crumbs are listed at the top of each deleted branch
+	if got := report.deleted(); len(got) != 1 || got[0] != ".crumbler/01-setup/01-database" {
+		t.Errorf("deleted() = %q", got)
+	}
+	if got := report.totals[".crumbler/01-setup"]; got == nil || got.CostUSD != 1.5 {
+		t.Errorf("01-setup totals = %+v, want deleted descendants included", got)
+	}
+
+	out := report.json()
+	if len(out.Crumbs) != 5 {
+		t.Errorf("json() crumbs = %+v", out.Crumbs)
+	}
+	for _, c := range out.Crumbs {
+		if wantDeleted := strings.Contains(c.Crumb, "01-database"); c.Deleted != wantDeleted {
+			t.Errorf("%s deleted = %v, want %v", c.Crumb, c.Deleted, wantDeleted)
+		}
+	}
+}
+
+func TestFormatTokens(t *testing.T) {
+	t.Parallel()
+
+	for n, want := range map[int]string{0: "0", 950: "950", 12345: "12.3k", 1400000: "1.4M"} {
+		if got := formatTokens(n); got != want {
+			t.Errorf("formatTokens(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+// near reports whether two amounts are equal up to rounding.
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
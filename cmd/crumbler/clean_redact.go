@@ -0,0 +1,119 @@
+package crumbler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// redactWriter redacts secrets in the input copied to --tee. JSON strings
+// are redacted after decoding their escapes, and re-encoded only if a
+// secret was found, so everything else is copied byte for byte. Lines are
+// held until they end, so secrets split across reads are still found.
+type redactWriter struct {
+	w      io.Writer
+	redact func(string) string
+	line   []byte // Start of a line not yet written
+}
+
+// newRedactWriter returns a writer that redacts lines before writing them
+// to w.
+func newRedactWriter(w io.Writer, redact func(string) string) *redactWriter {
+	return &redactWriter{w: w, redact: redact}
+}
+
+// Write writes the complete lines in p and holds the rest.
+func (w *redactWriter) Write(p []byte) (int, error) {
+	w.line = append(w.line, p...)
+	end := bytes.LastIndexByte(w.line, '\n')
+	if end < 0 {
+		return len(p), nil
+	}
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(string(w.line[:end+1]), "\n") {
+		out.WriteString(redactLine(line, w.redact))
+	}
+	w.line = append(w.line[:0], w.line[end+1:]...)
+	if _, err := io.WriteString(w.w, out.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes a final line without a newline.
+func (w *redactWriter) Flush() error {
+	if len(w.line) == 0 {
+		return nil
+	}
+	line := redactLine(string(w.line), w.redact)
+	w.line = w.line[:0]
+	_, err := io.WriteString(w.w, line)
+	return err
+}
+
+// redactLine redacts one line of input, JSON or not. Quoted strings are
+// redacted on their own, so a secret can't take JSON syntax with it. The
+// values of structuralKeys are copied as they are.
+func redactLine(line string, redact func(string) string) string {
+	var out strings.Builder
+	prev := "" // The last quoted string, which may be a key
+	for line != "" {
+		start := strings.IndexByte(line, '"')
+		end := -1
+		if start >= 0 {
+			end = closingQuote(line, start+1)
+		}
+		if end < 0 {
+			out.WriteString(redact(line))
+			break
+		}
+		out.WriteString(redact(line[:start]))
+		quoted := line[start : end+1]
+		if structuralKeys[prev] && strings.TrimSpace(line[:start]) == ":" {
+			out.WriteString(quoted)
+		} else {
+			out.WriteString(redactQuoted(quoted, redact))
+		}
+		prev = ""
+		json.Unmarshal([]byte(quoted), &prev)
+		line = line[end+1:]
+	}
+	return out.String()
+}
+
+// closingQuote returns the index of the quote ending the string that starts
+// at i, or -1 if it doesn't end on the line.
+func closingQuote(line string, i int) int {
+	for ; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			return i
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// redactQuoted redacts a quoted string. A JSON string is decoded first;
+// anything else is redacted as it is.
+func redactQuoted(quoted string, redact func(string) string) string {
+	var s string
+	if err := json.Unmarshal([]byte(quoted), &s); err != nil {
+		return redact(quoted)
+	}
+	redacted := redact(s)
+	if redacted == s {
+		return quoted
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(redacted); err != nil {
+		return redact(quoted)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,659 @@
+package crumbler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ariel-frischer/claude-clean/display"
+	"github.com/ariel-frischer/claude-clean/parser"
+	"github.com/fatih/color"
+)
+
+const (
+	// defaultMaxOutputLines is how much of a tool's output is shown unless
+	// --max-output-lines says otherwise.
+	defaultMaxOutputLines = parser.FirstLines + parser.LastLines
+	maxInputChars         = 300 // Longest tool input value shown whole
+	compactChars          = 100 // Longest text shown on a compact line
+	minWrapWidth          = 20  // Narrowest column text is wrapped to
+)
+
+// messageRenderer draws a transcript for clean.
+type messageRenderer interface {
+	// render writes a message. It returns an error, having written
+	// nothing, if the message is malformed.
+	render(msg *parser.StreamMessage, lineNum int) error
+	// renderRaw writes JSON that couldn't be rendered as a message, and why.
+	renderRaw(data string, lineNum int, reason error)
+	// renderUsage writes a message's token usage, for --usage.
+	renderUsage(usage *parser.Usage)
+}
+
+// termRenderer renders messages in one of the display styles, for a
+// terminal or a log.
+type termRenderer struct {
+	w        io.Writer
+	cfg      *display.Config
+	width    func() int // Column to wrap at, or 0 not to wrap
+	maxLines int        // Tool output lines shown, or 0 for all
+	cols     int        // width() for the message being rendered
+}
+
+// newTermRenderer returns a renderer writing to w. width may be nil, for
+// output that isn't wrapped.
+func newTermRenderer(w io.Writer, cfg *display.Config, width func() int) *termRenderer {
+	return &termRenderer{w: w, cfg: cfg, width: width, maxLines: defaultMaxOutputLines}
+}
+
+// useColor reports whether clean colors its output for --color=mode. auto
+// colors a terminal, unless NO_COLOR is set or TERM is dumb.
+func useColor(mode string, terminal bool, getenv func(string) string) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	return terminal && getenv("NO_COLOR") == "" && getenv("TERM") != "dumb"
+}
+
+// checkMessage returns why a message can't be rendered, or nil.
+func checkMessage(msg *parser.StreamMessage) error {
+	switch msg.Type {
+	case "system", "result":
+		return nil
+	case "assistant", "user":
+		if msg.Message == nil {
+			return fmt.Errorf("%s message without content", msg.Type)
+		}
+		for _, block := range msg.Message.Content {
+			if block.Type == "tool_use" && block.Name == "" {
+				return fmt.Errorf("tool call without a name")
+			}
+		}
+		return nil
+	case "":
+		return fmt.Errorf("no message type")
+	}
+	return fmt.Errorf("unknown message type %q", msg.Type)
+}
+
+func (r *termRenderer) render(msg *parser.StreamMessage, lineNum int) error {
+	if err := checkMessage(msg); err != nil {
+		return err
+	}
+	r.measure()
+
+	// Written in one piece, so nothing is left half drawn
+	var b strings.Builder
+	if r.cfg.Style == display.StyleCompact {
+		r.compactMessage(&b, msg, lineNum)
+	} else {
+		r.message(&b, msg, lineNum)
+	}
+	io.WriteString(r.w, b.String())
+	return nil
+}
+
+func (r *termRenderer) renderRaw(data string, lineNum int, reason error) {
+	r.measure()
+	var b strings.Builder
+	if r.cfg.Style == display.StyleCompact {
+		info := display.FormatLineNumCompact(lineNum, r.cfg.ShowLineNum) + " (" + reason.Error() + ")"
+		used := 4 + utf8.RuneCountInString(info)
+		io.WriteString(r.w, r.paint(display.BoldRed, "RAW")+r.paint(display.Gray, info)+" "+r.fit(data, used, 2*compactChars)+"\n")
+		return
+	}
+
+	s := r.section(&b, "RAW JSON", display.BoldRed, display.Red, lineNum)
+	s.line(display.Gray, reason.Error())
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(data), "", "  "); err == nil {
+		data = indented.String()
+	}
+	s.output(nil, data, r.maxLines)
+	s.close()
+	io.WriteString(r.w, b.String())
+}
+
+func (r *termRenderer) renderUsage(usage *parser.Usage) {
+	r.measure()
+	prefix := "  "
+	if r.boxed() {
+		prefix = "│ "
+	}
+	io.WriteString(r.w, r.paint(display.Gray, prefix+usageText(usage))+"\n")
+}
+
+// measure reads the terminal width for the next message.
+func (r *termRenderer) measure() {
+	r.cols = 0
+	if r.width != nil {
+		r.cols = r.width()
+	}
+}
+
+// boxed reports whether sections are drawn in boxes.
+func (r *termRenderer) boxed() bool {
+	switch r.cfg.Style {
+	case display.StyleCompact, display.StyleMinimal, display.StylePlain:
+		return false
+	}
+	return true
+}
+
+// paint colors s, unless the style is plain. c may be nil for the
+// terminal's own color.
+func (r *termRenderer) paint(c *color.Color, s string) string {
+	if c == nil || r.cfg.Style == display.StylePlain || s == "" {
+		return s
+	}
+	return c.Sprint(s)
+}
+
+// fit shortens text to one line of at most limit characters, fewer if
+// used columns are already taken and the rest of the terminal is narrower.
+func (r *termRenderer) fit(text string, used, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if r.cols > 0 && r.cols-used < limit {
+		limit = max(r.cols-used, minWrapWidth)
+	}
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit-3]) + "..."
+}
+
+// message renders a message in the default, minimal or plain style.
+func (r *termRenderer) message(b *strings.Builder, msg *parser.StreamMessage, lineNum int) {
+	switch msg.Type {
+	case "system":
+		title := "SYSTEM"
+		if msg.Subtype != "" {
+			title += " [" + msg.Subtype + "]"
+		}
+		s := r.section(b, title, display.BoldCyan, display.Cyan, lineNum)
+		if msg.CWD != "" {
+			s.line(display.Cyan, "Working Directory: "+msg.CWD)
+		}
+		if msg.Model != "" {
+			s.line(display.Cyan, "Model: "+msg.Model)
+		}
+		if msg.ClaudeCodeVersion != "" {
+			s.line(display.Cyan, "Claude Code: v"+msg.ClaudeCodeVersion)
+		}
+		if len(msg.Tools) > 0 {
+			s.line(display.Cyan, fmt.Sprintf("Tools: %d available", len(msg.Tools)))
+		}
+		s.close()
+	case "assistant":
+		r.assistantMessage(b, msg, lineNum)
+	case "user":
+		for _, block := range msg.Message.Content {
+			if block.Type == "tool_result" {
+				r.toolResult(b, block, lineNum)
+			}
+		}
+	case "result":
+		r.resultMessage(b, msg, lineNum)
+	}
+}
+
+// assistantMessage renders assistant text and tool calls in order, with
+// consecutive text blocks in one section.
+func (r *termRenderer) assistantMessage(b *strings.Builder, msg *parser.StreamMessage, lineNum int) {
+	usage := msg.Message.Usage
+	content := msg.Message.Content
+	for len(content) > 0 {
+		block := content[0]
+		if block.Type == "tool_use" {
+			if lines, ok := fileDiff(block); ok {
+				r.writeFileDiff(b, block, lines, lineNum)
+			} else {
+				r.toolUse(b, block, lineNum)
+			}
+			content = content[1:]
+			continue
+		}
+
+		var texts []string
+		for len(content) > 0 && content[0].Type != "tool_use" {
+			if content[0].Type == "text" && content[0].Text != "" {
+				texts = append(texts, content[0].Text)
+			}
+			content = content[1:]
+		}
+		if len(texts) == 0 {
+			continue
+		}
+		s := r.section(b, "ASSISTANT", display.BoldGreen, display.Green, lineNum)
+		for _, text := range texts {
+			s.line(nil, text)
+		}
+		if r.cfg.Verbose && usage != nil {
+			s.line(display.Gray, usageText(usage))
+			usage = nil // Usage is shown once
+		}
+		s.close()
+	}
+}
+
+// toolUse renders a tool call and its input, sorted by name.
+func (r *termRenderer) toolUse(b *strings.Builder, block parser.ContentBlock, lineNum int) {
+	s := r.section(b, "TOOL: "+block.Name, display.BoldYellow, display.Yellow, lineNum)
+	if r.cfg.Verbose {
+		s.line(display.Yellow, "ID: "+block.ID)
+	}
+	if len(block.Input) > 0 {
+		s.line(display.Yellow, "Input:")
+		keys := make([]string, 0, len(block.Input))
+		for key := range block.Input {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lead := "  " + key + ": "
+			switch v := block.Input[key].(type) {
+			case string:
+				s.field(lead, display.Yellow, nil, clipString(v))
+			case []interface{}:
+				if block.Name == "TodoWrite" && key == "todos" {
+					s.line(display.Yellow, "  "+key+":")
+					r.todos(s, v)
+				} else {
+					s.field(lead, display.Yellow, nil, fmt.Sprintf("[%d items]", len(v)))
+				}
+			case map[string]interface{}:
+				s.field(lead, display.Yellow, nil, "{...}")
+			default:
+				s.field(lead, display.Yellow, nil, fmt.Sprint(v))
+			}
+		}
+	}
+	s.close()
+}
+
+// todos renders a TodoWrite list with an icon for each item's status.
+func (r *termRenderer) todos(s *section, todos []interface{}) {
+	for _, todo := range todos {
+		item, ok := todo.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		content, _ := item["content"].(string)
+		status, _ := item["status"].(string)
+		icon, c := "-", display.Gray
+		switch status {
+		case "completed":
+			icon, c = "✓", display.Green
+		case "in_progress":
+			icon, c = "→", display.Yellow
+		case "pending":
+			icon = "○"
+		}
+		if r.cfg.Style == display.StylePlain {
+			icon = "[" + icon + "]"
+		}
+		s.field("    "+icon+" ", c, nil, content)
+	}
+}
+
+// toolResult renders a tool's output, cut to the first and last lines.
+func (r *termRenderer) toolResult(b *strings.Builder, block parser.ContentBlock, lineNum int) {
+	title, titleColor, frame := "TOOL RESULT", display.BoldMagenta, display.Gray
+	if block.IsError {
+		title, titleColor, frame = "TOOL RESULT ERROR", display.BoldRed, display.Red
+	}
+	s := r.section(b, title, titleColor, frame, lineNum)
+	if r.cfg.Verbose {
+		s.line(frame, "Tool ID: "+block.ToolUseID)
+	}
+	text := r.resultText(block)
+	if text == "" {
+		s.line(display.Gray, "(no output)")
+	} else {
+		s.output(nil, text, r.maxLines)
+	}
+	s.close()
+}
+
+// resultText returns a tool's output, without system reminders unless
+// verbose.
+func (r *termRenderer) resultText(block parser.ContentBlock) string {
+	text := toolOutputText(block.Content)
+	if !r.cfg.Verbose {
+		text = parser.StripSystemReminders(text)
+	}
+	return text
+}
+
+// resultMessage renders the final result of a session.
+func (r *termRenderer) resultMessage(b *strings.Builder, msg *parser.StreamMessage, lineNum int) {
+	title, titleColor := "RESULT: SUCCESS", display.BoldBlue
+	if msg.IsError {
+		title, titleColor = "RESULT: ERROR", display.BoldRed
+	}
+	s := r.section(b, title, titleColor, display.Blue, lineNum)
+	if msg.NumTurns > 0 {
+		s.line(display.Blue, fmt.Sprintf("Turns: %d", msg.NumTurns))
+	}
+	if msg.DurationMS > 0 {
+		duration := fmt.Sprintf("Duration: %.2fs", float64(msg.DurationMS)/1000)
+		if msg.DurationAPIMS > 0 {
+			duration += fmt.Sprintf(" (API: %.2fs)", float64(msg.DurationAPIMS)/1000)
+		}
+		s.line(display.Blue, duration)
+	}
+	if msg.TotalCostUSD > 0 {
+		s.line(display.Blue, fmt.Sprintf("Cost: $%.4f", msg.TotalCostUSD))
+	}
+	if msg.Usage != nil {
+		s.blank()
+		s.line(display.Blue, usageText(msg.Usage))
+	}
+
+	if r.cfg.Verbose && len(msg.ModelUsage) > 0 {
+		s.blank()
+		s.line(display.Blue, "Model Usage:")
+		models := make([]string, 0, len(msg.ModelUsage))
+		for model := range msg.ModelUsage {
+			models = append(models, model)
+		}
+		sort.Strings(models)
+		for _, model := range models {
+			s.line(display.Blue, "  "+model+":")
+			usage, _ := msg.ModelUsage[model].(map[string]interface{})
+			if n, ok := usage["inputTokens"].(float64); ok {
+				s.line(display.Blue, fmt.Sprintf("    Input: %.0f tokens", n))
+			}
+			if n, ok := usage["outputTokens"].(float64); ok {
+				s.line(display.Blue, fmt.Sprintf("    Output: %.0f tokens", n))
+			}
+			if cost, ok := usage["costUSD"].(float64); ok {
+				s.line(display.Blue, fmt.Sprintf("    Cost: $%.4f", cost))
+			}
+		}
+	}
+
+	if len(msg.PermissionDenials) > 0 {
+		s.blank()
+		s.line(display.Red, fmt.Sprintf("Permission Denials: %d", len(msg.PermissionDenials)))
+		if r.cfg.Verbose {
+			for i, denial := range msg.PermissionDenials {
+				s.line(display.Red, fmt.Sprintf("  [%d] %v", i+1, denial))
+			}
+		}
+	}
+
+	if msg.Result != "" {
+		s.blank()
+		s.line(nil, msg.Result)
+	}
+	s.close()
+}
+
+// compactMessage renders a message in the compact style, a line per block.
+func (r *termRenderer) compactMessage(b *strings.Builder, msg *parser.StreamMessage, lineNum int) {
+	num := display.FormatLineNumCompact(lineNum, r.cfg.ShowLineNum)
+	used := utf8.RuneCountInString(num) + 1
+
+	switch msg.Type {
+	case "system":
+		line := r.paint(display.BoldCyan, "SYS")
+		if msg.Subtype != "" {
+			line += r.paint(display.Cyan, "["+msg.Subtype+"]")
+		}
+		line += r.paint(display.Gray, num)
+		if msg.Model != "" {
+			line += r.paint(display.Cyan, " "+msg.Model)
+		}
+		if msg.CWD != "" {
+			line += r.paint(display.Cyan, " @"+msg.CWD)
+		}
+		b.WriteString(line + "\n")
+	case "assistant":
+		for _, block := range msg.Message.Content {
+			switch block.Type {
+			case "text":
+				if block.Text != "" {
+					b.WriteString(r.paint(display.BoldGreen, "AST") + r.paint(display.Gray, num) + " " +
+						r.fit(block.Text, 3+used, compactChars) + "\n")
+				}
+			case "tool_use":
+				if lines, ok := fileDiff(block); ok {
+					r.writeFileDiff(b, block, lines, lineNum)
+					continue
+				}
+				b.WriteString(r.paint(display.BoldYellow, "TOOL") + r.paint(display.Gray, num) + " " +
+					r.paint(display.Yellow, r.fit(block.Name+" "+compactInput(block.Input), 4+used, 2*compactChars)) + "\n")
+			}
+		}
+	case "user":
+		for _, block := range msg.Message.Content {
+			if block.Type != "tool_result" {
+				continue
+			}
+			label := r.paint(display.BoldMagenta, "RES")
+			if block.IsError {
+				label = r.paint(display.BoldRed, "ERR")
+			}
+			text := r.resultText(block)
+			if text == "" {
+				text = r.paint(display.Gray, "(no output)")
+			} else {
+				text = r.fit(text, 3+used, compactChars)
+			}
+			b.WriteString(label + r.paint(display.Gray, num) + " " + text + "\n")
+		}
+	case "result":
+		line := r.paint(display.BoldBlue, "OK")
+		if msg.IsError {
+			line = r.paint(display.BoldRed, "FAIL")
+		}
+		line += r.paint(display.Gray, num)
+		var stats []string
+		if msg.NumTurns > 0 {
+			stats = append(stats, fmt.Sprintf("turns=%d", msg.NumTurns))
+		}
+		if msg.DurationMS > 0 {
+			stats = append(stats, fmt.Sprintf("%.2fs", float64(msg.DurationMS)/1000))
+		}
+		if msg.TotalCostUSD > 0 {
+			stats = append(stats, fmt.Sprintf("$%.4f", msg.TotalCostUSD))
+		}
+		if msg.Usage != nil {
+			stats = append(stats, fmt.Sprintf("in=%d out=%d", msg.Usage.InputTokens, msg.Usage.OutputTokens))
+		}
+		if len(stats) > 0 {
+			line += r.paint(display.Blue, " "+strings.Join(stats, " "))
+		}
+		b.WriteString(line + "\n")
+		if msg.Result != "" {
+			b.WriteString("  " + r.fit(msg.Result, 2, 2*compactChars) + "\n")
+		}
+	}
+}
+
+// compactInput returns tool input on one line, sorted by name.
+func compactInput(input map[string]interface{}) string {
+	if len(input) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(input))
+	for key := range input {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		switch v := input[key].(type) {
+		case string:
+			if utf8.RuneCountInString(v) > 50 {
+				v = string([]rune(v)[:50]) + "..."
+			}
+			parts[i] = fmt.Sprintf("%s: %q", key, v)
+		case []interface{}:
+			parts[i] = fmt.Sprintf("%s: [%d items]", key, len(v))
+		case map[string]interface{}:
+			parts[i] = key + ": {...}"
+		default:
+			parts[i] = fmt.Sprintf("%s: %v", key, v)
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// usageText describes token usage.
+func usageText(usage *parser.Usage) string {
+	text := fmt.Sprintf("Tokens: in=%d out=%d", usage.InputTokens, usage.OutputTokens)
+	if usage.CacheReadInputTokens > 0 {
+		text += fmt.Sprintf(" cache_read=%d", usage.CacheReadInputTokens)
+	}
+	if usage.CacheCreationInputTokens > 0 {
+		text += fmt.Sprintf(" cache_create=%d", usage.CacheCreationInputTokens)
+	}
+	return text
+}
+
+// clipString shortens a long value to its start and end.
+func clipString(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxInputChars {
+		return s
+	}
+	return fmt.Sprintf("%s ... (%d chars omitted) ... %s",
+		string(runes[:200]), len(runes)-maxInputChars, string(runes[len(runes)-100:]))
+}
+
+// section writes a titled block of lines: boxed in the default style, and
+// indented below the title in minimal and plain.
+type section struct {
+	r     *termRenderer
+	b     *strings.Builder
+	frame *color.Color
+}
+
+// section writes a section's title and returns it for its lines.
+func (r *termRenderer) section(b *strings.Builder, title string, titleColor, frame *color.Color, lineNum int) *section {
+	if r.boxed() {
+		title = "┌─ " + title
+	}
+	b.WriteString(r.paint(titleColor, title) + r.paint(display.Gray, display.FormatLineNum(lineNum, r.cfg.ShowLineNum)) + "\n")
+	return &section{r: r, b: b, frame: frame}
+}
+
+// prefix returns what each line of the section starts with.
+func (s *section) prefix() string {
+	if s.r.boxed() {
+		return s.r.paint(s.frame, "│ ")
+	}
+	return "  "
+}
+
+// line writes text in color c, a line for each of its lines, wrapped to
+// the terminal width.
+func (s *section) line(c *color.Color, text string) {
+	s.field("", nil, c, text)
+}
+
+// field writes text after a lead like "name: ", with the lines after the
+// first indented to line up with it.
+func (s *section) field(lead string, leadColor, c *color.Color, text string) {
+	indent := utf8.RuneCountInString(lead)
+	width := 0
+	if s.r.cols > 0 {
+		width = max(s.r.cols-2-indent, minWrapWidth)
+	}
+	first := true
+	for _, line := range strings.Split(text, "\n") {
+		for _, part := range wrapText(line, width) {
+			start := strings.Repeat(" ", indent)
+			if first {
+				start = s.r.paint(leadColor, lead)
+				first = false
+			}
+			s.b.WriteString(s.prefix() + start + s.r.paint(c, part) + "\n")
+		}
+	}
+}
+
+// output writes the first and last lines of text when it has more than
+// maxLines, saying how many were left out. maxLines 0 writes them all.
+func (s *section) output(c *color.Color, text string, maxLines int) {
+	lines := strings.Split(text, "\n")
+	if maxLines <= 0 || len(lines) <= maxLines {
+		s.line(c, text)
+		return
+	}
+	head, tail := maxLines-maxLines/2, maxLines/2
+	s.line(c, strings.Join(lines[:head], "\n"))
+	s.line(display.Gray, fmt.Sprintf("... (%d more lines) ...", len(lines)-head-tail))
+	if tail > 0 {
+		s.line(c, strings.Join(lines[len(lines)-tail:], "\n"))
+	}
+}
+
+// blank writes an empty line.
+func (s *section) blank() {
+	if s.r.boxed() {
+		s.b.WriteString(s.r.paint(s.frame, "│") + "\n")
+	} else {
+		s.b.WriteString("\n")
+	}
+}
+
+// close ends the section.
+func (s *section) close() {
+	if s.r.boxed() {
+		s.b.WriteString(s.r.paint(s.frame, "└─") + "\n")
+	} else {
+		s.b.WriteString("\n")
+	}
+}
+
+// wrapText breaks a line into lines of at most width characters, at a
+// space where there's one in the second half of the line. Tabs become
+// four spaces. width 0 leaves the line as it is.
+func wrapText(line string, width int) []string {
+	if width <= 0 {
+		return []string{line}
+	}
+	line = strings.ReplaceAll(line, "\t", "    ")
+	runes := []rune(line)
+	var lines []string
+	for len(runes) > width {
+		cut, next := width, width
+		for i := width; i > 0 && i >= width/2; i-- {
+			if runes[i] == ' ' {
+				cut, next = i, i+1
+				break
+			}
+		}
+		lines = append(lines, string(runes[:cut]))
+		runes = runes[next:]
+	}
+	return append(lines, string(runes))
+}
+
+// rawJSON returns a JSON value on one line, with secrets redacted, for
+// showing when it couldn't be rendered as a message. raw is the value as
+// read, if there was one.
+func rawJSON(raw []byte, msg *parser.StreamMessage, opts *cleanOptions) string {
+	if raw == nil && msg != nil {
+		raw, _ = json.Marshal(msg)
+	}
+	var compact bytes.Buffer
+	text := string(raw)
+	if err := json.Compact(&compact, raw); err == nil {
+		text = compact.String()
+	}
+	if opts.redactor != nil {
+		text = redactLine(text, opts.redactor.String)
+	}
+	return text
+}
@@ -0,0 +1,118 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// exitSessionFailed is clean's exit status with --fail-on-error when the
+// agent's session failed or didn't finish.
+const exitSessionFailed = 2
+
+// Session statuses.
+const (
+	sessionSuccess    = "success"
+	sessionError      = "error"
+	sessionIncomplete = "incomplete" // The stream ended without a result
+)
+
+// sessionInfo describes how an agent session went, for --fail-on-error and
+// --session-file.
+type sessionInfo struct {
+	SessionID string `json:"session_id"`
+	Model     string `json:"model"`
+	NumTurns  int    `json:"num_turns"`
+	Status    string `json:"status"`            // success, error or incomplete
+	Subtype   string `json:"subtype,omitempty"` // The result's subtype, like error_max_turns
+	Result    string `json:"result"`            // The final result text
+
+	messages map[string]bool // Assistant message IDs, to count turns without a result
+	seen     int
+}
+
+// newSessionInfo returns the info for a session not yet started.
+func newSessionInfo() *sessionInfo {
+	return &sessionInfo{Status: sessionIncomplete, messages: make(map[string]bool)}
+}
+
+// add records one message.
+func (s *sessionInfo) add(msg *parser.StreamMessage) {
+	if msg.SessionID != "" {
+		s.SessionID = msg.SessionID
+	}
+	if msg.Model != "" {
+		s.Model = msg.Model
+	}
+	switch msg.Type {
+	case "assistant":
+		if msg.Message == nil {
+			return
+		}
+		if msg.Message.Model != "" && s.Model == "" {
+			s.Model = msg.Message.Model
+		}
+		s.seen++
+		id := msg.Message.ID
+		if id == "" {
+			id = fmt.Sprintf("#%d", s.seen)
+		}
+		s.messages[id] = true
+		if s.Status == sessionIncomplete {
+			s.NumTurns = len(s.messages)
+		}
+	case "result":
+		// A later result (as after a resumed session) replaces an earlier one
+		s.Status, s.Subtype, s.Result = sessionSuccess, msg.Subtype, msg.Result
+		if msg.IsError || (msg.Subtype != "" && msg.Subtype != "success") {
+			s.Status = sessionError
+		}
+		if msg.NumTurns > 0 {
+			s.NumTurns = msg.NumTurns
+		}
+	}
+}
+
+// failure returns an error if the session didn't end in success.
+func (s *sessionInfo) failure() error {
+	var err error
+	switch s.Status {
+	case sessionSuccess:
+		return nil
+	case sessionIncomplete:
+		err = errors.New("agent session ended without a result")
+	default:
+		reason := firstLine(s.Result)
+		if reason == "" {
+			reason = s.Subtype
+		}
+		err = fmt.Errorf("agent session failed: %s", reason)
+	}
+	return &exitError{code: exitSessionFailed, err: err}
+}
+
+// writeFile writes the session info as JSON to path.
+func (s *sessionInfo) writeFile(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+	return nil
+}
+
+// firstLine returns the first non-blank line of text, trimmed.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
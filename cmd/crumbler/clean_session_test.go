@@ -0,0 +1,105 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+func TestSessionInfo(t *testing.T) {
+	t.Parallel()
+
+	const start = `{"type":"system","subtype":"init","session_id":"s1","model":"claude-x"}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"a"}]}}
+{"type":"assistant","message":{"id":"m1","content":[{"type":"text","text":"b"}]}}
+{"type":"assistant","message":{"id":"m2","content":[{"type":"text","text":"c"}]}}
+`
+	tests := []struct {
+		name   string
+		input  string
+		want   sessionInfo
+		failed string // Expected --fail-on-error message, if any
+	}{
+		{
+			name:  "success",
+			input: start + `{"type":"result","subtype":"success","session_id":"s1","num_turns":4,"result":"All done"}`,
+			want:  sessionInfo{SessionID: "s1", Model: "claude-x", NumTurns: 4, Status: "success", Subtype: "success", Result: "All done"},
+		},
+		{
+			name:   "error result",
+			input:  start + `{"type":"result","subtype":"success","is_error":true,"result":"\nAPI Error: 529 overloaded\nretry later"}`,
+			want:   sessionInfo{SessionID: "s1", Model: "claude-x", NumTurns: 2, Status: "error", Subtype: "success", Result: "\nAPI Error: 529 overloaded\nretry later"},
+			failed: "agent session failed: API Error: 529 overloaded",
+		},
+		{
+			name:   "max turns",
+			input:  start + `{"type":"result","subtype":"error_max_turns","num_turns":10}`,
+			want:   sessionInfo{SessionID: "s1", Model: "claude-x", NumTurns: 10, Status: "error", Subtype: "error_max_turns"},
+			failed: "agent session failed: error_max_turns",
+		},
+		{
+			name:   "no result",
+			input:  start,
+			want:   sessionInfo{SessionID: "s1", Model: "claude-x", NumTurns: 2, Status: "incomplete"},
+			failed: "agent session ended without a result",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newSessionInfo()
+			opts := &cleanOptions{session: s, summaryJSON: true}
+			if err := processJSONStream(strings.NewReader(tt.input), &display.Config{}, opts); err != nil {
+				t.Fatal(err)
+			}
+
+			got := *s
+			got.messages, got.seen = nil, 0
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("session = %+v, want %+v", got, tt.want)
+			}
+
+			err := s.failure()
+			switch {
+			case tt.failed == "" && err != nil:
+				t.Errorf("failure() = %v, want nil", err)
+			case tt.failed != "" && (err == nil || err.Error() != tt.failed):
+				t.Errorf("failure() = %v, want %q", err, tt.failed)
+			case tt.failed != "" && ExitCode(err) != exitSessionFailed:
+				t.Errorf("ExitCode = %d, want %d", ExitCode(err), exitSessionFailed)
+			}
+		})
+	}
+}
+
+func TestSessionInfoWriteFile(t *testing.T) {
+	t.Parallel()
+
+	s := newSessionInfo()
+	s.SessionID, s.Model, s.NumTurns, s.Status, s.Result = "s1", "claude-x", 3, "success", "Done"
+	path := filepath.Join(t.TempDir(), "session.json")
+	if err := s.writeFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	want := map[string]interface{}{"session_id": "s1", "model": "claude-x", "num_turns": 3.0, "status": "success", "result": "Done"}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+}
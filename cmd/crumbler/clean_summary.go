@@ -0,0 +1,234 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ariel-frischer/claude-clean/parser"
+)
+
+// sessionSummary aggregates a stream of messages into session totals.
+type sessionSummary struct {
+	Messages            int          `json:"messages"`
+	Turns               int          `json:"turns"`
+	InputTokens         int          `json:"input_tokens"`
+	OutputTokens        int          `json:"output_tokens"`
+	CacheReadTokens     int          `json:"cache_read_tokens"`
+	CacheCreationTokens int          `json:"cache_creation_tokens"`
+	CostUSD             float64      `json:"cost_usd"`
+	ToolCalls           int          `json:"tool_calls"`
+	ToolErrors          int          `json:"tool_errors"`
+	Tools               []toolTotals `json:"tools"`
+	DurationMS          int64        `json:"duration_ms"`
+	Start               *time.Time   `json:"start,omitempty"`
+	End                 *time.Time   `json:"end,omitempty"`
+
+	usage       map[string]*parser.Usage // Latest usage per assistant message ID
+	usageOrder  []string                 // Assistant message IDs in first-seen order
+	toolNames   map[string]string        // Tool name by tool_use ID
+	tools       map[string]*toolTotals   // Totals by tool name
+	resultTurns int                      // Turns reported by result messages
+	resultMS    int64                    // Durations reported by result messages
+	resultUsage parser.Usage             // Usage reported by result messages
+}
+
+// toolTotals counts calls and errors for one tool.
+type toolTotals struct {
+	Name   string `json:"name"`
+	Calls  int    `json:"calls"`
+	Errors int    `json:"errors"`
+}
+
+// newSessionSummary returns an empty summary.
+func newSessionSummary() *sessionSummary {
+	return &sessionSummary{
+		usage:     make(map[string]*parser.Usage),
+		toolNames: make(map[string]string),
+		tools:     make(map[string]*toolTotals),
+	}
+}
+
+// add records one message. raw is the message's original JSON, which may
+// carry a timestamp that parser.StreamMessage doesn't keep.
+func (s *sessionSummary) add(msg *parser.StreamMessage, raw []byte) {
+	s.Messages++
+
+	var stamped struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	if json.Unmarshal(raw, &stamped) == nil && !stamped.Timestamp.IsZero() {
+		if s.Start == nil || stamped.Timestamp.Before(*s.Start) {
+			t := stamped.Timestamp
+			s.Start = &t
+		}
+		if s.End == nil || stamped.Timestamp.After(*s.End) {
+			t := stamped.Timestamp
+			s.End = &t
+		}
+	}
+
+	switch msg.Type {
+	case "assistant":
+		if msg.Message == nil {
+			return
+		}
+		// Streamed assistant messages repeat their ID (and usage) once per
+		// content block; count each message once with its latest usage
+		id := msg.Message.ID
+		if id == "" {
+			id = fmt.Sprintf("#%d", s.Messages)
+		}
+		if _, seen := s.usage[id]; !seen {
+			s.usageOrder = append(s.usageOrder, id)
+			s.usage[id] = nil
+		}
+		if msg.Message.Usage != nil {
+			s.usage[id] = msg.Message.Usage
+		}
+		for _, block := range msg.Message.Content {
+			if block.Type != "tool_use" {
+				continue
+			}
+			if _, seen := s.toolNames[block.ID]; seen && block.ID != "" {
+				continue
+			}
+			s.toolNames[block.ID] = block.Name
+			s.tool(block.Name).Calls++
+		}
+	case "user":
+		if msg.Message == nil {
+			return
+		}
+		for _, block := range msg.Message.Content {
+			if block.Type == "tool_result" && block.IsError {
+				s.tool(s.toolNames[block.ToolUseID]).Errors++
+			}
+		}
+	case "result":
+		s.CostUSD += msg.TotalCostUSD
+		s.resultTurns += msg.NumTurns
+		s.resultMS += int64(msg.DurationMS)
+		if u := msg.Usage; u != nil {
+			s.resultUsage.InputTokens += u.InputTokens
+			s.resultUsage.OutputTokens += u.OutputTokens
+			s.resultUsage.CacheReadInputTokens += u.CacheReadInputTokens
+			s.resultUsage.CacheCreationInputTokens += u.CacheCreationInputTokens
+		}
+	}
+}
+
+// tool returns the totals for a tool, creating them if needed.
+func (s *sessionSummary) tool(name string) *toolTotals {
+	if name == "" {
+		name = "unknown"
+	}
+	t, ok := s.tools[name]
+	if !ok {
+		t = &toolTotals{Name: name}
+		s.tools[name] = t
+	}
+	return t
+}
+
+// finish computes the totals from the recorded messages.
+func (s *sessionSummary) finish() {
+	// Prefer per-message usage; formats without it report usage in results
+	total := s.resultUsage
+	if s.hasMessageUsage() {
+		total = parser.Usage{}
+		for _, id := range s.usageOrder {
+			if u := s.usage[id]; u != nil {
+				total.InputTokens += u.InputTokens
+				total.OutputTokens += u.OutputTokens
+				total.CacheReadInputTokens += u.CacheReadInputTokens
+				total.CacheCreationInputTokens += u.CacheCreationInputTokens
+			}
+		}
+	}
+	s.InputTokens, s.OutputTokens = total.InputTokens, total.OutputTokens
+	s.CacheReadTokens, s.CacheCreationTokens = total.CacheReadInputTokens, total.CacheCreationInputTokens
+
+	// Prefer the agent's own turn count; otherwise count assistant messages
+	s.Turns = s.resultTurns
+	if s.Turns == 0 {
+		s.Turns = len(s.usageOrder)
+	}
+
+	// Prefer wall-clock time from timestamps; otherwise the reported duration
+	s.DurationMS = s.resultMS
+	if s.Start != nil && s.End.After(*s.Start) {
+		s.DurationMS = s.End.Sub(*s.Start).Milliseconds()
+	}
+
+	s.Tools = s.Tools[:0]
+	s.ToolCalls, s.ToolErrors = 0, 0
+	for _, t := range s.tools {
+		s.Tools = append(s.Tools, *t)
+		s.ToolCalls += t.Calls
+		s.ToolErrors += t.Errors
+	}
+	// Most used first, then by name
+	sort.Slice(s.Tools, func(i, j int) bool {
+		if s.Tools[i].Calls != s.Tools[j].Calls {
+			return s.Tools[i].Calls > s.Tools[j].Calls
+		}
+		return s.Tools[i].Name < s.Tools[j].Name
+	})
+}
+
+// hasMessageUsage reports whether any assistant message carried usage.
+func (s *sessionSummary) hasMessageUsage() bool {
+	for _, u := range s.usage {
+		if u != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// writeText prints the summary for people.
+func (s *sessionSummary) writeText(w io.Writer) error {
+	s.finish()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SESSION SUMMARY")
+	fmt.Fprintf(tw, "  Duration:\t%s\n", time.Duration(s.DurationMS)*time.Millisecond)
+	fmt.Fprintf(tw, "  Turns:\t%d\n", s.Turns)
+	fmt.Fprintf(tw, "  Input tokens:\t%d\n", s.InputTokens)
+	fmt.Fprintf(tw, "  Output tokens:\t%d\n", s.OutputTokens)
+	fmt.Fprintf(tw, "  Cache tokens:\t%d read, %d written\n", s.CacheReadTokens, s.CacheCreationTokens)
+	fmt.Fprintf(tw, "  Cost:\t$%.4f\n", s.CostUSD)
+	fmt.Fprintf(tw, "  Tool calls:\t%d (%s)\n", s.ToolCalls, plural(s.ToolErrors, "error"))
+	for _, t := range s.Tools {
+		line := fmt.Sprintf("    %s\t%d", t.Name, t.Calls)
+		if t.Errors > 0 {
+			line += fmt.Sprintf(" (%s)", plural(t.Errors, "error"))
+		}
+		fmt.Fprintln(tw, line)
+	}
+	return tw.Flush()
+}
+
+// writeJSON prints the summary for scripts.
+func (s *sessionSummary) writeJSON(w io.Writer) error {
+	s.finish()
+	if s.Tools == nil {
+		s.Tools = []toolTotals{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// plural formats a count with a noun, adding "s" unless the count is one.
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, strings.TrimSuffix(noun, "s"))
+}
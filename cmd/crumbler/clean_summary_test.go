@@ -0,0 +1,130 @@
+package crumbler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ariel-frischer/claude-clean/display"
+)
+
+// sessionLog is a short stream-json session: two assistant messages (the
+// first streamed as two lines sharing an ID and usage), three tool calls
+// with one error, and a result.
+const sessionLog = `{"type":"system","subtype":"init","session_id":"s1","timestamp":"2026-01-02T10:00:00Z"}
+{"type":"assistant","timestamp":"2026-01-02T10:00:05Z","message":{"id":"m1","role":"assistant","content":[{"type":"text","text":"Looking"}],"usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:06Z","message":{"id":"m1","role":"assistant","content":[{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"ls"}},{"type":"tool_use","id":"t2","name":"Read","input":{}}],"usage":{"input_tokens":100,"output_tokens":20,"cache_read_input_tokens":1000,"cache_creation_input_tokens":50}}}
+{"type":"user","timestamp":"2026-01-02T10:00:07Z","message":{"role":"user","content":[{"type":"tool_result","tool_use_id":"t1","content":"boom","is_error":true},{"type":"tool_result","tool_use_id":"t2","content":"ok"}]}}
+{"type":"assistant","timestamp":"2026-01-02T10:00:30Z","message":{"id":"m2","role":"assistant","content":[{"type":"tool_use","id":"t3","name":"Bash","input":{"command":"pwd"}}],"usage":{"input_tokens":10,"output_tokens":5,"cache_read_input_tokens":200}}}
+{"type":"result","subtype":"success","timestamp":"2026-01-02T10:01:00Z","num_turns":3,"duration_ms":59000,"total_cost_usd":0.25}
+`
+
+func TestSessionSummary(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  sessionSummary
+		tools []toolTotals
+	}{
+		{
+			name:  "full session",
+			input: sessionLog,
+			want: sessionSummary{
+				Messages:            6,
+				Turns:               3,
+				InputTokens:         110,
+				OutputTokens:        25,
+				CacheReadTokens:     1200,
+				CacheCreationTokens: 50,
+				CostUSD:             0.25,
+				ToolCalls:           3,
+				ToolErrors:          1,
+				DurationMS:          60000,
+			},
+			tools: []toolTotals{{Name: "Bash", Calls: 2, Errors: 1}, {Name: "Read", Calls: 1}},
+		},
+		{
+			name: "no result or timestamps",
+			input: `{"type":"assistant","message":{"id":"a","content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":1,"output_tokens":2}}}
+{"type":"assistant","message":{"id":"b","content":[{"type":"text","text":"bye"}],"usage":{"input_tokens":3,"output_tokens":4}}}`,
+			want: sessionSummary{Messages: 2, Turns: 2, InputTokens: 4, OutputTokens: 6},
+		},
+		{
+			name:  "reported duration without timestamps",
+			input: `{"type":"result","num_turns":1,"duration_ms":1500,"total_cost_usd":0.01}`,
+			want:  sessionSummary{Messages: 1, Turns: 1, CostUSD: 0.01, DurationMS: 1500},
+		},
+		{
+			name:  "empty",
+			input: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			summary := newSessionSummary()
+			opts := &cleanOptions{summary: summary, summaryJSON: true}
+			if err := processJSONStream(strings.NewReader(tt.input), &display.Config{}, opts); err != nil {
+				t.Fatalf("processJSONStream() error: %v", err)
+			}
+
+			var out bytes.Buffer
+			if err := summary.writeJSON(&out); err != nil {
+				t.Fatal(err)
+			}
+			var got sessionSummary
+			if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("summary is not valid JSON: %v\n%s", err, out.String())
+			}
+
+			tools := got.Tools
+			got.Tools, got.Start, got.End = nil, nil, nil
+			if got.Messages != tt.want.Messages || got.Turns != tt.want.Turns ||
+				got.InputTokens != tt.want.InputTokens || got.OutputTokens != tt.want.OutputTokens ||
+				got.CacheReadTokens != tt.want.CacheReadTokens || got.CacheCreationTokens != tt.want.CacheCreationTokens ||
+				got.CostUSD != tt.want.CostUSD || got.ToolCalls != tt.want.ToolCalls ||
+				got.ToolErrors != tt.want.ToolErrors || got.DurationMS != tt.want.DurationMS {
+				t.Errorf("summary = %+v\nwant %+v", got, tt.want)
+			}
+			if len(tools) != len(tt.tools) {
+				t.Fatalf("tools = %+v, want %+v", tools, tt.tools)
+			}
+			for i := range tools {
+				if tools[i] != tt.tools[i] {
+					t.Errorf("tools[%d] = %+v, want %+v", i, tools[i], tt.tools[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSessionSummaryText(t *testing.T) {
+	t.Parallel()
+
+	summary := newSessionSummary()
+	opts := &cleanOptions{summary: summary, summaryJSON: true}
+	if err := processJSONStream(strings.NewReader(sessionLog), &display.Config{}, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := summary.writeText(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Duration:", "1m0s",
+		"Turns:", "3",
+		"Cache tokens:", "1200 read, 50 written",
+		"Cost:", "$0.2500",
+		"Tool calls:", "3 (1 error)",
+		"Bash", "2 (1 error)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("summary missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -9,199 +9,6 @@ import (
 	"github.com/ariel-frischer/claude-clean/display"
 )
 
-func TestSkipWhitespace(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name     string
-		input    string
-		expected int
-	}{
-		{
-			name:     "no whitespace",
-			input:    `{"key":"value"}`,
-			expected: 0,
-		},
-		{
-			name:     "leading spaces",
-			input:    `   {"key":"value"}`,
-			expected: 3,
-		},
-		{
-			name:     "leading tabs",
-			input:    "\t\t\t{\"key\":\"value\"}",
-			expected: 3,
-		},
-		{
-			name:     "LF line ending",
-			input:    "\n{\"key\":\"value\"}",
-			expected: 1,
-		},
-		{
-			name:     "CR line ending",
-			input:    "\r{\"key\":\"value\"}",
-			expected: 1,
-		},
-		{
-			name:     "CRLF line ending",
-			input:    "\r\n{\"key\":\"value\"}",
-			expected: 2,
-		},
-		{
-			name:     "multiple LF",
-			input:    "\n\n\n{\"key\":\"value\"}",
-			expected: 3,
-		},
-		{
-			name:     "multiple CRLF",
-			input:    "\r\n\r\n{\"key\":\"value\"}",
-			expected: 4,
-		},
-		{
-			name:     "mixed whitespace",
-			input:    " \t \r\n \n {\"key\":\"value\"}",
-			expected: 8, // space(1) + tab(1) + space(1) + CRLF(2) + space(1) + LF(1) + space(1) = 8
-		},
-		{
-			name:     "empty buffer",
-			input:    "",
-			expected: 0,
-		},
-		{
-			name:     "only whitespace",
-			input:    "   \r\n\t  ",
-			expected: 8, // space(1) + space(1) + space(1) + CRLF(2) + tab(1) + space(1) + space(1) = 8
-		},
-		{
-			name:     "CR not followed by LF",
-			input:    "\r{\"key\":\"value\"}",
-			expected: 1,
-		},
-		{
-			name:     "CRLF at end of buffer",
-			input:    "\r\n",
-			expected: 2,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			result := skipWhitespace([]byte(tt.input))
-			if result != tt.expected {
-				t.Errorf("skipWhitespace(%q) = %d, want %d", tt.input, result, tt.expected)
-			}
-		})
-	}
-}
-
-func TestFindCompleteJSON(t *testing.T) {
-	t.Parallel()
-
-	tests := []struct {
-		name          string
-		input         string
-		expectedStart int
-		expectedEnd   int
-	}{
-		{
-			name:          "simple JSON object",
-			input:         `{"key":"value"}`,
-			expectedStart: 0,
-			expectedEnd:   15,
-		},
-		{
-			name:          "JSON with nested objects",
-			input:         `{"outer":{"inner":"value"}}`,
-			expectedStart: 0,
-			expectedEnd:   27, // len(`{"outer":{"inner":"value"}}`) = 27
-		},
-		{
-			name:          "JSON with string containing braces",
-			input:         `{"key":"value {with} braces"}`,
-			expectedStart: 0,
-			expectedEnd:   29, // len(`{"key":"value {with} braces"}`) = 29
-		},
-		{
-			name:          "JSON with escaped quotes",
-			input:         `{"key":"value \"quoted\""}`,
-			expectedStart: 0,
-			expectedEnd:   26,
-		},
-		{
-			name:          "JSON with escaped backslash",
-			input:         `{"key":"value \\ backslash"}`,
-			expectedStart: 0,
-			expectedEnd:   28,
-		},
-		{
-			name:          "multiple JSON objects",
-			input:         `{"first":"value"}{"second":"value"}`,
-			expectedStart: 0,
-			expectedEnd:   17, // len(`{"first":"value"}`) = 17
-		},
-		{
-			name:          "JSON with leading whitespace",
-			input:         `   {"key":"value"}`,
-			expectedStart: 3,
-			expectedEnd:   18,
-		},
-		{
-			name:          "incomplete JSON",
-			input:         `{"key":"value`,
-			expectedStart: 0, // Function finds opening brace, but no closing brace
-			expectedEnd:   -1,
-		},
-		{
-			name:          "empty buffer",
-			input:         ``,
-			expectedStart: -1,
-			expectedEnd:   -1,
-		},
-		{
-			name:          "only opening brace",
-			input:         `{`,
-			expectedStart: 0,
-			expectedEnd:   -1,
-		},
-		{
-			name:          "JSON with array",
-			input:         `{"array":[1,2,3]}`,
-			expectedStart: 0,
-			expectedEnd:   17,
-		},
-		{
-			name:          "JSON with nested braces in string",
-			input:         `{"key":"{nested}"}`,
-			expectedStart: 0,
-			expectedEnd:   18, // len(`{"key":"{nested}"}`) = 18
-		},
-		{
-			name:          "JSON with newlines",
-			input:         "{\n  \"key\": \"value\"\n}",
-			expectedStart: 0,
-			expectedEnd:   20,
-		},
-		{
-			name:          "JSON with CRLF",
-			input:         "{\r\n  \"key\": \"value\"\r\n}",
-			expectedStart: 0,
-			expectedEnd:   22,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			t.Parallel()
-			start, end := findCompleteJSON([]byte(tt.input))
-			if start != tt.expectedStart || end != tt.expectedEnd {
-				t.Errorf("findCompleteJSON(%q) = (%d, %d), want (%d, %d)",
-					tt.input, start, end, tt.expectedStart, tt.expectedEnd)
-			}
-		})
-	}
-}
-
 func TestProcessJSONStream_SimpleObjects(t *testing.T) {
 	t.Parallel()
 
@@ -244,7 +51,7 @@ func TestProcessJSONStream_SimpleObjects(t *testing.T) {
 				ShowLineNum: false,
 			}
 			input := bytes.NewReader([]byte(tt.input))
-			err := processJSONStream(input, cfg, false)
+			err := processJSONStream(input, cfg, nil)
 			if err != nil {
 				t.Errorf("processJSONStream() error = %v", err)
 			}
@@ -267,7 +74,7 @@ func TestProcessJSONStream_ChunkedInput(t *testing.T) {
 		ShowLineNum: false,
 	}
 
-	err := processJSONStream(chunkedReader, cfg, false)
+	err := processJSONStream(chunkedReader, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() with chunked input error = %v", err)
 	}
@@ -284,7 +91,7 @@ func TestProcessJSONStream_PartialJSON(t *testing.T) {
 		ShowLineNum: false,
 	}
 
-	err := processJSONStream(input, cfg, false)
+	err := processJSONStream(input, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() with partial JSON error = %v", err)
 	}
@@ -301,7 +108,7 @@ func TestProcessJSONStream_InvalidJSON(t *testing.T) {
 		ShowLineNum: false,
 	}
 
-	err := processJSONStream(input, cfg, false)
+	err := processJSONStream(input, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() with invalid JSON error = %v", err)
 	}
@@ -317,7 +124,7 @@ func TestProcessJSONStream_EmptyInput(t *testing.T) {
 		ShowLineNum: false,
 	}
 
-	err := processJSONStream(input, cfg, false)
+	err := processJSONStream(input, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() with empty input error = %v", err)
 	}
@@ -358,7 +165,7 @@ func TestProcessJSONStream_WhitespaceOnly(t *testing.T) {
 				ShowLineNum: false,
 			}
 
-			err := processJSONStream(input, cfg, false)
+			err := processJSONStream(input, cfg, nil)
 			if err != nil {
 				t.Errorf("processJSONStream() with whitespace only error = %v", err)
 			}
@@ -380,7 +187,7 @@ func TestProcessJSONStream_LargeJSON(t *testing.T) {
 		ShowLineNum: false,
 	}
 
-	err := processJSONStream(input, cfg, false)
+	err := processJSONStream(input, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() with large JSON error = %v", err)
 	}
@@ -399,7 +206,7 @@ func TestProcessJSONStream_PanicRecovery(t *testing.T) {
 	}
 
 	// This should complete without crashing even if display library panics
-	err := processJSONStream(input, cfg, false)
+	err := processJSONStream(input, cfg, nil)
 	if err != nil {
 		t.Errorf("processJSONStream() should recover from panics, got error: %v", err)
 	}
@@ -0,0 +1,243 @@
+package crumbler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/waynenilsen/crumbler/internal/ledger"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// runCost handles the 'crumbler cost' command.
+// It rolls the cost ledger written by 'crumbler clean --record' up the tree.
+func runCost(args []string) error {
+	// Handle help flag
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
+		printCostHelp()
+		return nil
+	}
+
+	var jsonOut bool
+	for _, arg := range args {
+		switch arg {
+		case "--json":
+			jsonOut = true
+		default:
+			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler cost --help' for usage", arg)
+		}
+	}
+
+	root, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+	report, err := loadCostReport(root)
+	if err != nil {
+		return err
+	}
+
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report.json())
+	}
+
+	if len(report.totals) == 0 {
+		fmt.Printf("No costs recorded (%s not found or empty).\n", ledger.File)
+		fmt.Println("Record them with: ... | crumbler clean --record")
+		return nil
+	}
+	fmt.Printf("Spent: %s\n\n", formatCost(report.total()))
+	if report.tree != nil {
+		fmt.Println(report.tree.TreeWithLabels(report.label("")))
+	}
+	report.printDeleted()
+	return nil
+}
+
+// costReport is the ledger rolled up over the current crumb tree.
+type costReport struct {
+	rootPath string                    // Root crumb path, relative to the project root
+	tree     *crumbler.Crumb           // Live crumbs; nil when the project is done
+	live     map[string]bool           // Paths of live crumbs
+	totals   map[string]*ledger.Totals // Rolled-up totals by crumb path
+}
+
+// loadCostReport reads the ledger and the crumb tree of the project at root.
+func loadCostReport(root string) (*costReport, error) {
+	project, err := crumbler.Open(root)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := project.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return newCostReport(project.Root(), snap.List())
+}
+
+// newCostReport reads the ledger of the project at root and rolls it up
+// over tree, the crumb tree already read (nil when the project is done).
+func newCostReport(root string, tree *crumbler.Crumb) (*costReport, error) {
+	entries, err := ledger.Read(root)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &costReport{
+		rootPath: relPath(root, crumblerDir(root)),
+		tree:     tree,
+		live:     make(map[string]bool),
+		totals:   ledger.Rollup(entries),
+	}
+	r.addLive(tree)
+	return r, nil
+}
+
+// addLive records the paths of c and its descendants as live.
+func (r *costReport) addLive(c *crumbler.Crumb) {
+	if c == nil {
+		return
+	}
+	r.live[c.RelPath] = true
+	for _, child := range c.Children {
+		r.addLive(child)
+	}
+}
+
+// total returns the totals for the whole project.
+func (r *costReport) total() ledger.Totals {
+	if t := r.totals[r.rootPath]; t != nil {
+		return *t
+	}
+	return ledger.Totals{}
+}
+
+// label returns a tree label function showing each crumb's totals after
+// any marker for currentPath.
+func (r *costReport) label(currentPath string) func(c *crumbler.Crumb) string {
+	return func(c *crumbler.Crumb) string {
+		var label string
+		if currentPath != "" && c.Path == currentPath {
+			label += " ← current"
+		}
+		if t := r.totals[c.RelPath]; t != nil {
+			label += "  " + formatCost(*t)
+		}
+		return label
+	}
+}
+
+// deleted returns the deleted crumbs with recorded costs whose parent
+// still exists (or is the root crumb), sorted by path. Their totals
+// include their own deleted descendants.
+func (r *costReport) deleted() []string {
+	var paths []string
+	for path := range r.totals {
+		if r.live[path] || path == r.rootPath {
+			continue
+		}
+		if parent := filepath.Dir(path); r.live[parent] || parent == r.rootPath {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// printDeleted lists the costs of deleted crumbs.
+func (r *costReport) printDeleted() {
+	paths := r.deleted()
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Println("Deleted crumbs (included in the totals above):")
+	for _, path := range paths {
+		fmt.Printf("  ✓ %s  %s\n", path, formatCost(*r.totals[path]))
+	}
+}
+
+// costJSON is the --json output of 'crumbler cost'.
+type costJSON struct {
+	Total  ledger.Totals   `json:"total"`
+	Crumbs []crumbCostJSON `json:"crumbs"`
+}
+
+// crumbCostJSON is one crumb's rolled-up totals.
+type crumbCostJSON struct {
+	Crumb   string `json:"crumb"`
+	Deleted bool   `json:"deleted"`
+	ledger.Totals
+}
+
+// json returns the report for --json, with crumbs sorted by path.
+func (r *costReport) json() costJSON {
+	out := costJSON{Total: r.total(), Crumbs: []crumbCostJSON{}}
+	for path, t := range r.totals {
+		deleted := !r.live[path] && !(path == r.rootPath && r.tree == nil)
+		out.Crumbs = append(out.Crumbs, crumbCostJSON{Crumb: path, Deleted: deleted, Totals: *t})
+	}
+	sort.Slice(out.Crumbs, func(i, j int) bool { return out.Crumbs[i].Crumb < out.Crumbs[j].Crumb })
+	return out
+}
+
+// formatCost formats totals as "$1.2345 · 12.3k tokens".
+func formatCost(t ledger.Totals) string {
+	return fmt.Sprintf("$%.4f · %s tokens", t.CostUSD, formatTokens(t.Tokens()))
+}
+
+// formatTokens abbreviates a token count (950, 12.3k, 1.2M).
+func formatTokens(n int) string {
+	switch {
+	case n >= 1000000:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// printCostHelp prints help for the cost command.
+func printCostHelp() {
+	fmt.Print(`crumbler cost - Show what agents spent on each crumb
+
+USAGE:
+    crumbler cost [--json]
+
+DESCRIPTION:
+    Rolls up the cost ledger written by 'crumbler clean --record' over the
+    crumb tree. Each crumb's totals include its descendants, including ones
+    that were already deleted, so a branch shows everything spent on it.
+    Deleted crumbs whose parent still exists are listed below the tree.
+
+    The ledger is ` + ledger.File + ` in the project root. It identifies
+    crumbs by path, so renaming or reordering a crumb leaves its earlier
+    costs under the old path.
+
+OPTIONS:
+    --json       Print totals for every recorded crumb path as JSON
+    -h, --help   Show this help message
+
+EXAMPLES:
+    # Record usage while an agent works, then see where it went
+    crumbler prompt | claude -p --verbose --output-format stream-json | crumbler clean --record
+    crumbler cost
+
+    # Show costs next to the tree
+    crumbler status --cost
+
+OUTPUT EXAMPLE:
+    Spent: $1.8200 · 1.4M tokens
+
+    .crumbler/  $1.8200 · 1.4M tokens
+    ├── 01-setup/  $1.1000 · 820.5k tokens
+    │   └── 02-api/
+    └── 02-features/
+
+    Deleted crumbs (included in the totals above):
+      ✓ .crumbler/01-setup/01-database  $1.1000 · 820.5k tokens
+`)
+}
@@ -1,10 +1,6 @@
 package crumbler
 
-import (
-	"fmt"
-
-	"github.com/waynenilsen/crumbler/internal/crumb"
-)
+import "fmt"
 
 // runCreate handles the 'crumbler create' command.
 // It creates new sub-crumbs under the current crumb.
@@ -21,20 +17,18 @@ func runCreate(args []string) error {
 		return fmt.Errorf("error: missing crumb name\n\nUsage: crumbler create \"Name\" [\"Name2\" ...]\n\nRun 'crumbler create --help' for more information")
 	}
 
-	projectRoot, err := getProjectRoot()
+	project, err := openNewProject()
 	if err != nil {
 		return err
 	}
 
-	// Create all crumbs as siblings
-	paths, err := crumb.CreateMultiple(projectRoot, args)
+	// Create all crumbs as siblings (runs the create hooks)
+	created, err := project.Create(args...)
 	if err != nil {
-		return fmt.Errorf("failed to create crumb(s): %w", err)
+		return err
 	}
-
-	for _, path := range paths {
-		rel := relPath(projectRoot, path)
-		fmt.Printf("Created crumb: %s/README.md\n", rel)
+	for _, c := range created {
+		fmt.Printf("Created crumb: %s/README.md\n", c.RelPath)
 	}
 
 	fmt.Println("\nNOTE: Agents must call read tool before write tool on README.md files, even though they're empty.")
@@ -67,6 +61,10 @@ CREATES:
     XX-name/           Crumb directory (XX is auto-assigned ID)
     XX-name/README.md  Empty README file
 
+HOOKS:
+    Runs .crumbler/hooks/pre-create before creating (a non-zero exit aborts)
+    and .crumbler/hooks/post-create afterwards. See 'crumbler help hooks'.
+
 CONSTRAINTS:
     - Maximum 10 children per crumb (IDs 01-10)
     - Names are converted to kebab-case
@@ -84,6 +82,7 @@ EXAMPLES:
 
 ERRORS:
     - "directory is full" - Parent crumb already has 10 children
+    - "invalid crumb name" - Name has no letters or digits
     - "not a crumbler project" - No .crumbler directory found
 `)
 }
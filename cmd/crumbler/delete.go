@@ -1,52 +1,76 @@
 package crumbler
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
-	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
 )
 
 // runDelete handles the 'crumbler delete' command.
 // It deletes the current crumb (marks work as done).
 func runDelete(args []string) error {
-	// Handle help flag
-	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
-		printDeleteHelp()
-		return nil
-	}
-
-	projectRoot, err := getProjectRoot()
-	if err != nil {
-		return err
+	var skipReason string
+	var verifyTimeout time.Duration
+
+	// Parse flags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h", "help":
+			printDeleteHelp()
+			return nil
+		case "--skip-verify":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return fmt.Errorf("--skip-verify requires a reason\n\nUsage: crumbler delete --skip-verify \"reason\"")
+			}
+			i++
+			skipReason = args[i]
+		case "--verify-timeout":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--verify-timeout requires a duration (e.g. 5m)")
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid --verify-timeout %q: expected a duration like 90s or 5m", args[i])
+			}
+			verifyTimeout = d
+		default:
+			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler delete --help' for usage", args[i])
+		}
 	}
 
-	// Get current crumb for display
-	current, err := crumb.GetCurrent(projectRoot)
+	project, err := openProject()
 	if err != nil {
 		return err
 	}
 
-	if current == nil {
+	// Verify, run the delete hooks and delete the current crumb
+	result, err := project.Delete(crumbler.DeleteOptions{
+		SkipVerifyReason: skipReason,
+		VerifyTimeout:    verifyTimeout,
+		OnVerify: func(command string) {
+			fmt.Printf("Verifying: %s\n", command)
+		},
+	})
+	if errors.Is(err, crumbler.ErrNoCrumb) {
 		fmt.Println("No crumbs to delete. Project is done!")
 		return nil
 	}
-
-	relPath := relPath(projectRoot, current.Path)
-
-	// Delete the crumb
-	if err := crumb.Delete(projectRoot); err != nil {
-		return fmt.Errorf("failed to delete crumb: %w", err)
-	}
-
-	fmt.Printf("Deleted crumb: %s\n", relPath)
-
-	// Check if project is now done
-	done, err := crumb.IsDone(projectRoot)
 	if err != nil {
+		printVerifyFailure(err)
 		return err
 	}
 
-	if done {
+	if len(result.Skipped) > 0 {
+		fmt.Printf("Skipped verification (%s): %s\n", skipReason, strings.Join(result.Skipped, "; "))
+	}
+	fmt.Printf("Deleted crumb: %s\n", result.Deleted.RelPath)
+
+	if result.Done {
 		fmt.Println("\nAll crumbs completed. Project is done!")
 	} else {
 		fmt.Println("\nRun 'crumbler prompt' for next task.")
@@ -55,12 +79,26 @@ func runDelete(args []string) error {
 	return nil
 }
 
+// printVerifyFailure prints the failing command's output if err is a
+// verification failure, so the agent can see why.
+func printVerifyFailure(err error) {
+	var verr *crumbler.VerifyError
+	if !errors.As(err, &verr) {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\nVerification failed: %s (%v)\n\n", verr.Command, verr.Err)
+	if output := strings.TrimRight(verr.Output, "\n"); output != "" {
+		fmt.Fprintln(os.Stderr, output)
+	}
+	fmt.Fprintln(os.Stderr, "\nCrumb not deleted. Fix the failures, or override with --skip-verify \"reason\".")
+}
+
 // printDeleteHelp prints help for the delete command.
 func printDeleteHelp() {
 	fmt.Print(`crumbler delete - Delete the current crumb
 
 USAGE:
-    crumbler delete
+    crumbler delete [flags]
 
 DESCRIPTION:
     Deletes the current crumb, marking its work as complete. The current
@@ -71,11 +109,29 @@ DESCRIPTION:
 
     You cannot delete a crumb that has children. Complete child crumbs first.
 
+VERIFICATION:
+    A crumb or any of its ancestors can declare verification commands in a
+    VERIFY file next to its README.md, one shell command per line (lines
+    starting with # are ignored). Before deleting, crumbler runs the
+    ancestors' commands (outermost first) and then the crumb's own, from
+    the project root. If any command fails or times out, the crumb is not
+    deleted and the command's output is printed.
+
+FLAGS:
+    --skip-verify REASON      Delete without verifying; the reason is
+                              recorded in .crumbler-verify-skips.jsonl
+    --verify-timeout DURATION Timeout per verification command (default 10m)
+
 WORKFLOW:
     1. Execute the work described in the crumb's README
     2. Run 'crumbler delete' to mark the work as done
     3. Run 'crumbler prompt' to get the next task
 
+HOOKS:
+    Runs .crumbler/hooks/pre-delete after verification and before deleting
+    (a non-zero exit aborts) and .crumbler/hooks/post-delete afterwards.
+    See 'crumbler help hooks'.
+
 CONSTRAINTS:
     - Cannot delete a crumb with children
     - Cannot delete the root .crumbler directory
@@ -84,12 +140,19 @@ EXAMPLES:
     # Delete current crumb after completing its work
     crumbler delete
 
+    # Declare verification for a crumb and everything below it
+    echo "go test ./..." > .crumbler/01-setup/VERIFY
+
+    # Override a failing verification (reason is recorded)
+    crumbler delete --skip-verify "upstream outage, tests verified manually"
+
     # Check what's next
     crumbler prompt
 
 ERRORS:
     - "no crumb to delete" - No crumbs exist
     - "cannot delete crumb with children" - Must delete children first
+    - "verification failed" - A verification command failed or timed out
     - "not a crumbler project" - No .crumbler directory found
 `)
 }
@@ -0,0 +1,54 @@
+package crumbler
+
+import "fmt"
+
+// printHooksHelp prints help for lifecycle hooks ('crumbler help hooks').
+func printHooksHelp() {
+	fmt.Print(`crumbler hooks - Run your own automation around crumbler operations
+
+DESCRIPTION:
+    Executable files in .crumbler/hooks/ run before and after crumbler
+    operations, so you can post to a log, regenerate docs or format READMEs
+    without wrapping the binary. The hooks directory is never treated as a
+    crumb.
+
+HOOKS:
+    pre-create     Before 'crumbler create' (crumb = parent of new crumbs)
+    post-create    After 'crumbler create'
+    pre-delete     Before 'crumbler delete', after verification passes
+    post-delete    After 'crumbler delete' (crumb = the deleted crumb)
+    pre-prompt     Before 'crumbler prompt'
+
+    A non-zero exit from a pre-hook aborts the operation. A failing
+    post-hook only prints a warning. Hook output goes to stderr.
+    post-delete cannot run when the root crumb is deleted, since
+    .crumbler/hooks/ is deleted with it.
+
+    A named plan has its own hooks in .crumbler-plans/<name>/.crumbler/hooks/.
+    They run in the project directory, like the default plan's, and see
+    crumb paths relative to it.
+
+ENVIRONMENT:
+    CRUMBLER_HOOK            Hook event (e.g. pre-create)
+    CRUMBLER_PROJECT_ROOT    Project root directory
+    CRUMBLER_CRUMB_PATH      Crumb path relative to the project root
+    CRUMBLER_CRUMB_STATE     DECOMPOSE, EXECUTE or DONE
+
+STDIN:
+    A JSON payload with the same information:
+    {"event":"pre-create","project_root":"/src/app",
+     "crumb":".crumbler/01-setup","state":"DECOMPOSE",
+     "names":["Add Auth"]}
+
+    pre-create includes "names" (names about to be created) and
+    post-create includes "created" (paths of the created crumbs).
+
+EXAMPLE:
+    mkdir -p .crumbler/hooks
+    cat > .crumbler/hooks/post-delete <<'HOOK'
+    #!/bin/sh
+    echo "done $CRUMBLER_CRUMB_PATH" >> crumbler.log
+    HOOK
+    chmod +x .crumbler/hooks/post-delete
+`)
+}
@@ -0,0 +1,63 @@
+package crumbler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/waynenilsen/crumbler/internal/mcp"
+)
+
+// runMCP handles the 'crumbler mcp' command.
+// It serves crumbler tools over the Model Context Protocol on stdio.
+func runMCP(args []string) error {
+	// Handle help flag
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
+		printMCPHelp()
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler mcp --help' for usage", args[0])
+	}
+
+	project, err := openProject()
+	if err != nil {
+		return err
+	}
+
+	return mcp.New(project).Serve(os.Stdin, os.Stdout)
+}
+
+// printMCPHelp prints help for the mcp command.
+func printMCPHelp() {
+	fmt.Print(`crumbler mcp - Model Context Protocol server
+
+USAGE:
+    crumbler mcp
+
+DESCRIPTION:
+    Speaks the Model Context Protocol (JSON-RPC 2.0, one message per line)
+    over stdin/stdout, so MCP-capable agents can use crumbler as native
+    tools instead of shelling out and parsing text. Hook output and
+    warnings go to stderr.
+
+TOOLS:
+    get_prompt     The agent prompt for the current crumb, with its state
+    status         The crumb tree, count and current crumb
+    create         Create sibling sub-crumbs, each with README content
+    write_readme   Replace a crumb's README (default: current crumb)
+    delete         Delete the current crumb (runs VERIFY commands and hooks)
+    note           Append a note to a crumb's README (default: current crumb)
+
+    Results are returned as structured content. Failed calls set isError
+    and report an error code: no_crumb, has_children, directory_full,
+    invalid_name, not_found, exists, verification_failed, hook_failed,
+    invalid_arguments or internal.
+
+EXAMPLES:
+    # Register with Claude Code
+    claude mcp add crumbler -- crumbler mcp
+
+    # Generic MCP client configuration
+    {"mcpServers": {"crumbler": {"command": "crumbler", "args": ["mcp"]}}}
+`)
+}
@@ -0,0 +1,100 @@
+package crumbler
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// planEnv selects a named plan; the global --plan flag sets it too.
+const planEnv = "CRUMBLER_PLAN"
+
+// selectedPlan returns the name of the selected plan.
+func selectedPlan() string {
+	if name := os.Getenv(planEnv); name != "" {
+		return name
+	}
+	return crumb.DefaultPlan
+}
+
+// runPlans handles the 'crumbler plans' command.
+// It lists the default plan and every named plan with its state.
+func runPlans(args []string) error {
+	// Handle help flag
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
+		printPlansHelp()
+		return nil
+	}
+	if len(args) > 0 {
+		return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler plans --help' for usage", args[0])
+	}
+
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return err
+	}
+	names, err := crumb.ListPlans(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PLAN\tCRUMBS\tCURRENT")
+	for _, name := range append([]string{crumb.DefaultPlan}, names...) {
+		root, err := crumb.PlanRoot(projectRoot, name)
+		if err != nil {
+			return err
+		}
+		snap, err := crumb.TakeSnapshot(root)
+		if err != nil {
+			return err
+		}
+		count, current := snap.Count(), snap.Current()
+
+		marker := " "
+		if name == selectedPlan() {
+			marker = "*"
+		}
+		state := crumb.StateDone
+		if current != nil {
+			state = fmt.Sprintf("%s (%s)", current.RelPath, current.State())
+		}
+		fmt.Fprintf(w, "%s %s\t%d\t%s\n", marker, name, count, state)
+	}
+	return w.Flush()
+}
+
+// printPlansHelp prints help for the plans command.
+func printPlansHelp() {
+	fmt.Print(`crumbler plans - List named plans
+
+USAGE:
+    crumbler plans
+
+DESCRIPTION:
+    A project can run several independent plans side by side, e.g. a
+    feature plan and a tech-debt plan. Each plan has its own crumb tree
+    and its own DONE state:
+
+        .crumbler/                       # The default plan
+        .crumbler-plans/<name>/.crumbler/  # Named plans
+
+    Select a plan for any command with the global --plan flag or the
+    CRUMBLER_PLAN environment variable. A named plan is created the first
+    time you create a crumb in it; other commands fail on a plan that
+    doesn't exist, so a mistyped name isn't taken for a finished plan.
+    Crumb paths are still shown relative to the project root, and
+    verification commands run there.
+
+    This command lists every plan with its crumb count and current crumb.
+    The selected plan is marked with *.
+
+EXAMPLES:
+    crumbler --plan debt create "Remove dead code"
+    crumbler --plan debt prompt
+    CRUMBLER_PLAN=debt crumbler delete
+    crumbler plans
+`)
+}
@@ -3,13 +3,13 @@ package crumbler
 import (
 	"fmt"
 
-	"github.com/waynenilsen/crumbler/internal/prompt"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
 )
 
 // runPrompt handles the 'crumbler prompt' command.
 // It generates the AI agent prompt based on current crumb.
 func runPrompt(args []string) error {
-	config := &prompt.Config{}
+	var opts crumbler.PromptOptions
 
 	// Parse flags
 	for _, arg := range args {
@@ -18,27 +18,27 @@ func runPrompt(args []string) error {
 			printPromptHelp()
 			return nil
 		case "--no-preamble":
-			config.NoPreamble = true
+			opts.NoPreamble = true
 		case "--no-postamble":
-			config.NoPostamble = true
+			opts.NoPostamble = true
 		case "--no-context":
-			config.NoContext = true
+			opts.NoContext = true
 		case "--minimal":
-			config.Minimal = true
+			opts.Minimal = true
 		default:
 			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler prompt --help' for usage", arg)
 		}
 	}
 
-	projectRoot, err := getProjectRoot()
+	project, err := openProject()
 	if err != nil {
 		return err
 	}
 
-	// Generate the prompt
-	output, err := prompt.GeneratePrompt(projectRoot, config)
+	// Generate the prompt (a failing pre-prompt hook aborts it)
+	output, err := project.Prompt(opts)
 	if err != nil {
-		return fmt.Errorf("failed to generate prompt: %w", err)
+		return err
 	}
 
 	fmt.Print(output)
@@ -73,6 +73,10 @@ EXAMPLES:
     # Get prompt without context
     crumbler prompt --no-context
 
+HOOKS:
+    Runs .crumbler/hooks/pre-prompt before generating the prompt (a non-zero
+    exit aborts). Hook output goes to stderr. See 'crumbler help hooks'.
+
 AGENT LOOP:
     The typical agent loop is:
     1. crumbler prompt          # Get instructions
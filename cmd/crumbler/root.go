@@ -2,11 +2,14 @@
 package crumbler
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
 )
 
 // Execute is the main entry point for the CLI, called from main.go.
@@ -25,6 +28,24 @@ func Execute() error {
 		printTopLevelHelp()
 		return nil
 	}
+	for len(args) > 0 && (args[0] == "--plan" || strings.HasPrefix(args[0], "--plan=")) {
+		name := strings.TrimPrefix(args[0], "--plan=")
+		if args[0] == "--plan" {
+			if len(args) < 2 {
+				return fmt.Errorf("--plan requires a plan name")
+			}
+			name = args[1]
+			args = args[1:]
+		}
+		args = args[1:]
+		// Export the selection so hooks and verification commands that
+		// call crumbler again stay on the same plan
+		os.Setenv(planEnv, name)
+	}
+	if len(args) == 0 {
+		printTopLevelHelp()
+		return nil
+	}
 
 	// Route to subcommand
 	switch args[0] {
@@ -36,6 +57,8 @@ func Execute() error {
 		return nil
 	case "status":
 		return runStatus(args[1:])
+	case "cost":
+		return runCost(args[1:])
 	case "create":
 		return runCreate(args[1:])
 	case "delete":
@@ -44,6 +67,18 @@ func Execute() error {
 		return runPrompt(args[1:])
 	case "clean":
 		return runClean(args[1:])
+	case "worktree":
+		return runWorktree(args[1:])
+	case "watch":
+		return runWatch(args[1:])
+	case "tui":
+		return runTUI(args[1:])
+	case "serve":
+		return runServe(args[1:])
+	case "mcp":
+		return runMCP(args[1:])
+	case "plans":
+		return runPlans(args[1:])
 	default:
 		fmt.Fprintf(os.Stderr, "error: unknown command '%s'\n\n", args[0])
 		printTopLevelHelp()
@@ -56,6 +91,8 @@ func runHelpFor(cmd string) error {
 	switch cmd {
 	case "status":
 		return runStatus([]string{"--help"})
+	case "cost":
+		return runCost([]string{"--help"})
 	case "create":
 		return runCreate([]string{"--help"})
 	case "delete":
@@ -64,11 +101,51 @@ func runHelpFor(cmd string) error {
 		return runPrompt([]string{"--help"})
 	case "clean":
 		return runClean([]string{"--help"})
+	case "worktree":
+		return runWorktree([]string{"--help"})
+	case "watch":
+		return runWatch([]string{"--help"})
+	case "tui":
+		return runTUI([]string{"--help"})
+	case "serve":
+		return runServe([]string{"--help"})
+	case "mcp":
+		return runMCP([]string{"--help"})
+	case "plans":
+		return runPlans([]string{"--help"})
+	case "hooks":
+		printHooksHelp()
+		return nil
 	default:
 		return fmt.Errorf("unknown command: %s", cmd)
 	}
 }
 
+// exitError is an error that ends the process with a specific status.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// ExitCode returns the process exit status for an error returned by
+// Execute: 1, unless the command chose another (such as 3 from
+// 'crumbler clean --enforce-budget').
+func ExitCode(err error) int {
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	return 1
+}
+
 // printTopLevelHelp prints the top-level help message.
 func printTopLevelHelp() {
 	fmt.Print(`crumbler - Simple Task Decomposition for AI Agents
@@ -82,14 +159,22 @@ USAGE:
 
 COMMANDS:
     status    Show crumb tree and current state
+    cost      Show agent token usage and cost per crumb
+    watch     Live view of the crumb tree
+    tui       Interactive browser for reviewing and editing the plan
+    serve     Read-only web dashboard
+    mcp       Serve crumbler tools over the Model Context Protocol
+    plans     List named plans
     create    Create a new sub-crumb (auto-initializes if needed)
     delete    Delete the current crumb (mark work as done)
     prompt    Generate AI agent prompt for current state
     clean     Format Claude Code streaming JSON output
+    worktree  Work leaf crumbs in parallel git worktrees
     help      Show help for a command
 
 FLAGS:
-    -h, --help  Show this help message
+    -h, --help   Show this help message
+    --plan NAME  Work on a named plan instead of .crumbler/ (also CRUMBLER_PLAN)
 
 WORKFLOW:
     1. crumbler create "Task"     # Create first crumb (auto-inits)
@@ -105,10 +190,12 @@ EXAMPLES:
     crumbler status                      # View crumb tree
     crumbler delete                      # Mark done
     crumbler help create                 # Get command help
+    crumbler help hooks                  # Lifecycle hooks in .crumbler/hooks/
 
 STRUCTURE:
     .crumbler/                           # Project root (auto-created)
     ├── README.md                        # Root crumb
+    ├── hooks/                           # Optional lifecycle hooks
     ├── 01-setup/                        # First child crumb
     │   ├── README.md                    # Task instructions
     │   └── 01-database/                 # Nested crumb
@@ -120,21 +207,22 @@ For more information: crumbler help <command>
 `)
 }
 
-// findProjectRoot locates the .crumbler directory by walking up from pwd.
-// If .crumbler exists, returns path to directory containing it.
-// If .crumbler doesn't exist, returns current working directory.
+// findProjectRoot locates the project by walking up from pwd.
+// If .crumbler or .crumbler-plans exists, returns path to directory containing it.
+// If neither exists, returns current working directory.
 func findProjectRoot() (string, error) {
 	dir, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("failed to get current directory: %w", err)
 	}
 
-	// Walk up to find existing .crumbler
+	// Walk up to find existing .crumbler (or named plans)
 	searchDir := dir
 	for {
-		crumblerDir := filepath.Join(searchDir, crumb.CrumblerDir)
-		if info, err := os.Stat(crumblerDir); err == nil && info.IsDir() {
-			return searchDir, nil
+		for _, name := range []string{crumb.CrumblerDir, crumb.PlansDir} {
+			if info, err := os.Stat(filepath.Join(searchDir, name)); err == nil && info.IsDir() {
+				return searchDir, nil
+			}
 		}
 
 		parent := filepath.Dir(searchDir)
@@ -146,9 +234,35 @@ func findProjectRoot() (string, error) {
 	}
 }
 
-// getProjectRoot returns the project root (cwd or directory with .crumbler).
+// getProjectRoot returns the root of the selected plan: the project root
+// (cwd or directory with .crumbler) for the default plan, or the named
+// plan's directory under .crumbler-plans/ when --plan or CRUMBLER_PLAN is set.
+// A named plan must exist, so a typo isn't taken for a finished plan.
 func getProjectRoot() (string, error) {
-	return findProjectRoot()
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return "", err
+	}
+	return crumb.ExistingPlanRoot(projectRoot, os.Getenv(planEnv))
+}
+
+// openProject opens the selected plan of the project (see getProjectRoot).
+func openProject() (*crumbler.Project, error) {
+	root, err := getProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	return crumbler.Open(root)
+}
+
+// openNewProject is openProject for create, which starts a named plan
+// that doesn't exist yet.
+func openNewProject() (*crumbler.Project, error) {
+	projectRoot, err := findProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	return crumbler.OpenPlan(projectRoot, os.Getenv(planEnv))
 }
 
 // crumblerDir returns the path to the .crumbler directory.
@@ -156,9 +270,10 @@ func crumblerDir(projectRoot string) string {
 	return filepath.Join(projectRoot, crumb.CrumblerDir)
 }
 
-// relPath returns a path relative to the project root.
+// relPath returns a path relative to the project root. For a named plan
+// that is the project containing .crumbler-plans/, not the plan's root.
 func relPath(projectRoot, fullPath string) string {
-	rel, err := filepath.Rel(projectRoot, fullPath)
+	rel, err := filepath.Rel(crumb.ProjectRoot(projectRoot), fullPath)
 	if err != nil {
 		return fullPath
 	}
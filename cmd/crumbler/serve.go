@@ -0,0 +1,119 @@
+package crumbler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/server"
+)
+
+// runServe handles the 'crumbler serve' command.
+// It serves a read-only web dashboard until interrupted.
+func runServe(args []string) error {
+	addr := server.DefaultAddr
+	interval := server.DefaultInterval
+
+	// Parse flags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h", "help":
+			printServeHelp()
+			return nil
+		case "--addr":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--addr requires an address (e.g. 127.0.0.1:8080)")
+			}
+			i++
+			addr = args[i]
+		case "-n", "--interval":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a duration (e.g. 2s)", args[i])
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid interval %q: expected a duration like 500ms or 2s", args[i])
+			}
+			interval = d
+		default:
+			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler serve --help' for usage", args[i])
+		}
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	srv := &http.Server{
+		Handler: server.New(projectRoot, interval),
+		// Event streams stay open, so cancel them when shutting down
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	fmt.Printf("Serving crumbler dashboard on http://%s (Ctrl-C to stop)\n", listener.Addr())
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// printServeHelp prints help for the serve command.
+func printServeHelp() {
+	fmt.Print(`crumbler serve - Read-only web dashboard
+
+USAGE:
+    crumbler serve [flags]
+
+DESCRIPTION:
+    Serves an HTML dashboard showing the crumb tree, the current crumb,
+    rendered READMEs and recently completed crumbs from git history.
+    Open pages update live via server-sent events when the tree changes.
+
+    The dashboard is read-only: only GET requests are accepted and nothing
+    under .crumbler/ is modified. It listens on localhost by default; pass
+    --addr to expose it to your network.
+
+ENDPOINTS:
+    /            Dashboard page
+    /api/state   Dashboard state as JSON
+    /events      Server-sent events ("tree") on every change
+
+FLAGS:
+    --addr ADDR               Listen address (default 127.0.0.1:8080)
+    -n, --interval DURATION   How often to check for changes (default 1s)
+
+EXAMPLES:
+    crumbler serve
+    crumbler serve --addr 0.0.0.0:9000
+`)
+}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 
 	"github.com/waynenilsen/crumbler/internal/crumb"
-	"github.com/waynenilsen/crumbler/internal/prompt"
+	"github.com/waynenilsen/crumbler/internal/worktree"
 )
 
 // runStatus handles the 'crumbler status' command.
@@ -16,47 +16,79 @@ func runStatus(args []string) error {
 		return nil
 	}
 
-	projectRoot, err := getProjectRoot()
-	if err != nil {
-		return err
+	var showCost bool
+	for _, arg := range args {
+		switch arg {
+		case "--cost":
+			showCost = true
+		default:
+			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler status --help' for usage", arg)
+		}
 	}
 
-	// Get crumb count
-	count, err := crumb.Count(projectRoot)
+	project, err := openProject()
 	if err != nil {
 		return err
 	}
 
-	// Get the tree
-	tree, err := crumb.List(projectRoot)
+	// Read the tree, count and current crumb in one walk
+	snap, err := project.Snapshot()
 	if err != nil {
 		return err
 	}
+	count := snap.Count()
+	tree := snap.List()
 
 	// Print header
+	if plan := selectedPlan(); plan != crumb.DefaultPlan {
+		fmt.Printf("Plan: %s\n", plan)
+	}
+	var costs *costReport
+	if showCost {
+		if costs, err = newCostReport(project.Root(), tree); err != nil {
+			return err
+		}
+	}
 	if count == 0 {
 		fmt.Println("Project Status: DONE (no crumbs remaining)")
+		if costs != nil {
+			fmt.Printf("Spent: %s\n", formatCost(costs.total()))
+		}
 		return nil
 	}
 
 	fmt.Printf("Project Status: %d crumb(s) remaining\n\n", count)
 
-	// Get current crumb
-	current, err := crumb.GetCurrent(projectRoot)
-	if err != nil {
-		return err
-	}
-
 	// Print tree with current crumb marked
+	current := snap.Current()
 	currentPath := ""
 	if current != nil {
 		currentPath = current.Path
 	}
-	fmt.Println(prompt.FormatTreeWithCurrent(tree, "", false, currentPath))
+	if costs != nil {
+		fmt.Println(tree.TreeWithLabels(costs.label(currentPath)))
+	} else {
+		fmt.Println(tree.Tree(currentPath))
+	}
 
 	if current != nil {
 		fmt.Printf("Current: %s\n", current.RelPath)
 	}
+	if costs != nil {
+		fmt.Printf("Spent: %s\n", formatCost(costs.total()))
+	}
+
+	// Show leaves checked out in worktrees (ignored outside a git repository
+	// and for named plans, which worktrees don't support)
+	if selectedPlan() != crumb.DefaultPlan {
+		return nil
+	}
+	if worktrees, err := worktree.List(project.Root()); err == nil && len(worktrees) > 0 {
+		fmt.Println("\nWorktrees:")
+		for _, wt := range worktrees {
+			fmt.Printf("  %s → %s (%s)\n", wt.Crumb, wt.Path, wt.Branch)
+		}
+	}
 
 	return nil
 }
@@ -66,13 +98,19 @@ func printStatusHelp() {
 	fmt.Print(`crumbler status - Show project status
 
 USAGE:
-    crumbler status
+    crumbler status [--cost]
 
 DESCRIPTION:
     Displays the current state of the crumbler project including:
     - Total number of remaining crumbs
     - Tree view of all crumbs
     - Current crumb (marked with arrow)
+    - Leaf crumbs checked out in git worktrees (see 'crumbler worktree')
+
+OPTIONS:
+    --cost       Show recorded agent cost next to each crumb, including
+                 deleted descendants (see 'crumbler cost')
+    -h, --help   Show this help message
 
 OUTPUT:
     Tree view shows the crumb hierarchy with the current crumb marked.
@@ -80,6 +118,7 @@ OUTPUT:
 
 EXAMPLES:
     crumbler status
+    crumbler status --cost
 
 OUTPUT EXAMPLE:
     Project Status: 3 crumb(s) remaining
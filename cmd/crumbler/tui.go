@@ -0,0 +1,160 @@
+package crumbler
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/term"
+
+	"github.com/waynenilsen/crumbler/internal/tui"
+)
+
+const (
+	// enterAltScreen switches to the alternate screen and hides the cursor.
+	enterAltScreen = "\033[?1049h\033[?25l"
+	// exitAltScreen shows the cursor and restores the main screen.
+	exitAltScreen = "\033[?25h\033[?1049l"
+)
+
+// runTUI handles the 'crumbler tui' command.
+// It opens an interactive browser over the crumb tree.
+func runTUI(args []string) error {
+	// Handle help flag
+	if len(args) > 0 && (args[0] == "--help" || args[0] == "-h" || args[0] == "help") {
+		printTUIHelp()
+		return nil
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("crumbler tui requires an interactive terminal")
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	model, err := tui.New(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	oldState, err := term.MakeRaw(stdin)
+	if err != nil {
+		return fmt.Errorf("failed to enter raw mode: %w", err)
+	}
+	fmt.Print(enterAltScreen)
+	defer func() {
+		fmt.Print(exitAltScreen)
+		term.Restore(stdin, oldState)
+	}()
+
+	buf := make([]byte, 64)
+	for {
+		if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			model.Width, model.Height = width, height
+		}
+		draw(model.View())
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return nil
+		}
+
+		for _, key := range tui.ParseKeys(buf[:n]) {
+			switch model.Update(key) {
+			case tui.CommandQuit:
+				return nil
+			case tui.CommandEdit:
+				// Hand the terminal to the editor, then pick up its changes
+				fmt.Print(exitAltScreen)
+				term.Restore(stdin, oldState)
+				editErr := runEditor(model.EditPath)
+				if oldState, err = term.MakeRaw(stdin); err != nil {
+					return fmt.Errorf("failed to enter raw mode: %w", err)
+				}
+				fmt.Print(enterAltScreen)
+				if editErr != nil {
+					return editErr
+				}
+				if err := model.Reload(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// draw redraws the whole screen. In raw mode "\n" does not return the
+// cursor to column 0, so lines are joined with "\r\n".
+func draw(view string) {
+	out := []byte("\033[H")
+	for i := 0; i < len(view); i++ {
+		if view[i] == '\n' {
+			out = append(out, "\033[K\r\n"...)
+			continue
+		}
+		out = append(out, view[i])
+	}
+	out = append(out, "\033[K\033[J"...)
+	os.Stdout.Write(out)
+}
+
+// runEditor opens path in $VISUAL or $EDITOR (default vi).
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	// Run through the shell so editors with arguments ("code -w") work
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", editor+" "+path)
+	} else {
+		cmd = exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+	return nil
+}
+
+// printTUIHelp prints help for the tui command.
+func printTUIHelp() {
+	fmt.Print(`crumbler tui - Interactive browser for the crumb tree
+
+USAGE:
+    crumbler tui
+
+DESCRIPTION:
+    Opens an interactive tree browser for reviewing and editing a plan,
+    with a README preview pane for the selected crumb. Every change goes
+    through the same operations as the CLI, so the 10-child limit and
+    name validation still hold.
+
+KEYS:
+    ↑/k ↓/j      Move the cursor
+    →/l ←/h      Expand / collapse (← on a leaf jumps to its parent)
+    enter/space  Toggle expand
+    K / J        Move the crumb up / down among its siblings (swaps IDs)
+    r            Rename the crumb (keeps its ID)
+    a            Add a child crumb
+    d            Delete the crumb and all its descendants (asks y/n)
+    e            Edit the README in $VISUAL or $EDITOR (default vi)
+    q            Quit
+
+EXAMPLES:
+    crumbler tui
+    EDITOR="code -w" crumbler tui
+`)
+}
@@ -0,0 +1,251 @@
+package crumbler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/prompt"
+)
+
+const (
+	// defaultWatchInterval is how often 'crumbler watch' polls .crumbler/.
+	defaultWatchInterval = time.Second
+	// watchHighlight is how long new and deleted crumbs stay highlighted.
+	watchHighlight = 30 * time.Second
+	// clearScreen moves the cursor home and clears the terminal.
+	clearScreen = "\033[H\033[2J"
+)
+
+// runWatch handles the 'crumbler watch' command.
+// It polls .crumbler/ and redraws the tree whenever anything changes.
+func runWatch(args []string) error {
+	interval := defaultWatchInterval
+
+	// Parse flags
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h", "help":
+			printWatchHelp()
+			return nil
+		case "-n", "--interval":
+			if i+1 >= len(args) {
+				return fmt.Errorf("%s requires a duration (e.g. 2s)", args[i])
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid interval %q: expected a duration like 500ms or 2s", args[i])
+			}
+			interval = d
+		default:
+			return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler watch --help' for usage", args[i])
+		}
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	state := newWatchState()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		snap, err := crumb.TakeSnapshot(projectRoot)
+		if err != nil {
+			return err
+		}
+		tree, current := snap.List(), snap.Current()
+
+		now := time.Now()
+		state.update(tree, current, now)
+		fmt.Print(clearScreen + state.render(now))
+
+		// The whole .crumbler directory vanishes when the project completes
+		if tree == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchState tracks changes between polls of the crumb tree.
+type watchState struct {
+	tree         *crumb.Crumb
+	current      *crumb.Crumb
+	currentSince time.Time
+	seen         map[string]bool      // RelPaths from the previous poll
+	created      map[string]time.Time // RelPath → when it appeared
+	deleted      []deletedCrumb       // Most recent first
+	started      bool
+}
+
+// deletedCrumb is a crumb that disappeared between polls.
+type deletedCrumb struct {
+	relPath string
+	at      time.Time
+}
+
+// newWatchState returns an empty watch state.
+func newWatchState() *watchState {
+	return &watchState{
+		seen:    make(map[string]bool),
+		created: make(map[string]time.Time),
+	}
+}
+
+// update records a new poll of the tree.
+func (w *watchState) update(tree, current *crumb.Crumb, now time.Time) {
+	paths := make(map[string]bool)
+	collectRelPaths(tree, paths)
+
+	// Nothing is new on the first poll
+	if w.started {
+		for path := range paths {
+			if !w.seen[path] {
+				w.created[path] = now
+			}
+		}
+		var gone []string
+		for path := range w.seen {
+			if !paths[path] {
+				gone = append(gone, path)
+			}
+		}
+		// Deepest first, matching the order crumbs are completed
+		sort.Sort(sort.Reverse(sort.StringSlice(gone)))
+		for _, path := range gone {
+			delete(w.created, path)
+			w.deleted = append([]deletedCrumb{{relPath: path, at: now}}, w.deleted...)
+		}
+	}
+
+	if current == nil || w.current == nil || current.Path != w.current.Path {
+		w.currentSince = now
+	}
+
+	w.tree = tree
+	w.current = current
+	w.seen = paths
+	w.started = true
+}
+
+// render returns the watch screen contents.
+func (w *watchState) render(now time.Time) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("crumbler watch - %s (Ctrl-C to exit)\n\n", now.Format("15:04:05")))
+
+	if w.tree == nil {
+		sb.WriteString("Project Status: DONE (no crumbs remaining)\n")
+	} else {
+		count := len(w.seen)
+		sb.WriteString(fmt.Sprintf("Project Status: %d crumb(s) remaining\n\n", count))
+		sb.WriteString(prompt.FormatTreeWithLabels(w.tree, func(c *crumb.Crumb) string {
+			return w.label(c, now)
+		}))
+		if w.current != nil {
+			sb.WriteString(fmt.Sprintf("\nCurrent: %s [%s, %s]\n",
+				w.current.RelPath, w.current.State(), formatElapsed(now.Sub(w.currentSince))))
+		}
+	}
+
+	var recent []deletedCrumb
+	for _, d := range w.deleted {
+		if now.Sub(d.at) <= watchHighlight {
+			recent = append(recent, d)
+		}
+	}
+	if len(recent) > 0 {
+		sb.WriteString("\nJust completed:\n")
+		for _, d := range recent {
+			sb.WriteString(fmt.Sprintf("  ✓ %s (%s ago)\n", d.relPath, formatElapsed(now.Sub(d.at))))
+		}
+	}
+
+	return sb.String()
+}
+
+// label returns the annotations for a crumb in the watch tree.
+func (w *watchState) label(c *crumb.Crumb, now time.Time) string {
+	var label string
+	if w.current != nil && c.Path == w.current.Path {
+		label += " ← current"
+	}
+	if at, ok := w.created[c.RelPath]; ok && now.Sub(at) <= watchHighlight {
+		label += " + new"
+	}
+	return label
+}
+
+// collectRelPaths adds the RelPath of every crumb in the tree to paths.
+func collectRelPaths(c *crumb.Crumb, paths map[string]bool) {
+	if c == nil {
+		return
+	}
+	paths[c.RelPath] = true
+	for i := range c.Children {
+		collectRelPaths(&c.Children[i], paths)
+	}
+}
+
+// formatElapsed formats a duration as a short human-readable string.
+func formatElapsed(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// printWatchHelp prints help for the watch command.
+func printWatchHelp() {
+	fmt.Print(`crumbler watch - Live view of the crumb tree
+
+USAGE:
+    crumbler watch [flags]
+
+DESCRIPTION:
+    Polls .crumbler/ and redraws the tree whenever anything changes, so you
+    can follow an agent loop from another terminal instead of re-running
+    'crumbler status'.
+
+    - The current crumb is marked with its state (DECOMPOSE or EXECUTE)
+      and how long it has been current since watch started observing
+    - Newly created crumbs are marked "+ new"
+    - Just-deleted crumbs are listed under "Just completed"
+
+    When the whole .crumbler directory disappears (the project is done),
+    watch shows the final state and exits.
+
+FLAGS:
+    -n, --interval DURATION   Poll interval (default 1s)
+
+EXAMPLES:
+    # In one terminal
+    while true; do claude --print "$(crumbler prompt)"; done
+
+    # In another
+    crumbler watch
+    crumbler watch -n 250ms
+`)
+}
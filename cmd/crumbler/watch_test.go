@@ -0,0 +1,107 @@
+package crumbler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// watchTree builds a tree with the given leaf children under .crumbler.
+func watchTree(names ...string) *crumb.Crumb {
+	root := &crumb.Crumb{Path: "/p/.crumbler", RelPath: ".crumbler"}
+	for i, name := range names {
+		id := fmt.Sprintf("%02d", i+1)
+		root.Children = append(root.Children, crumb.Crumb{
+			Path:    "/p/.crumbler/" + id + "-" + name,
+			RelPath: ".crumbler/" + id + "-" + name,
+			ID:      id,
+			Name:    name,
+			IsLeaf:  true,
+		})
+	}
+	root.IsLeaf = len(root.Children) == 0
+	return root
+}
+
+func TestWatchState(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	state := newWatchState()
+
+	tree := watchTree("setup")
+	state.update(tree, &tree.Children[0], start)
+	screen := state.render(start)
+	if strings.Contains(screen, "+ new") {
+		t.Error("nothing should be new on the first poll")
+	}
+	if !strings.Contains(screen, "01-setup/ ← current") {
+		t.Errorf("expected current marker, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, "2 crumb(s) remaining") {
+		t.Errorf("expected crumb count, got:\n%s", screen)
+	}
+
+	// A sibling is created
+	tree = watchTree("setup", "features")
+	state.update(tree, &tree.Children[0], start.Add(5*time.Second))
+	screen = state.render(start.Add(65 * time.Second))
+	if strings.Contains(screen, "02-features/ + new") {
+		t.Error("new marker should expire")
+	}
+	screen = state.render(start.Add(6 * time.Second))
+	if !strings.Contains(screen, "02-features/ + new") {
+		t.Errorf("expected new marker, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, "6s]") {
+		t.Errorf("expected elapsed time on current crumb, got:\n%s", screen)
+	}
+
+	// The first crumb is deleted and the sibling becomes current
+	tree = watchTree("features")
+	tree.Children[0].ID = "02"
+	tree.Children[0].Path = "/p/.crumbler/02-features"
+	tree.Children[0].RelPath = ".crumbler/02-features"
+	state.update(tree, &tree.Children[0], start.Add(10*time.Second))
+	screen = state.render(start.Add(12 * time.Second))
+	if !strings.Contains(screen, "✓ .crumbler/01-setup (2s ago)") {
+		t.Errorf("expected deleted crumb, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, "02-features/ ← current + new") {
+		t.Errorf("expected new current crumb, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, "2s]") {
+		t.Errorf("elapsed time should reset when current changes, got:\n%s", screen)
+	}
+
+	// The whole .crumbler directory vanishes
+	state.update(nil, nil, start.Add(20*time.Second))
+	screen = state.render(start.Add(20 * time.Second))
+	if !strings.Contains(screen, "DONE") {
+		t.Errorf("expected DONE, got:\n%s", screen)
+	}
+	if !strings.Contains(screen, "✓ .crumbler/02-features (0s ago)") || !strings.Contains(screen, "✓ .crumbler (0s ago)") {
+		t.Errorf("expected remaining crumbs listed as completed, got:\n%s", screen)
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		d        time.Duration
+		expected string
+	}{
+		{1500 * time.Millisecond, "1s"},
+		{75 * time.Second, "1m15s"},
+		{2*time.Hour + 5*time.Minute, "2h05m"},
+	}
+	for _, tt := range tests {
+		if got := formatElapsed(tt.d); got != tt.expected {
+			t.Errorf("formatElapsed(%v) = %q, want %q", tt.d, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package crumbler
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/worktree"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// runWorktree handles the 'crumbler worktree' command.
+// It routes to the start and finish subcommands.
+func runWorktree(args []string) error {
+	if len(args) == 0 || args[0] == "--help" || args[0] == "-h" || args[0] == "help" {
+		printWorktreeHelp()
+		return nil
+	}
+
+	// Worktree branches are named after crumb paths in the default plan
+	if selectedPlan() != crumb.DefaultPlan {
+		return fmt.Errorf("crumbler worktree only supports the default plan")
+	}
+
+	switch args[0] {
+	case "start":
+		return runWorktreeStart(args[1:])
+	case "finish":
+		return runWorktreeFinish(args[1:])
+	default:
+		return fmt.Errorf("unknown worktree subcommand: %s\n\nRun 'crumbler worktree --help' for usage", args[0])
+	}
+}
+
+// runWorktreeStart handles 'crumbler worktree start <path>'.
+func runWorktreeStart(args []string) error {
+	var path, dir string
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h":
+			printWorktreeHelp()
+			return nil
+		case "--dir":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--dir requires a directory")
+			}
+			i++
+			dir = args[i]
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler worktree --help' for usage", args[i])
+			}
+			path = args[i]
+		}
+	}
+
+	if path == "" {
+		return fmt.Errorf("error: missing crumb path\n\nUsage: crumbler worktree start <path>")
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	wt, err := worktree.Start(projectRoot, resolveCrumbPath(projectRoot, path), dir)
+	if err != nil {
+		return fmt.Errorf("failed to start worktree: %w", err)
+	}
+
+	fmt.Printf("Started worktree for crumb: %s\n", wt.Crumb)
+	fmt.Printf("  Branch:   %s\n", wt.Branch)
+	fmt.Printf("  Worktree: %s\n", wt.Path)
+	fmt.Printf("\nWork the crumb in the worktree, merge %s, then run 'crumbler worktree finish %s'.\n", wt.Branch, wt.Crumb)
+
+	return nil
+}
+
+// runWorktreeFinish handles 'crumbler worktree finish [path]'.
+func runWorktreeFinish(args []string) error {
+	var path, skipReason string
+	var verifyTimeout time.Duration
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--help", "-h":
+			printWorktreeHelp()
+			return nil
+		case "--skip-verify":
+			if i+1 >= len(args) || strings.TrimSpace(args[i+1]) == "" {
+				return fmt.Errorf("--skip-verify requires a reason\n\nUsage: crumbler worktree finish --skip-verify \"reason\"")
+			}
+			i++
+			skipReason = args[i]
+		case "--verify-timeout":
+			if i+1 >= len(args) {
+				return fmt.Errorf("--verify-timeout requires a duration (e.g. 5m)")
+			}
+			i++
+			d, err := time.ParseDuration(args[i])
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid --verify-timeout %q: expected a duration like 90s or 5m", args[i])
+			}
+			verifyTimeout = d
+		default:
+			if strings.HasPrefix(args[i], "-") {
+				return fmt.Errorf("unknown flag: %s\n\nRun 'crumbler worktree --help' for usage", args[i])
+			}
+			path = args[i]
+		}
+	}
+
+	projectRoot, err := getProjectRoot()
+	if err != nil {
+		return err
+	}
+
+	crumbRel := ""
+	if path != "" {
+		crumbRel = relPath(projectRoot, resolveCrumbPath(projectRoot, path))
+	}
+
+	// Delete the crumb the way 'crumbler delete' does, verifying and
+	// running hooks, before the worktree and branch go
+	deleteCrumb := func(root, crumbRel string) error {
+		project, err := crumbler.Open(root)
+		if err != nil {
+			return err
+		}
+		_, err = project.Delete(crumbler.DeleteOptions{
+			Path:             crumbRel,
+			SkipVerifyReason: skipReason,
+			VerifyTimeout:    verifyTimeout,
+			OnVerify: func(command string) {
+				fmt.Printf("Verifying: %s\n", command)
+			},
+		})
+		printVerifyFailure(err)
+		return err
+	}
+
+	wt, err := worktree.Finish(projectRoot, crumbRel, deleteCrumb)
+	if err != nil {
+		return fmt.Errorf("failed to finish worktree: %w", err)
+	}
+
+	fmt.Printf("Removed worktree: %s\n", wt.Path)
+	fmt.Printf("Deleted branch: %s\n", wt.Branch)
+	fmt.Printf("Deleted crumb: %s\n", wt.Crumb)
+
+	return nil
+}
+
+// resolveCrumbPath turns a crumb path given on the command line into a full path.
+// Accepts paths relative to the project root (".crumbler/01-setup") or to
+// the .crumbler directory ("01-setup").
+func resolveCrumbPath(projectRoot, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	path = filepath.Clean(path)
+	if path == crumb.CrumblerDir || strings.HasPrefix(path, crumb.CrumblerDir+string(filepath.Separator)) {
+		return filepath.Join(projectRoot, path)
+	}
+	return filepath.Join(crumblerDir(projectRoot), path)
+}
+
+// printWorktreeHelp prints help for the worktree command.
+func printWorktreeHelp() {
+	fmt.Print(`crumbler worktree - Work leaf crumbs in parallel git worktrees
+
+USAGE:
+    crumbler worktree start <path> [--dir DIR]
+    crumbler worktree finish [path] [--skip-verify REASON] [--verify-timeout DURATION]
+
+DESCRIPTION:
+    Independent leaves in different branches of the tree can be worked at
+    the same time. Each agent gets its own git worktree and branch, so
+    agents never share a checkout.
+
+    start   Creates branch crumb/<path> and a worktree for the leaf crumb.
+            The worktree is created next to the project root unless --dir
+            is given.
+
+    finish  Once the crumb's branch is merged into the main tree, deletes
+            the crumb there like 'crumbler delete' (running its VERIFY
+            commands and delete hooks), then removes the worktree and
+            branch. If the delete fails, both are kept. Run without a path
+            from inside a crumb worktree to finish the crumb it was started
+            for.
+
+ARGUMENTS:
+    path    Crumb path, relative to the project root (.crumbler/01-setup)
+            or to the .crumbler directory (01-setup)
+
+FLAGS:
+    --dir DIR                 Directory for the new worktree (start)
+    --skip-verify REASON      Delete without verifying (finish); the reason
+                              is recorded in .crumbler-verify-skips.jsonl
+    --verify-timeout DURATION Timeout per verification command (finish,
+                              default 10m)
+
+CONSTRAINTS:
+    - Only leaf crumbs can be checked out
+    - A crumb can be checked out in at most one worktree
+    - finish refuses to delete a crumb whose branch is not merged
+
+EXAMPLES:
+    crumbler worktree start 01-setup/02-api
+    # Creates branch crumb/01-setup--02-api and worktree ../app-crumb-01-setup--02-api
+
+    git merge crumb/01-setup--02-api
+    crumbler worktree finish 01-setup/02-api
+
+    crumbler status
+    # Shows which leaves are checked out where
+`)
+}
@@ -2,10 +2,13 @@ module github.com/waynenilsen/crumbler
 
 go 1.25.6
 
-require github.com/ariel-frischer/claude-clean v0.2.0
+require (
+	github.com/ariel-frischer/claude-clean v0.2.0
+	github.com/fatih/color v1.18.0
+	golang.org/x/term v0.38.0
+)
 
 require (
-	github.com/fatih/color v1.18.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.39.0 // indirect
@@ -0,0 +1,104 @@
+package crumb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// BudgetFile declares spending limits for a crumb and everything below it.
+// Each line is "tokens N" (N may end in k or M) or "cost N" in dollars.
+// Blank lines and lines starting with # are ignored. The root crumb's
+// budget applies to the whole project.
+const BudgetFile = "BUDGET"
+
+// Budget is the spending limit declared by one crumb. A zero limit is no
+// limit.
+type Budget struct {
+	Crumb   string  // Declaring crumb, relative to the project root
+	Tokens  int     // All tokens, including cache reads and writes
+	CostUSD float64 // Dollars
+}
+
+// Budgets returns the budgets that apply to a crumb on disk.
+// See Store.Budgets.
+func Budgets(root, crumbPath string) ([]Budget, error) {
+	return disk.Budgets(root, crumbPath)
+}
+
+// Budgets returns the budgets that apply to a crumb: those declared by its
+// ancestors (outermost first), then its own. Spending on the crumb counts
+// against all of them.
+func (s *Store) Budgets(root, crumbPath string) ([]Budget, error) {
+	dirs := crumbAndAncestors(root, crumbPath)
+
+	var budgets []Budget
+	for i := len(dirs) - 1; i >= 0; i-- {
+		content, err := s.fs.ReadFile(filepath.Join(dirs[i], BudgetFile))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", BudgetFile, err)
+		}
+		budget, err := parseBudgetFile(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath(root, filepath.Join(dirs[i], BudgetFile)), err)
+		}
+		budget.Crumb = relPath(root, dirs[i])
+		budgets = append(budgets, budget)
+	}
+
+	return budgets, nil
+}
+
+// parseBudgetFile reads the limits in BUDGET file content.
+func parseBudgetFile(content string) (Budget, error) {
+	var budget Budget
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return Budget{}, fmt.Errorf("line %d: want \"tokens N\" or \"cost N\", got %q", n+1, line)
+		}
+
+		switch key, value := strings.ToLower(fields[0]), fields[1]; key {
+		case "tokens":
+			tokens, err := parseTokenCount(value)
+			if err != nil {
+				return Budget{}, fmt.Errorf("line %d: %w", n+1, err)
+			}
+			budget.Tokens = tokens
+		case "cost":
+			cost, err := strconv.ParseFloat(strings.TrimPrefix(value, "$"), 64)
+			if err != nil || cost < 0 {
+				return Budget{}, fmt.Errorf("line %d: invalid cost %q", n+1, value)
+			}
+			budget.CostUSD = cost
+		default:
+			return Budget{}, fmt.Errorf("line %d: unknown limit %q (use tokens or cost)", n+1, fields[0])
+		}
+	}
+	return budget, nil
+}
+
+// parseTokenCount parses a token count such as 50000, 500k or 2.5M.
+func parseTokenCount(value string) (int, error) {
+	number, scale := value, 1.0
+	switch {
+	case strings.HasSuffix(value, "k"), strings.HasSuffix(value, "K"):
+		number, scale = value[:len(value)-1], 1e3
+	case strings.HasSuffix(value, "m"), strings.HasSuffix(value, "M"):
+		number, scale = value[:len(value)-1], 1e6
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid token count %q", value)
+	}
+	return int(n * scale), nil
+}
@@ -0,0 +1,98 @@
+package crumb
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestBudgets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("inherits ancestor budgets outermost first", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		parentPath := filepath.Join(root, CrumblerDir, "01-parent")
+		childPath := filepath.Join(parentPath, "01-child")
+		createCrumb(t, s, parentPath)
+		createCrumb(t, s, childPath)
+
+		writeBudget(t, s, filepath.Join(root, CrumblerDir), "cost $20\n")
+		writeBudget(t, s, childPath, "# keep it small\n\ntokens 500k\ncost 2.5\n")
+
+		budgets, err := s.Budgets(root, childPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []Budget{
+			{Crumb: CrumblerDir, CostUSD: 20},
+			{Crumb: filepath.Join(CrumblerDir, "01-parent", "01-child"), Tokens: 500000, CostUSD: 2.5},
+		}
+		if !reflect.DeepEqual(budgets, want) {
+			t.Errorf("Budgets() = %+v, want %+v", budgets, want)
+		}
+	})
+
+	t.Run("deleted crumb keeps ancestor budgets", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		writeBudget(t, s, filepath.Join(root, CrumblerDir), "tokens 1M\n")
+
+		budgets, err := s.Budgets(root, filepath.Join(root, CrumblerDir, "01-gone"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(budgets) != 1 || budgets[0].Tokens != 1000000 {
+			t.Errorf("Budgets() = %+v, want the root budget", budgets)
+		}
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		writeBudget(t, s, filepath.Join(root, CrumblerDir), "tokens 1M\nminutes 30\n")
+
+		_, err := s.Budgets(root, filepath.Join(root, CrumblerDir))
+		if err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("Budgets() error = %v, want it to name line 2", err)
+		}
+	})
+}
+
+func TestParseTokenCount(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		want  int
+		ok    bool
+	}{
+		{value: "50000", want: 50000, ok: true},
+		{value: "500k", want: 500000, ok: true},
+		{value: "2.5M", want: 2500000, ok: true},
+		{value: "lots", ok: false},
+		{value: "-1", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseTokenCount(tt.value)
+			if (err == nil) != tt.ok || got != tt.want {
+				t.Errorf("parseTokenCount(%q) = %d, %v; want %d, ok=%v", tt.value, got, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// writeBudget writes a BUDGET file for a crumb.
+func writeBudget(t *testing.T, s *Store, crumbPath, content string) {
+	t.Helper()
+	if err := s.fs.WriteFile(filepath.Join(crumbPath, BudgetFile), []byte(content)); err != nil {
+		t.Fatalf("failed to write BUDGET: %v", err)
+	}
+}
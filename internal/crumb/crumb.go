@@ -2,7 +2,6 @@ package crumb
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 )
@@ -14,6 +13,16 @@ const (
 	ReadmeFile = "README.md"
 )
 
+// States of the project as seen from the current crumb (see README "Prompt States").
+const (
+	// StateDecompose means the current crumb's README is empty
+	StateDecompose = "DECOMPOSE"
+	// StateExecute means the current crumb's README has content
+	StateExecute = "EXECUTE"
+	// StateDone means no crumbs remain
+	StateDone = "DONE"
+)
+
 // Crumb represents a unit of work in the crumbler system.
 type Crumb struct {
 	Path     string  // Full filesystem path
@@ -22,27 +31,35 @@ type Crumb struct {
 	ID       string  // Two-digit ID (01-10)
 	IsLeaf   bool    // True if no children
 	Children []Crumb // Child crumbs (if branch)
+
+	fs     Storage // Where the crumb is stored; nil means OS
+	readme *string // README contents read by a Snapshot; nil means read on demand
+}
+
+// GetCurrent finds the current crumb on disk. See Store.GetCurrent.
+func GetCurrent(root string) (*Crumb, error) {
+	return disk.GetCurrent(root)
 }
 
 // GetCurrent finds the current crumb using depth-first traversal.
 // Returns nil if project is done (.crumbler doesn't exist).
-func GetCurrent(root string) (*Crumb, error) {
+func (s *Store) GetCurrent(root string) (*Crumb, error) {
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// Check if .crumbler exists
-	if _, err := os.Stat(crumblerPath); os.IsNotExist(err) {
+	if !s.exists(crumblerPath) {
 		return nil, nil // Project is done (no .crumbler)
 	}
 
 	// Check for child crumbs
-	children, err := ListChildDirs(crumblerPath)
+	children, err := s.ListChildDirs(crumblerPath)
 	if err != nil {
 		return nil, err
 	}
 
 	// If has children, traverse to find current (deepest first) crumb
 	if len(children) > 0 {
-		crumb, err := traverse(children[0])
+		crumb, err := s.traverse(children[0])
 		if err != nil {
 			return nil, err
 		}
@@ -55,26 +72,32 @@ func GetCurrent(root string) (*Crumb, error) {
 	// No children - root crumb is the current crumb
 	crumb := &Crumb{
 		Path:    crumblerPath,
-		RelPath: CrumblerDir,
+		RelPath: relPath(root, crumblerPath),
 		Name:    "",
 		ID:      "",
 		IsLeaf:  true,
+		fs:      s.fs,
 	}
 	return crumb, nil
 }
 
+// Create creates a new sub-crumb on disk. See Store.Create.
+func Create(root string, name string) (string, error) {
+	return disk.Create(root, name)
+}
+
 // Create creates a new sub-crumb under the current crumb.
 // If .crumbler doesn't exist, creates it first (auto-init).
 // Returns the path to the created crumb directory.
-func Create(root string, name string) (string, error) {
+func (s *Store) Create(root string, name string) (string, error) {
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// Auto-init: create .crumbler with README if it doesn't exist
-	if err := ensureCrumblerDir(crumblerPath); err != nil {
+	if err := s.ensureCrumblerDir(crumblerPath); err != nil {
 		return "", err
 	}
 
-	current, err := GetCurrent(root)
+	current, err := s.GetCurrent(root)
 	if err != nil {
 		return "", err
 	}
@@ -87,26 +110,36 @@ func Create(root string, name string) (string, error) {
 		parentPath = current.Path
 	}
 
-	return CreateAt(parentPath, name)
+	return s.CreateAt(parentPath, name)
+}
+
+// CreateMultiple creates sibling sub-crumbs on disk. See Store.CreateMultiple.
+func CreateMultiple(root string, names []string) ([]string, error) {
+	return disk.CreateMultiple(root, names)
 }
 
 // CreateMultiple creates multiple sub-crumbs under the current crumb.
 // All crumbs are created as siblings (children of the same parent).
 // Returns the paths to all created crumb directories.
-func CreateMultiple(root string, names []string) ([]string, error) {
+func (s *Store) CreateMultiple(root string, names []string) ([]string, error) {
 	if len(names) == 0 {
 		return nil, fmt.Errorf("no names provided")
 	}
+	for _, name := range names {
+		if Kebabify(name) == "" {
+			return nil, fmt.Errorf("%w %q", ErrInvalidName, name)
+		}
+	}
 
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// Auto-init: create .crumbler with README if it doesn't exist
-	if err := ensureCrumblerDir(crumblerPath); err != nil {
+	if err := s.ensureCrumblerDir(crumblerPath); err != nil {
 		return nil, err
 	}
 
 	// Get current crumb ONCE - all creates will be children of this
-	current, err := GetCurrent(root)
+	current, err := s.GetCurrent(root)
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +154,7 @@ func CreateMultiple(root string, names []string) ([]string, error) {
 	// Create all crumbs as siblings under the same parent
 	var paths []string
 	for _, name := range names {
-		path, err := CreateAt(parentPath, name)
+		path, err := s.CreateAt(parentPath, name)
 		if err != nil {
 			return paths, fmt.Errorf("failed to create crumb %q: %w", name, err)
 		}
@@ -131,28 +164,36 @@ func CreateMultiple(root string, names []string) ([]string, error) {
 	return paths, nil
 }
 
+// CreateAt creates a new crumb on disk. See Store.CreateAt.
+func CreateAt(parentPath string, name string) (string, error) {
+	return disk.CreateAt(parentPath, name)
+}
+
 // CreateAt creates a new crumb at a specific parent path.
 // Returns the path to the created crumb directory.
-func CreateAt(parentPath string, name string) (string, error) {
+func (s *Store) CreateAt(parentPath string, name string) (string, error) {
 	// Get next available ID
-	id, err := NextID(parentPath)
+	id, err := s.NextID(parentPath)
 	if err != nil {
 		return "", err
 	}
 
 	// Create directory name
 	kebabName := Kebabify(name)
+	if kebabName == "" {
+		return "", fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
 	dirname := FormatDir(id, kebabName)
 	crumbPath := filepath.Join(parentPath, dirname)
 
 	// Create directory
-	if err := os.MkdirAll(crumbPath, 0755); err != nil {
+	if err := s.fs.MkdirAll(crumbPath); err != nil {
 		return "", fmt.Errorf("failed to create crumb directory: %w", err)
 	}
 
 	// Create empty README.md
 	readmePath := filepath.Join(crumbPath, ReadmeFile)
-	if err := os.WriteFile(readmePath, []byte{}, 0644); err != nil {
+	if err := s.fs.WriteFile(readmePath, []byte{}); err != nil {
 		return "", fmt.Errorf("failed to create README.md: %w", err)
 	}
 
@@ -160,67 +201,140 @@ func CreateAt(parentPath string, name string) (string, error) {
 }
 
 // ensureCrumblerDir creates the .crumbler directory with README if it doesn't exist.
-func ensureCrumblerDir(crumblerPath string) error {
-	if _, err := os.Stat(crumblerPath); os.IsNotExist(err) {
-		if err := os.MkdirAll(crumblerPath, 0755); err != nil {
+func (s *Store) ensureCrumblerDir(crumblerPath string) error {
+	if !s.exists(crumblerPath) {
+		if err := s.fs.MkdirAll(crumblerPath); err != nil {
 			return fmt.Errorf("failed to create .crumbler directory: %w", err)
 		}
 		readmePath := filepath.Join(crumblerPath, ReadmeFile)
-		if err := os.WriteFile(readmePath, []byte{}, 0644); err != nil {
+		if err := s.fs.WriteFile(readmePath, []byte{}); err != nil {
 			return fmt.Errorf("failed to create root README.md: %w", err)
 		}
 	}
 	return nil
 }
 
+// Delete removes the current crumb on disk. See Store.Delete.
+func Delete(root string) error {
+	return disk.Delete(root)
+}
+
 // Delete removes the current crumb.
 // Fails if the crumb has children.
 // Deleting the root crumb removes the entire .crumbler directory.
-func Delete(root string) error {
-	current, err := GetCurrent(root)
+func (s *Store) Delete(root string) error {
+	current, err := s.GetCurrent(root)
 	if err != nil {
 		return err
 	}
 
 	if current == nil {
-		return fmt.Errorf("no crumb to delete")
+		return ErrNoCrumb
 	}
 
+	return s.DeleteAt(current.Path)
+}
+
+// DeleteAt removes the crumb at a path on disk. See Store.DeleteAt.
+func DeleteAt(crumbPath string) error {
+	return disk.DeleteAt(crumbPath)
+}
+
+// DeleteAt removes the crumb at a specific path.
+// Fails if the crumb has children.
+func (s *Store) DeleteAt(crumbPath string) error {
 	// Check if crumb has children
-	children, err := ListChildDirs(current.Path)
+	children, err := s.ListChildDirs(crumbPath)
 	if err != nil {
 		return err
 	}
 	if len(children) > 0 {
-		return fmt.Errorf("cannot delete crumb with children (has %d children)", len(children))
+		return fmt.Errorf("%w (has %d children)", ErrHasChildren, len(children))
 	}
 
 	// Remove the directory (including root .crumbler)
-	if err := os.RemoveAll(current.Path); err != nil {
+	if err := s.fs.RemoveAll(crumbPath); err != nil {
 		return fmt.Errorf("failed to delete crumb: %w", err)
 	}
 
 	return nil
 }
 
+// List returns the crumb tree on disk. See Store.List.
+func List(root string) (*Crumb, error) {
+	return disk.List(root)
+}
+
 // List returns all crumbs as a tree structure.
 // Returns nil if .crumbler doesn't exist (project is done).
-func List(root string) (*Crumb, error) {
+func (s *Store) List(root string) (*Crumb, error) {
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// Check if .crumbler exists
-	if _, err := os.Stat(crumblerPath); os.IsNotExist(err) {
+	if !s.exists(crumblerPath) {
 		return nil, nil // No crumbs
 	}
 
-	return listCrumb(root, crumblerPath)
+	return s.listCrumb(root, crumblerPath)
+}
+
+// Find returns the crumb at path on disk. See Store.Find.
+func Find(root, path string) (*Crumb, error) {
+	return disk.Find(root, path)
+}
+
+// Find returns the crumb (with its subtree) at path, given relative to the
+// project root (".crumbler/01-setup"), to the root or to the .crumbler
+// directory ("01-setup"). Paths outside .crumbler are rejected with ErrNotFound.
+func (s *Store) Find(root, path string) (*Crumb, error) {
+	fullPath, err := resolvePath(root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	crumb, err := s.listCrumb(root, fullPath)
+	if err != nil {
+		return nil, err
+	}
+	if crumb == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	return crumb, nil
+}
+
+// resolvePath returns the full path of a crumb path as accepted by Find.
+func resolvePath(root, path string) (string, error) {
+	crumblerPath := filepath.Join(root, CrumblerDir)
+	crumblerRel := relPath(root, crumblerPath) // differs from CrumblerDir for named plans
+
+	path = filepath.Clean(path)
+	fullPath := path
+	if !filepath.IsAbs(path) {
+		switch {
+		case hasPathPrefix(path, crumblerRel):
+			fullPath = filepath.Join(ProjectRoot(root), path)
+		case hasPathPrefix(path, CrumblerDir):
+			fullPath = filepath.Join(root, path)
+		default:
+			fullPath = filepath.Join(crumblerPath, path)
+		}
+	}
+
+	rel, err := filepath.Rel(crumblerPath, fullPath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+	}
+	return fullPath, nil
 }
 
 // listCrumb recursively builds the crumb tree.
-func listCrumb(root, path string) (*Crumb, error) {
-	// Check for README.md
-	readmePath := filepath.Join(path, ReadmeFile)
-	if _, err := os.Stat(readmePath); os.IsNotExist(err) {
+func (s *Store) listCrumb(root, path string) (*Crumb, error) {
+	// Check for README.md and get children in one read
+	d, err := s.readCrumbDir(path)
+	if err != nil {
+		return nil, err
+	}
+	if !d.hasReadme {
 		return nil, nil
 	}
 
@@ -232,16 +346,11 @@ func listCrumb(root, path string) (*Crumb, error) {
 		RelPath: relPath(root, path),
 		Name:    name,
 		ID:      id,
+		fs:      s.fs,
 	}
 
-	// Get children
-	children, err := ListChildDirs(path)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, childPath := range children {
-		child, err := listCrumb(root, childPath)
+	for _, childPath := range d.children {
+		child, err := s.listCrumb(root, childPath)
 		if err != nil {
 			return nil, err
 		}
@@ -255,32 +364,42 @@ func listCrumb(root, path string) (*Crumb, error) {
 	return crumb, nil
 }
 
+// IsDone reports whether the project on disk is done. See Store.IsDone.
+func IsDone(root string) (bool, error) {
+	return disk.IsDone(root)
+}
+
 // IsDone returns true if no work remains in the project.
 // Project is done when .crumbler directory doesn't exist.
 // The filesystem IS the state - existence = work to do, deleted = done.
-func IsDone(root string) (bool, error) {
+func (s *Store) IsDone(root string) (bool, error) {
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// Check if .crumbler exists
-	if _, err := os.Stat(crumblerPath); os.IsNotExist(err) {
+	if !s.exists(crumblerPath) {
 		return true, nil // Project is done (no .crumbler directory)
 	}
 
 	return false, nil // Project has work to do
 }
 
+// Count returns the number of crumbs on disk. See Store.Count.
+func Count(root string) (int, error) {
+	return disk.Count(root)
+}
+
 // Count returns the total number of crumbs.
 // Returns 0 if .crumbler doesn't exist (project is done).
-func Count(root string) (int, error) {
+func (s *Store) Count(root string) (int, error) {
 	crumblerPath := filepath.Join(root, CrumblerDir)
 
 	// If .crumbler doesn't exist, project is done
-	if _, err := os.Stat(crumblerPath); os.IsNotExist(err) {
+	if !s.exists(crumblerPath) {
 		return 0, nil
 	}
 
 	// Count child crumbs + 1 for root
-	childCount, err := countCrumbs(crumblerPath)
+	childCount, err := s.countCrumbs(crumblerPath)
 	if err != nil {
 		return 0, err
 	}
@@ -289,15 +408,40 @@ func Count(root string) (int, error) {
 }
 
 // GetReadme returns the contents of the crumb's README.md.
+// Crumbs from a Snapshot return the contents as of the snapshot.
 func (c *Crumb) GetReadme() (string, error) {
+	if c.readme != nil {
+		return *c.readme, nil
+	}
 	readmePath := filepath.Join(c.Path, ReadmeFile)
-	content, err := os.ReadFile(readmePath)
+	content, err := c.storage().ReadFile(readmePath)
 	if err != nil {
 		return "", err
 	}
 	return string(content), nil
 }
 
+// State returns StateDecompose if the crumb's README is empty, StateExecute otherwise.
+func (c *Crumb) State() string {
+	readme, _ := c.GetReadme()
+	if strings.TrimSpace(readme) == "" {
+		return StateDecompose
+	}
+	return StateExecute
+}
+
+// WriteReadme replaces the contents of the crumb's README.md.
+func (c *Crumb) WriteReadme(content string) error {
+	readmePath := filepath.Join(c.Path, ReadmeFile)
+	if err := c.storage().WriteFile(readmePath, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write README.md: %w", err)
+	}
+	if c.readme != nil {
+		*c.readme = content
+	}
+	return nil
+}
+
 // DisplayName returns a human-readable name for the crumb.
 func (c *Crumb) DisplayName() string {
 	if c.Name != "" {
@@ -316,9 +460,22 @@ func (c *Crumb) DisplayName() string {
 	return "root"
 }
 
-// relPath returns a path relative to the project root.
+// storage returns the Storage the crumb lives in.
+func (c *Crumb) storage() Storage {
+	if c.fs == nil {
+		return OS
+	}
+	return c.fs
+}
+
+// hasPathPrefix reports whether path is dir or inside it.
+func hasPathPrefix(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// relPath returns a path relative to the project root (see ProjectRoot).
 func relPath(root, fullPath string) string {
-	rel, err := filepath.Rel(root, fullPath)
+	rel, err := filepath.Rel(ProjectRoot(root), fullPath)
 	if err != nil {
 		return fullPath
 	}
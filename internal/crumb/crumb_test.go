@@ -1,36 +1,48 @@
 package crumb
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 )
 
-// setupTestProject creates a temporary test project with .crumbler directory.
-func setupTestProject(t *testing.T) string {
+// newTestStore returns an in-memory store with an empty project directory.
+func newTestStore(t *testing.T) (*Store, string) {
 	t.Helper()
-	dir := t.TempDir()
+	s := NewStore(NewMemStorage())
+	dir := filepath.Join(string(filepath.Separator), "project")
+	if err := s.fs.MkdirAll(dir); err != nil {
+		t.Fatalf("failed to create project directory: %v", err)
+	}
+	return s, dir
+}
+
+// setupTestProject creates an in-memory test project with .crumbler directory.
+func setupTestProject(t *testing.T) (*Store, string) {
+	t.Helper()
+	s, dir := newTestStore(t)
 	crumblerDir := filepath.Join(dir, CrumblerDir)
-	if err := os.MkdirAll(crumblerDir, 0755); err != nil {
+	if err := s.fs.MkdirAll(crumblerDir); err != nil {
 		t.Fatalf("failed to create .crumbler: %v", err)
 	}
 	// Create root README.md
 	readmePath := filepath.Join(crumblerDir, ReadmeFile)
-	if err := os.WriteFile(readmePath, []byte("# Project"), 0644); err != nil {
+	if err := s.fs.WriteFile(readmePath, []byte("# Project")); err != nil {
 		t.Fatalf("failed to create README.md: %v", err)
 	}
-	return dir
+	return s, dir
 }
 
 // createCrumb creates a crumb directory with README.md.
-func createCrumb(t *testing.T, path string) {
+func createCrumb(t *testing.T, s *Store, path string) {
 	t.Helper()
-	if err := os.MkdirAll(path, 0755); err != nil {
+	if err := s.fs.MkdirAll(path); err != nil {
 		t.Fatalf("failed to create crumb dir: %v", err)
 	}
 	readmePath := filepath.Join(path, ReadmeFile)
-	if err := os.WriteFile(readmePath, []byte(""), 0644); err != nil {
+	if err := s.fs.WriteFile(readmePath, []byte("")); err != nil {
 		t.Fatalf("failed to create README.md: %v", err)
 	}
 }
@@ -117,63 +129,74 @@ func TestNextID(t *testing.T) {
 	t.Parallel()
 
 	t.Run("empty directory", func(t *testing.T) {
-		dir := t.TempDir()
-		id, err := NextID(dir)
+		t.Parallel()
+
+		s, dir := newTestStore(t)
+		id, err := s.NextID(dir)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if id != "01" {
-			t.Errorf("NextID() = %q, want %q", id, "01")
+			t.Errorf("s.NextID() = %q, want %q", id, "01")
 		}
 	})
 
 	t.Run("non-existent directory", func(t *testing.T) {
-		id, err := NextID("/nonexistent/path")
+		t.Parallel()
+
+		s, _ := newTestStore(t)
+		id, err := s.NextID("/nonexistent/path")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if id != "01" {
-			t.Errorf("NextID() = %q, want %q", id, "01")
+			t.Errorf("s.NextID() = %q, want %q", id, "01")
 		}
 	})
 
 	t.Run("with existing crumbs", func(t *testing.T) {
-		dir := t.TempDir()
-		createCrumb(t, filepath.Join(dir, "01-first"))
-		createCrumb(t, filepath.Join(dir, "02-second"))
+		t.Parallel()
+
+		s, dir := newTestStore(t)
+		createCrumb(t, s, filepath.Join(dir, "01-first"))
+		createCrumb(t, s, filepath.Join(dir, "02-second"))
 
-		id, err := NextID(dir)
+		id, err := s.NextID(dir)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if id != "03" {
-			t.Errorf("NextID() = %q, want %q", id, "03")
+			t.Errorf("s.NextID() = %q, want %q", id, "03")
 		}
 	})
 
 	t.Run("with gap in IDs", func(t *testing.T) {
-		dir := t.TempDir()
-		createCrumb(t, filepath.Join(dir, "01-first"))
-		createCrumb(t, filepath.Join(dir, "03-third"))
+		t.Parallel()
 
-		id, err := NextID(dir)
+		s, dir := newTestStore(t)
+		createCrumb(t, s, filepath.Join(dir, "01-first"))
+		createCrumb(t, s, filepath.Join(dir, "03-third"))
+
+		id, err := s.NextID(dir)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 		if id != "02" {
-			t.Errorf("NextID() = %q, want %q (should fill gap)", id, "02")
+			t.Errorf("s.NextID() = %q, want %q (should fill gap)", id, "02")
 		}
 	})
 
 	t.Run("directory full", func(t *testing.T) {
-		dir := t.TempDir()
+		t.Parallel()
+
+		s, dir := newTestStore(t)
 		for i := 1; i <= 10; i++ {
-			createCrumb(t, filepath.Join(dir, FormatDir(fmt.Sprintf("%02d", i), "crumb")))
+			createCrumb(t, s, filepath.Join(dir, FormatDir(fmt.Sprintf("%02d", i), "crumb")))
 		}
 
-		_, err := NextID(dir)
-		if err == nil {
-			t.Error("expected error for full directory")
+		_, err := s.NextID(dir)
+		if !errors.Is(err, ErrDirectoryFull) {
+			t.Errorf("s.NextID() error = %v, want ErrDirectoryFull", err)
 		}
 	})
 }
@@ -182,8 +205,10 @@ func TestGetCurrent(t *testing.T) {
 	t.Parallel()
 
 	t.Run("no children returns root crumb", func(t *testing.T) {
-		root := setupTestProject(t)
-		current, err := GetCurrent(root)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		current, err := s.GetCurrent(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -199,10 +224,12 @@ func TestGetCurrent(t *testing.T) {
 	})
 
 	t.Run("single crumb", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-task"))
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-task"))
 
-		current, err := GetCurrent(root)
+		current, err := s.GetCurrent(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -218,12 +245,14 @@ func TestGetCurrent(t *testing.T) {
 	})
 
 	t.Run("nested crumbs returns deepest", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup", "01-database", "01-migrations"))
+		t.Parallel()
 
-		current, err := GetCurrent(root)
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup", "01-database", "01-migrations"))
+
+		current, err := s.GetCurrent(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -236,11 +265,13 @@ func TestGetCurrent(t *testing.T) {
 	})
 
 	t.Run("returns first by ID", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "02-second"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-first"))
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "02-second"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-first"))
 
-		current, err := GetCurrent(root)
+		current, err := s.GetCurrent(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -253,8 +284,10 @@ func TestGetCurrent(t *testing.T) {
 	})
 
 	t.Run("no .crumbler returns nil", func(t *testing.T) {
-		dir := t.TempDir()
-		current, err := GetCurrent(dir)
+		t.Parallel()
+
+		s, dir := newTestStore(t)
+		current, err := s.GetCurrent(dir)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -268,8 +301,10 @@ func TestCreate(t *testing.T) {
 	t.Parallel()
 
 	t.Run("create first crumb", func(t *testing.T) {
-		root := setupTestProject(t)
-		path, err := Create(root, "First Task")
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		path, err := s.Create(root, "First Task")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -281,16 +316,18 @@ func TestCreate(t *testing.T) {
 
 		// Verify README.md exists
 		readmePath := filepath.Join(path, ReadmeFile)
-		if _, err := os.Stat(readmePath); os.IsNotExist(err) {
+		if _, err := s.fs.Stat(readmePath); os.IsNotExist(err) {
 			t.Error("README.md not created")
 		}
 	})
 
 	t.Run("create nested crumb", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-parent"))
+		t.Parallel()
 
-		path, err := Create(root, "Child Task")
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-parent"))
+
+		path, err := s.Create(root, "Child Task")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -302,10 +339,12 @@ func TestCreate(t *testing.T) {
 	})
 
 	t.Run("auto-increments ID", func(t *testing.T) {
-		root := setupTestProject(t)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
 		// Create two sibling crumbs at root level
 		// First, create with no current crumb (root becomes current)
-		path1, err := Create(root, "First Task")
+		path1, err := s.Create(root, "First Task")
 		if err != nil {
 			t.Fatalf("unexpected error creating first: %v", err)
 		}
@@ -315,7 +354,7 @@ func TestCreate(t *testing.T) {
 
 		// Now 01-first-task is the current crumb (deepest leaf)
 		// Creating "Second Task" should create UNDER 01-first-task
-		path2, err := Create(root, "Second Task")
+		path2, err := s.Create(root, "Second Task")
 		if err != nil {
 			t.Fatalf("unexpected error creating second: %v", err)
 		}
@@ -328,73 +367,89 @@ func TestCreate(t *testing.T) {
 			t.Errorf("parent of second = %q, want %q", filepath.Base(filepath.Dir(path2)), "01-first-task")
 		}
 	})
+
+	t.Run("rejects names without letters or digits", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		if _, err := s.CreateMultiple(root, []string{"Valid", "!!!"}); !errors.Is(err, ErrInvalidName) {
+			t.Fatalf("s.CreateMultiple() error = %v, want ErrInvalidName", err)
+		}
+		if children, _ := s.ListChildDirs(filepath.Join(root, CrumblerDir)); len(children) != 0 {
+			t.Error("no crumbs should be created when any name is invalid")
+		}
+	})
 }
 
 func TestDelete(t *testing.T) {
 	t.Parallel()
 
 	t.Run("delete leaf crumb", func(t *testing.T) {
-		root := setupTestProject(t)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
 		crumbPath := filepath.Join(root, CrumblerDir, "01-task")
-		createCrumb(t, crumbPath)
+		createCrumb(t, s, crumbPath)
 
-		err := Delete(root)
+		err := s.Delete(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
 
-		if _, err := os.Stat(crumbPath); !os.IsNotExist(err) {
+		if _, err := s.fs.Stat(crumbPath); !os.IsNotExist(err) {
 			t.Error("crumb directory should be deleted")
 		}
 	})
 
 	t.Run("delete works depth-first", func(t *testing.T) {
-		root := setupTestProject(t)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
 		parentPath := filepath.Join(root, CrumblerDir, "01-parent")
 		childPath := filepath.Join(parentPath, "01-child")
 		grandchildPath := filepath.Join(childPath, "01-grandchild")
-		createCrumb(t, parentPath)
-		createCrumb(t, childPath)
-		createCrumb(t, grandchildPath)
+		createCrumb(t, s, parentPath)
+		createCrumb(t, s, childPath)
+		createCrumb(t, s, grandchildPath)
 
 		// Current should be grandchild (deepest)
-		current, _ := GetCurrent(root)
+		current, _ := s.GetCurrent(root)
 		if current.Name != "grandchild" {
 			t.Fatalf("current = %q, want grandchild", current.Name)
 		}
 
 		// Delete grandchild
-		if err := Delete(root); err != nil {
+		if err := s.Delete(root); err != nil {
 			t.Fatalf("failed to delete grandchild: %v", err)
 		}
-		if _, err := os.Stat(grandchildPath); !os.IsNotExist(err) {
+		if _, err := s.fs.Stat(grandchildPath); !os.IsNotExist(err) {
 			t.Fatal("grandchild should be deleted")
 		}
 
 		// Current should now be child
-		current, _ = GetCurrent(root)
+		current, _ = s.GetCurrent(root)
 		if current.Name != "child" {
 			t.Fatalf("current = %q, want child", current.Name)
 		}
 
 		// Delete child
-		if err := Delete(root); err != nil {
+		if err := s.Delete(root); err != nil {
 			t.Fatalf("failed to delete child: %v", err)
 		}
 
 		// Current should now be parent
-		current, _ = GetCurrent(root)
+		current, _ = s.GetCurrent(root)
 		if current.Name != "parent" {
 			t.Fatalf("current = %q, want parent", current.Name)
 		}
 
 		// Delete parent
-		if err := Delete(root); err != nil {
+		if err := s.Delete(root); err != nil {
 			t.Fatalf("failed to delete parent: %v", err)
 		}
 
 		// After deleting all children, current is root
-		current, _ = GetCurrent(root)
+		current, _ = s.GetCurrent(root)
 		if current == nil {
 			t.Fatal("expected root as current after deleting children")
 		}
@@ -403,30 +458,120 @@ func TestDelete(t *testing.T) {
 		}
 
 		// Project is not done yet (.crumbler still exists)
-		done, _ := IsDone(root)
+		done, _ := s.IsDone(root)
 		if done {
 			t.Error("project should not be done - .crumbler still exists")
 		}
 
 		// Delete root crumb (removes .crumbler entirely)
-		if err := Delete(root); err != nil {
+		if err := s.Delete(root); err != nil {
 			t.Fatalf("failed to delete root: %v", err)
 		}
 
 		// Now project should be done (.crumbler deleted)
-		done, _ = IsDone(root)
+		done, _ = s.IsDone(root)
 		if !done {
 			t.Error("project should be done after deleting root")
 		}
 	})
 }
 
+func TestDeleteAt(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deletes leaf that is not current", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		firstPath := filepath.Join(root, CrumblerDir, "01-first")
+		secondPath := filepath.Join(root, CrumblerDir, "02-second")
+		createCrumb(t, s, firstPath)
+		createCrumb(t, s, secondPath)
+
+		if err := s.DeleteAt(secondPath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := s.fs.Stat(secondPath); !os.IsNotExist(err) {
+			t.Error("second crumb should be deleted")
+		}
+		if _, err := s.fs.Stat(firstPath); err != nil {
+			t.Error("first crumb should still exist")
+		}
+	})
+
+	t.Run("refuses crumb with children", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		parentPath := filepath.Join(root, CrumblerDir, "01-parent")
+		createCrumb(t, s, parentPath)
+		createCrumb(t, s, filepath.Join(parentPath, "01-child"))
+
+		err := s.DeleteAt(parentPath)
+		if !errors.Is(err, ErrHasChildren) {
+			t.Fatalf("s.DeleteAt() error = %v, want ErrHasChildren", err)
+		}
+		if _, err := s.fs.Stat(parentPath); err != nil {
+			t.Error("parent crumb should still exist")
+		}
+	})
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	s, root := setupTestProject(t)
+	parentPath := filepath.Join(root, CrumblerDir, "01-parent")
+	createCrumb(t, s, parentPath)
+	createCrumb(t, s, filepath.Join(parentPath, "01-child"))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "relative to project root", path: ".crumbler/01-parent", want: filepath.Join(CrumblerDir, "01-parent")},
+		{name: "relative to .crumbler", path: "01-parent/01-child", want: filepath.Join(CrumblerDir, "01-parent", "01-child")},
+		{name: "root", path: ".crumbler", want: CrumblerDir},
+		{name: "missing", path: "02-missing", wantErr: ErrNotFound},
+		{name: "outside .crumbler", path: "../etc", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := s.Find(root, tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("s.Find() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.RelPath != tt.want {
+				t.Errorf("s.Find().RelPath = %q, want %q", got.RelPath, tt.want)
+			}
+		})
+	}
+
+	parent, _ := s.Find(root, "01-parent")
+	if parent.IsLeaf || len(parent.Children) != 1 {
+		t.Errorf("s.Find() should include the subtree, got %d children", len(parent.Children))
+	}
+}
+
 func TestList(t *testing.T) {
 	t.Parallel()
 
 	t.Run("empty project", func(t *testing.T) {
-		root := setupTestProject(t)
-		tree, err := List(root)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		tree, err := s.List(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -439,12 +584,14 @@ func TestList(t *testing.T) {
 	})
 
 	t.Run("nested structure", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "02-features"))
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "02-features"))
 
-		tree, err := List(root)
+		tree, err := s.List(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -467,10 +614,12 @@ func TestIsDone(t *testing.T) {
 	t.Parallel()
 
 	t.Run("no .crumbler is done", func(t *testing.T) {
-		dir := t.TempDir()
+		t.Parallel()
+
+		s, dir := newTestStore(t)
 		// No .crumbler directory
 
-		done, err := IsDone(dir)
+		done, err := s.IsDone(dir)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -480,8 +629,10 @@ func TestIsDone(t *testing.T) {
 	})
 
 	t.Run(".crumbler exists is not done", func(t *testing.T) {
-		root := setupTestProject(t)
-		done, err := IsDone(root)
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		done, err := s.IsDone(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -491,10 +642,12 @@ func TestIsDone(t *testing.T) {
 	})
 
 	t.Run("project with crumbs not done", func(t *testing.T) {
-		root := setupTestProject(t)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-task"))
+		t.Parallel()
 
-		done, err := IsDone(root)
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-task"))
+
+		done, err := s.IsDone(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -508,8 +661,10 @@ func TestCount(t *testing.T) {
 	t.Parallel()
 
 	t.Run("empty project", func(t *testing.T) {
-		root := t.TempDir() // No .crumbler directory
-		count, err := Count(root)
+		t.Parallel()
+
+		s, root := newTestStore(t) // No .crumbler directory
+		count, err := s.Count(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -519,8 +674,10 @@ func TestCount(t *testing.T) {
 	})
 
 	t.Run("root only", func(t *testing.T) {
-		root := setupTestProject(t) // Creates .crumbler with README.md
-		count, err := Count(root)
+		t.Parallel()
+
+		s, root := setupTestProject(t) // Creates .crumbler with README.md
+		count, err := s.Count(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -530,12 +687,14 @@ func TestCount(t *testing.T) {
 	})
 
 	t.Run("nested structure", func(t *testing.T) {
-		root := setupTestProject(t) // Creates .crumbler with README.md (counts as 1)
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
-		createCrumb(t, filepath.Join(root, CrumblerDir, "02-features"))
+		t.Parallel()
+
+		s, root := setupTestProject(t) // Creates .crumbler with README.md (counts as 1)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-setup", "01-database"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "02-features"))
 
-		count, err := Count(root)
+		count, err := s.Count(root)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
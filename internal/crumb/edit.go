@@ -0,0 +1,126 @@
+package crumb
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Rename renames a crumb on disk. See Store.Rename.
+func Rename(crumbPath string, name string) (string, error) {
+	return disk.Rename(crumbPath, name)
+}
+
+// Rename changes a crumb's name, keeping its ID.
+// The name is kebabified like names given to Create.
+// Returns the new path of the crumb.
+func (s *Store) Rename(crumbPath string, name string) (string, error) {
+	id, _ := ParseDir(filepath.Base(crumbPath))
+	if id == "" {
+		return "", fmt.Errorf("cannot rename %s: not a child crumb", crumbPath)
+	}
+
+	kebabName := Kebabify(name)
+	if kebabName == "" {
+		return "", fmt.Errorf("%w %q", ErrInvalidName, name)
+	}
+
+	newPath := filepath.Join(filepath.Dir(crumbPath), FormatDir(id, kebabName))
+	if newPath == crumbPath {
+		return crumbPath, nil
+	}
+	if _, err := s.fs.Stat(newPath); err == nil {
+		return "", fmt.Errorf("%w: %s", ErrExists, filepath.Base(newPath))
+	}
+
+	if err := s.fs.Rename(crumbPath, newPath); err != nil {
+		return "", fmt.Errorf("failed to rename crumb: %w", err)
+	}
+	return newPath, nil
+}
+
+// Reorder moves a crumb among its siblings on disk. See Store.Reorder.
+func Reorder(crumbPath string, delta int) (string, error) {
+	return disk.Reorder(crumbPath, delta)
+}
+
+// Reorder moves a crumb one position among its siblings by exchanging IDs
+// with the previous (delta < 0) or next (delta > 0) sibling.
+// Returns the new path of the crumb. Moving past either end is a no-op.
+func (s *Store) Reorder(crumbPath string, delta int) (string, error) {
+	id, _ := ParseDir(filepath.Base(crumbPath))
+	if id == "" {
+		return "", fmt.Errorf("cannot reorder %s: not a child crumb", crumbPath)
+	}
+
+	siblings, err := s.ListChildDirs(filepath.Dir(crumbPath))
+	if err != nil {
+		return "", err
+	}
+
+	index := -1
+	for i, sibling := range siblings {
+		if sibling == crumbPath {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, crumbPath)
+	}
+
+	target := index + delta
+	if delta == 0 || target < 0 || target >= len(siblings) {
+		return crumbPath, nil
+	}
+
+	newPath, _, err := s.swap(crumbPath, siblings[target])
+	return newPath, err
+}
+
+// swap exchanges the IDs of two sibling crumbs.
+// Returns the new paths of a and b.
+func (s *Store) swap(a, b string) (string, string, error) {
+	parent := filepath.Dir(a)
+	idA, nameA := ParseDir(filepath.Base(a))
+	idB, nameB := ParseDir(filepath.Base(b))
+
+	newA := filepath.Join(parent, FormatDir(idB, nameA))
+	newB := filepath.Join(parent, FormatDir(idA, nameB))
+
+	// Move a out of the way first; its temporary name has no ID so it is
+	// never seen as a crumb if the swap is interrupted.
+	tmp := filepath.Join(parent, ".swap-"+filepath.Base(a))
+	if err := s.fs.Rename(a, tmp); err != nil {
+		return "", "", fmt.Errorf("failed to reorder crumbs: %w", err)
+	}
+	if err := s.fs.Rename(b, newB); err != nil {
+		s.fs.Rename(tmp, a)
+		return "", "", fmt.Errorf("failed to reorder crumbs: %w", err)
+	}
+	if err := s.fs.Rename(tmp, newA); err != nil {
+		return "", "", fmt.Errorf("failed to reorder crumbs: %w", err)
+	}
+	return newA, newB, nil
+}
+
+// DeleteTree removes a crumb and its descendants on disk. See Store.DeleteTree.
+func DeleteTree(crumbPath string) error {
+	return disk.DeleteTree(crumbPath)
+}
+
+// DeleteTree removes a crumb together with all of its descendants.
+// The root .crumbler directory cannot be removed this way.
+func (s *Store) DeleteTree(crumbPath string) error {
+	id, _ := ParseDir(filepath.Base(crumbPath))
+	if id == "" {
+		return fmt.Errorf("cannot delete %s: not a child crumb", crumbPath)
+	}
+	if _, err := s.fs.Stat(filepath.Join(crumbPath, ReadmeFile)); err != nil {
+		return fmt.Errorf("cannot delete %s: %w", crumbPath, ErrNotFound)
+	}
+
+	if err := s.fs.RemoveAll(crumbPath); err != nil {
+		return fmt.Errorf("failed to delete crumb: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package crumb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps ID and kebabifies", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		oldPath := filepath.Join(root, CrumblerDir, "02-old-name")
+		createCrumb(t, s, oldPath)
+		createCrumb(t, s, filepath.Join(oldPath, "01-child"))
+
+		newPath, err := s.Rename(oldPath, "New Name!")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filepath.Base(newPath) != "02-new-name" {
+			t.Errorf("s.Rename() = %q, want 02-new-name", filepath.Base(newPath))
+		}
+		if _, err := s.fs.Stat(filepath.Join(newPath, "01-child", ReadmeFile)); err != nil {
+			t.Error("children should move with the crumb")
+		}
+	})
+
+	t.Run("rejects empty name", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		path := filepath.Join(root, CrumblerDir, "01-task")
+		createCrumb(t, s, path)
+
+		if _, err := s.Rename(path, "!!!"); err == nil {
+			t.Error("expected error for name that kebabifies to empty")
+		}
+	})
+
+	t.Run("rejects root", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		if _, err := s.Rename(filepath.Join(root, CrumblerDir), "root"); err == nil {
+			t.Error("expected error renaming .crumbler")
+		}
+	})
+}
+
+func TestReorder(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*Store, string) {
+		s, root := setupTestProject(t)
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-first"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "02-second"))
+		createCrumb(t, s, filepath.Join(root, CrumblerDir, "04-fourth"))
+		return s, root
+	}
+
+	t.Run("move down swaps IDs", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setup(t)
+		newPath, err := s.Reorder(filepath.Join(root, CrumblerDir, "01-first"), 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filepath.Base(newPath) != "02-first" {
+			t.Errorf("s.Reorder() = %q, want 02-first", filepath.Base(newPath))
+		}
+		assertChildren(t, s, filepath.Join(root, CrumblerDir), "01-second", "02-first", "04-fourth")
+	})
+
+	t.Run("move up across gap", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setup(t)
+		newPath, err := s.Reorder(filepath.Join(root, CrumblerDir, "04-fourth"), -1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if filepath.Base(newPath) != "02-fourth" {
+			t.Errorf("s.Reorder() = %q, want 02-fourth", filepath.Base(newPath))
+		}
+		assertChildren(t, s, filepath.Join(root, CrumblerDir), "01-first", "02-fourth", "04-second")
+	})
+
+	t.Run("past the end is a no-op", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setup(t)
+		path := filepath.Join(root, CrumblerDir, "01-first")
+		newPath, err := s.Reorder(path, -1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if newPath != path {
+			t.Errorf("s.Reorder() = %q, want unchanged", newPath)
+		}
+		assertChildren(t, s, filepath.Join(root, CrumblerDir), "01-first", "02-second", "04-fourth")
+	})
+}
+
+func TestDeleteTree(t *testing.T) {
+	t.Parallel()
+
+	t.Run("removes crumb with children", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		parentPath := filepath.Join(root, CrumblerDir, "01-parent")
+		createCrumb(t, s, parentPath)
+		createCrumb(t, s, filepath.Join(parentPath, "01-child"))
+
+		if err := s.DeleteTree(parentPath); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := s.fs.Stat(parentPath); !os.IsNotExist(err) {
+			t.Error("subtree should be deleted")
+		}
+	})
+
+	t.Run("refuses root", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		if err := s.DeleteTree(filepath.Join(root, CrumblerDir)); err == nil {
+			t.Error("expected error deleting .crumbler")
+		}
+	})
+}
+
+// assertChildren checks the child crumb directory names of dir.
+func assertChildren(t *testing.T, s *Store, dir string, expected ...string) {
+	t.Helper()
+	children, err := s.ListChildDirs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, child := range children {
+		names = append(names, filepath.Base(child))
+	}
+	if len(names) != len(expected) {
+		t.Fatalf("children = %v, want %v", names, expected)
+	}
+	for i := range names {
+		if names[i] != expected[i] {
+			t.Fatalf("children = %v, want %v", names, expected)
+		}
+	}
+}
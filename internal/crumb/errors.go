@@ -0,0 +1,22 @@
+package crumb
+
+import "errors"
+
+// Sentinel errors returned (wrapped) by crumb operations. Callers that need
+// to tell failures apart, such as the MCP server, match them with errors.Is.
+var (
+	// ErrNoCrumb means there is no crumb to operate on (the project is done).
+	ErrNoCrumb = errors.New("no crumb to delete")
+	// ErrHasChildren means a crumb cannot be deleted until its children are.
+	ErrHasChildren = errors.New("cannot delete crumb with children")
+	// ErrDirectoryFull means a crumb already has the maximum number of children.
+	ErrDirectoryFull = errors.New("directory is full")
+	// ErrInvalidName means a crumb name is empty after kebabifying.
+	ErrInvalidName = errors.New("invalid crumb name")
+	// ErrNotFound means a path does not name a crumb.
+	ErrNotFound = errors.New("crumb not found")
+	// ErrExists means a crumb with the same directory name already exists.
+	ErrExists = errors.New("crumb already exists")
+	// ErrNoPlan means a named plan does not exist.
+	ErrNoPlan = errors.New("no such plan")
+)
@@ -0,0 +1,261 @@
+package crumb
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemStorage is a Storage that keeps everything in memory. It is safe for
+// concurrent use, and is meant for tests and tools that build crumb trees
+// without touching disk. The zero value is not usable; call NewMemStorage.
+type MemStorage struct {
+	mu    sync.RWMutex
+	dirs  map[string]bool   // Directories by clean path
+	files map[string][]byte // File contents by clean path
+}
+
+// NewMemStorage returns an empty in-memory Storage containing only the
+// root directory.
+func NewMemStorage() *MemStorage {
+	return &MemStorage{
+		dirs:  map[string]bool{string(filepath.Separator): true, ".": true},
+		files: map[string][]byte{},
+	}
+}
+
+var (
+	errIsDir  = errors.New("is a directory")
+	errNotDir = errors.New("not a directory")
+)
+
+// Stat describes the file or directory at name.
+func (m *MemStorage) Stat(name string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.stat("stat", filepath.Clean(name))
+}
+
+func (m *MemStorage) stat(op, name string) (fs.FileInfo, error) {
+	if m.dirs[name] {
+		return memInfo{name: filepath.Base(name), dir: true}, nil
+	}
+	if data, ok := m.files[name]; ok {
+		return memInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir lists the entries of a directory, sorted by name.
+func (m *MemStorage) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name = filepath.Clean(name)
+	if !m.dirs[name] {
+		if _, ok := m.files[name]; ok {
+			return nil, &fs.PathError{Op: "readdir", Path: name, Err: errNotDir}
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	var entries []fs.DirEntry
+	add := func(path string) {
+		if path != name && filepath.Dir(path) == name {
+			info, _ := m.stat("readdir", path)
+			entries = append(entries, fs.FileInfoToDirEntry(info))
+		}
+	}
+	for path := range m.dirs {
+		add(path)
+	}
+	for path := range m.files {
+		add(path)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// ReadFile returns a copy of a file's contents.
+func (m *MemStorage) ReadFile(name string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name = filepath.Clean(name)
+	data, ok := m.files[name]
+	if !ok {
+		if m.dirs[name] {
+			return nil, &fs.PathError{Op: "read", Path: name, Err: errIsDir}
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// WriteFile creates or replaces a file. Its directory must exist.
+func (m *MemStorage) WriteFile(name string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if m.dirs[name] {
+		return &fs.PathError{Op: "open", Path: name, Err: errIsDir}
+	}
+	if !m.dirs[filepath.Dir(name)] {
+		return &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	m.files[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// MkdirAll creates a directory along with any missing parents.
+func (m *MemStorage) MkdirAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	var missing []string
+	for dir := path; !m.dirs[dir]; dir = filepath.Dir(dir) {
+		if _, ok := m.files[dir]; ok {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: errNotDir}
+		}
+		missing = append(missing, dir)
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	for _, dir := range missing {
+		m.dirs[dir] = true
+	}
+	return nil
+}
+
+// Rename moves a file or directory, with everything below it.
+// Like os.Rename, it replaces an existing file or empty directory.
+func (m *MemStorage) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	linkErr := func(err error) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: err}
+	}
+	if _, err := m.stat("rename", oldpath); err != nil {
+		return linkErr(fs.ErrNotExist)
+	}
+	if !m.dirs[filepath.Dir(newpath)] {
+		return linkErr(fs.ErrNotExist)
+	}
+	if oldpath == newpath {
+		return nil
+	}
+	if m.dirs[oldpath] && isBelow(newpath, oldpath) {
+		return linkErr(errors.New("cannot move a directory into itself"))
+	}
+	if m.dirs[newpath] {
+		if !m.dirs[oldpath] {
+			return linkErr(errIsDir)
+		}
+		if m.hasChildren(newpath) {
+			return linkErr(fs.ErrExist)
+		}
+	}
+
+	m.removeAll(newpath)
+	moved := func(path string) (string, bool) {
+		if path != oldpath && !isBelow(path, oldpath) {
+			return "", false
+		}
+		return newpath + strings.TrimPrefix(path, oldpath), true
+	}
+	dirs := map[string]bool{}
+	for path := range m.dirs {
+		if to, ok := moved(path); ok {
+			delete(m.dirs, path)
+			dirs[to] = true
+		}
+	}
+	files := map[string][]byte{}
+	for path, data := range m.files {
+		if to, ok := moved(path); ok {
+			delete(m.files, path)
+			files[to] = data
+		}
+	}
+	for path := range dirs {
+		m.dirs[path] = true
+	}
+	for path, data := range files {
+		m.files[path] = data
+	}
+	return nil
+}
+
+// RemoveAll removes path and everything below it.
+func (m *MemStorage) RemoveAll(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeAll(filepath.Clean(path))
+	return nil
+}
+
+func (m *MemStorage) removeAll(path string) {
+	for dir := range m.dirs {
+		if dir == path || isBelow(dir, path) {
+			delete(m.dirs, dir)
+		}
+	}
+	for file := range m.files {
+		if file == path || isBelow(file, path) {
+			delete(m.files, file)
+		}
+	}
+}
+
+// hasChildren reports whether directory dir has any entries.
+func (m *MemStorage) hasChildren(dir string) bool {
+	for path := range m.dirs {
+		if isBelow(path, dir) {
+			return true
+		}
+	}
+	for path := range m.files {
+		if isBelow(path, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBelow reports whether path is strictly inside dir.
+func isBelow(path, dir string) bool {
+	if dir == string(filepath.Separator) {
+		return path != dir && filepath.IsAbs(path)
+	}
+	return path != dir && hasPathPrefix(path, dir)
+}
+
+// memInfo is the fs.FileInfo of a MemStorage entry.
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string       { return i.name }
+func (i memInfo) Size() int64        { return i.size }
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() any           { return nil }
+
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0755
+	}
+	return 0644
+}
@@ -1,4 +1,8 @@
 // Package crumb provides core crumb operations for crumbler v2.
+//
+// Operations run against a Storage backend through a Store. The
+// package-level functions use the OS filesystem; NewMemStorage keeps a tree
+// entirely in memory.
 package crumb
 
 import (
@@ -48,10 +52,15 @@ func Kebabify(name string) string {
 	return strings.Trim(cleaned, "-")
 }
 
+// NextID returns the next available ID in a directory on disk. See Store.NextID.
+func NextID(dir string) (string, error) {
+	return disk.NextID(dir)
+}
+
 // NextID returns the next available ID (01-10) in the given directory.
 // Returns error if directory is full (already has 10 children).
-func NextID(dir string) (string, error) {
-	entries, err := os.ReadDir(dir)
+func (s *Store) NextID(dir string) (string, error) {
+	entries, err := s.fs.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return "01", nil
@@ -80,7 +89,7 @@ func NextID(dir string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("directory is full (max %d children)", MaxChildren)
+	return "", fmt.Errorf("%w (max %d children)", ErrDirectoryFull, MaxChildren)
 }
 
 // FormatDir combines an ID and name into a directory name.
@@ -122,9 +131,14 @@ func ParseDir(dirname string) (id, name string) {
 	return potentialID, ""
 }
 
-// ListChildDirs returns sorted child directories that match the ID pattern.
+// ListChildDirs lists child crumb directories on disk. See Store.ListChildDirs.
 func ListChildDirs(dir string) ([]string, error) {
-	entries, err := os.ReadDir(dir)
+	return disk.ListChildDirs(dir)
+}
+
+// ListChildDirs returns sorted child directories that match the ID pattern.
+func (s *Store) ListChildDirs(dir string) ([]string, error) {
+	entries, err := s.fs.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
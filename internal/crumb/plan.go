@@ -0,0 +1,90 @@
+package crumb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// PlansDir holds named plans. Each plan is a root of its own:
+	// .crumbler-plans/<name>/.crumbler/ is the plan's crumb tree.
+	PlansDir = ".crumbler-plans"
+	// DefaultPlan names the project's own .crumbler/ tree.
+	DefaultPlan = "default"
+)
+
+// PlanRoot returns the root for a plan: the project root itself for the
+// default plan ("" or "default"), or .crumbler-plans/<name> for a named plan.
+// Plan names must already be kebab-case.
+func PlanRoot(projectRoot, name string) (string, error) {
+	if name == "" || name == DefaultPlan {
+		return projectRoot, nil
+	}
+	if Kebabify(name) != name {
+		return "", fmt.Errorf("invalid plan name %q: use lowercase letters, digits and hyphens", name)
+	}
+	return filepath.Join(projectRoot, PlansDir, name), nil
+}
+
+// ExistingPlanRoot is PlanRoot for a plan that must already exist, since
+// the missing tree of a mistyped plan name looks like a finished plan.
+// Returns an error matching ErrNoPlan, naming the plans there are, if the
+// named plan's directory doesn't exist.
+func ExistingPlanRoot(projectRoot, name string) (string, error) {
+	root, err := PlanRoot(projectRoot, name)
+	if err != nil || name == "" || name == DefaultPlan {
+		return root, err
+	}
+	if info, err := os.Stat(root); err == nil && info.IsDir() {
+		return root, nil
+	}
+	names, err := ListPlans(projectRoot)
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("%w: %s (the project has no named plans)", ErrNoPlan, name)
+	}
+	return "", fmt.Errorf("%w: %s (plans: %s)", ErrNoPlan, name, strings.Join(names, ", "))
+}
+
+// ListPlans lists the named plans of a project on disk. See Store.ListPlans.
+func ListPlans(projectRoot string) ([]string, error) {
+	return disk.ListPlans(projectRoot)
+}
+
+// ListPlans returns the names of the named plans in the project, sorted.
+// The default plan is not included.
+func (s *Store) ListPlans(projectRoot string) ([]string, error) {
+	entries, err := s.fs.ReadDir(filepath.Join(projectRoot, PlansDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", PlansDir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && Kebabify(entry.Name()) == entry.Name() && entry.Name() != DefaultPlan {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ProjectRoot returns the project directory for root. For a named plan root
+// (<project>/.crumbler-plans/<name>) that is the enclosing project; otherwise
+// it is root itself. Crumb paths shown to users and agents are relative to
+// the project directory, and verification commands run in it.
+func ProjectRoot(root string) string {
+	parent := filepath.Dir(root)
+	if filepath.Base(parent) == PlansDir {
+		return filepath.Dir(parent)
+	}
+	return root
+}
@@ -0,0 +1,144 @@
+package crumb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanRoot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		plan    string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty is default", plan: "", want: "/p"},
+		{name: "default", plan: DefaultPlan, want: "/p"},
+		{name: "named", plan: "tech-debt", want: filepath.Join("/p", PlansDir, "tech-debt")},
+		{name: "uppercase", plan: "Debt", wantErr: true},
+		{name: "path escape", plan: "../x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := PlanRoot("/p", tt.plan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PlanRoot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PlanRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListPlans(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if names, err := ListPlans(root); err != nil || len(names) != 0 {
+		t.Fatalf("ListPlans() = %v, %v, want none", names, err)
+	}
+
+	for _, name := range []string{"feature", "debt", "Not A Plan"} {
+		if err := os.MkdirAll(filepath.Join(root, PlansDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := ListPlans(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, ",") != "debt,feature" {
+		t.Errorf("ListPlans() = %v, want [debt feature]", names)
+	}
+}
+
+func TestExistingPlanRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	if _, err := ExistingPlanRoot(root, "debt"); !errors.Is(err, ErrNoPlan) {
+		t.Fatalf("ExistingPlanRoot() error = %v, want ErrNoPlan", err)
+	}
+	if got, err := ExistingPlanRoot(root, ""); err != nil || got != root {
+		t.Errorf("ExistingPlanRoot() = %q, %v, want the project root", got, err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, PlansDir, "debt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ExistingPlanRoot(root, "debt"); err != nil || got != filepath.Join(root, PlansDir, "debt") {
+		t.Errorf("ExistingPlanRoot() = %q, %v", got, err)
+	}
+
+	// A typo names the plans there are
+	_, err := ExistingPlanRoot(root, "dept")
+	if !errors.Is(err, ErrNoPlan) || !strings.Contains(err.Error(), "plans: debt") {
+		t.Errorf("ExistingPlanRoot() error = %v, want ErrNoPlan listing debt", err)
+	}
+}
+
+func TestNamedPlan(t *testing.T) {
+	t.Parallel()
+
+	project := t.TempDir()
+	root, err := PlanRoot(project, "debt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ProjectRoot(root) != project || ProjectRoot(project) != project {
+		t.Fatalf("ProjectRoot() should map the plan root to %s", project)
+	}
+
+	// Creating a crumb auto-initializes the plan
+	path, err := Create(root, "Remove dead code")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, CrumblerDir)); !os.IsNotExist(err) {
+		t.Error("named plan should not touch the default .crumbler")
+	}
+
+	// Paths are shown relative to the project, not the plan root
+	current, err := GetCurrent(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRel := filepath.Join(PlansDir, "debt", CrumblerDir, "01-remove-dead-code")
+	if current.Path != path || current.RelPath != wantRel {
+		t.Errorf("current = %s (%s), want %s", current.Path, current.RelPath, wantRel)
+	}
+
+	// Find accepts both the displayed path and the plan-relative one
+	for _, p := range []string{wantRel, filepath.Join(CrumblerDir, "01-remove-dead-code"), "01-remove-dead-code"} {
+		if c, err := Find(root, p); err != nil || c.Path != path {
+			t.Errorf("Find(%q) = %v, %v", p, c, err)
+		}
+	}
+
+	// Verification runs in the project directory
+	if err := os.WriteFile(filepath.Join(project, "marker"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := RunVerify(root, []string{"test -f marker"}, 0); err != nil {
+		t.Errorf("RunVerify() should run in the project root: %v", err)
+	}
+
+	// Each plan has its own DONE state
+	if err := Delete(root); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(root); err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := IsDone(root); !done {
+		t.Error("plan should be done")
+	}
+}
@@ -0,0 +1,162 @@
+package crumb
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Snapshot is a crumb tree read in a single walk. It answers the same
+// questions as Count, List, GetCurrent and Find, plus each crumb's README,
+// from memory, so commands that show the whole tree touch every directory
+// once. A Snapshot does not change when the tree does; take a new one to
+// see later changes.
+type Snapshot struct {
+	root    string
+	done    bool
+	count   int
+	tree    *Crumb
+	current *Crumb
+}
+
+// TakeSnapshot reads the crumb tree on disk. See Store.TakeSnapshot.
+func TakeSnapshot(root string) (*Snapshot, error) {
+	return disk.TakeSnapshot(root)
+}
+
+// TakeSnapshot reads the whole crumb tree under root in one walk: one
+// directory listing and one README read per crumb.
+func (s *Store) TakeSnapshot(root string) (*Snapshot, error) {
+	crumblerPath := filepath.Join(root, CrumblerDir)
+	snap := &Snapshot{root: root}
+
+	if !s.exists(crumblerPath) {
+		snap.done = true // Project is done (no .crumbler)
+		return snap, nil
+	}
+
+	tree, err := s.walk(snap, crumblerPath, true)
+	if err != nil {
+		return nil, err
+	}
+	snap.tree = tree
+	snap.count++ // The root crumb
+
+	return snap, nil
+}
+
+// walk reads dir and everything below it into snap, returning the crumb
+// for dir, or nil if dir has no README.md. onPath is set while dir is on
+// the first-child path from the root, where the current crumb lies.
+//
+// The results match the separate walks: Count counts every child crumb
+// directory, List only includes crumbs with a README.md, and the current
+// crumb follows first children like GetCurrent.
+func (s *Store) walk(snap *Snapshot, dir string, onPath bool) (*Crumb, error) {
+	d, err := s.readCrumbDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	isRoot := dir == filepath.Join(snap.root, CrumblerDir)
+
+	var crumb *Crumb
+	if d.hasReadme {
+		id, name := ParseDir(filepath.Base(dir))
+		crumb = &Crumb{
+			Path:    dir,
+			RelPath: relPath(snap.root, dir),
+			Name:    name,
+			ID:      id,
+			fs:      s.fs,
+		}
+		if content, err := s.fs.ReadFile(filepath.Join(dir, ReadmeFile)); err == nil {
+			readme := string(content)
+			crumb.readme = &readme
+		}
+	}
+
+	snap.count += len(d.children)
+	var children []Crumb
+	for i, childPath := range d.children {
+		// Like traverse, the current crumb is never below a missing README
+		child, err := s.walk(snap, childPath, onPath && i == 0 && (crumb != nil || isRoot))
+		if err != nil {
+			return nil, err
+		}
+		if child != nil && crumb != nil {
+			children = append(children, *child)
+		}
+	}
+
+	if crumb != nil {
+		crumb.Children = children
+		crumb.IsLeaf = len(children) == 0
+	}
+
+	if onPath && len(d.children) == 0 && (crumb != nil || isRoot) {
+		// GetCurrent treats the root as current even without a README,
+		// and leaves its name empty
+		current := Crumb{Path: dir, RelPath: relPath(snap.root, dir), IsLeaf: true, fs: s.fs}
+		if crumb != nil {
+			current = *crumb
+		}
+		if isRoot {
+			current.Name, current.ID = "", ""
+		}
+		snap.current = &current
+	}
+	return crumb, nil
+}
+
+// Root returns the root the snapshot was taken of.
+func (s *Snapshot) Root() string {
+	return s.root
+}
+
+// Done reports whether the project was done (.crumbler didn't exist).
+func (s *Snapshot) Done() bool {
+	return s.done
+}
+
+// Count returns the total number of crumbs, including the root crumb.
+func (s *Snapshot) Count() int {
+	return s.count
+}
+
+// List returns the crumb tree, or nil if the project was done.
+func (s *Snapshot) List() *Crumb {
+	return s.tree
+}
+
+// Current returns the current crumb, or nil if the project was done.
+func (s *Snapshot) Current() *Crumb {
+	return s.current
+}
+
+// Find returns the crumb (with its subtree) at path, accepting the same
+// paths as Store.Find.
+func (s *Snapshot) Find(path string) (*Crumb, error) {
+	fullPath, err := resolvePath(s.root, path)
+	if err != nil {
+		return nil, err
+	}
+	if c := findPath(s.tree, fullPath); c != nil {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNotFound, filepath.Clean(path))
+}
+
+// findPath returns the crumb at fullPath in the tree below c, or nil.
+func findPath(c *Crumb, fullPath string) *Crumb {
+	if c == nil || !hasPathPrefix(fullPath, c.Path) {
+		return nil
+	}
+	if c.Path == fullPath {
+		return c
+	}
+	for i := range c.Children {
+		if found := findPath(&c.Children[i], fullPath); found != nil {
+			return found
+		}
+	}
+	return nil
+}
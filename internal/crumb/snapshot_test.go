@@ -0,0 +1,279 @@
+package crumb
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, s *Store, root string)
+	}{
+		{
+			name:  "done",
+			setup: func(t *testing.T, s *Store, root string) {},
+		},
+		{
+			name: "root only",
+			setup: func(t *testing.T, s *Store, root string) {
+				createCrumb(t, s, filepath.Join(root, CrumblerDir))
+			},
+		},
+		{
+			name: "nested",
+			setup: func(t *testing.T, s *Store, root string) {
+				crumblerDir := filepath.Join(root, CrumblerDir)
+				createCrumb(t, s, crumblerDir)
+				createCrumb(t, s, filepath.Join(crumblerDir, "01-setup"))
+				createCrumb(t, s, filepath.Join(crumblerDir, "01-setup", "01-database"))
+				createCrumb(t, s, filepath.Join(crumblerDir, "02-features"))
+				if err := s.fs.WriteFile(filepath.Join(crumblerDir, "01-setup", "01-database", ReadmeFile), []byte("# DB\n")); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			name: "missing README on current path",
+			setup: func(t *testing.T, s *Store, root string) {
+				crumblerDir := filepath.Join(root, CrumblerDir)
+				createCrumb(t, s, crumblerDir)
+				if err := s.fs.MkdirAll(filepath.Join(crumblerDir, "01-broken", "01-child")); err != nil {
+					t.Fatal(err)
+				}
+				createCrumb(t, s, filepath.Join(crumblerDir, "02-fine"))
+			},
+		},
+		{
+			name: "root without README",
+			setup: func(t *testing.T, s *Store, root string) {
+				if err := s.fs.MkdirAll(filepath.Join(root, CrumblerDir)); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, root := newTestStore(t)
+			tt.setup(t, s, root)
+
+			snap, err := s.TakeSnapshot(root)
+			if err != nil {
+				t.Fatalf("TakeSnapshot() error: %v", err)
+			}
+
+			// The snapshot agrees with the separate walks
+			done, _ := s.IsDone(root)
+			count, _ := s.Count(root)
+			tree, _ := s.List(root)
+			current, _ := s.GetCurrent(root)
+			if snap.Done() != done {
+				t.Errorf("Done() = %v, want %v", snap.Done(), done)
+			}
+			if snap.Count() != count {
+				t.Errorf("Count() = %d, want %d", snap.Count(), count)
+			}
+			if got, want := describe(snap.List()), describe(tree); got != want {
+				t.Errorf("List() = %s, want %s", got, want)
+			}
+			if got, want := describe(snap.Current()), describe(current); got != want {
+				t.Errorf("Current() = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestSnapshotReadmes(t *testing.T) {
+	t.Parallel()
+
+	s, root := setupTestProject(t)
+	taskPath := filepath.Join(root, CrumblerDir, "01-task")
+	createCrumb(t, s, taskPath)
+	snap, err := s.TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// READMEs are answered from the snapshot, not the storage
+	if err := s.fs.WriteFile(filepath.Join(taskPath, ReadmeFile), []byte("changed")); err != nil {
+		t.Fatal(err)
+	}
+	current := snap.Current()
+	if readme, _ := current.GetReadme(); readme != "" || current.State() != StateDecompose {
+		t.Errorf("README = %q, state %s; want the snapshot's empty README", readme, current.State())
+	}
+	if readme, _ := snap.List().GetReadme(); readme != "# Project" {
+		t.Errorf("root README = %q", readme)
+	}
+
+	// Writing through a snapshot crumb updates both
+	if err := current.WriteReadme("# Task\n"); err != nil {
+		t.Fatal(err)
+	}
+	if current.State() != StateExecute {
+		t.Error("state should follow WriteReadme")
+	}
+	if data, _ := s.fs.ReadFile(filepath.Join(taskPath, ReadmeFile)); string(data) != "# Task\n" {
+		t.Errorf("stored README = %q", data)
+	}
+}
+
+func TestSnapshotFind(t *testing.T) {
+	t.Parallel()
+
+	s, root := setupTestProject(t)
+	createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-parent"))
+	createCrumb(t, s, filepath.Join(root, CrumblerDir, "01-parent", "01-child"))
+	snap, err := s.TakeSnapshot(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr error
+	}{
+		{path: ".crumbler/01-parent", want: filepath.Join(CrumblerDir, "01-parent")},
+		{path: "01-parent/01-child", want: filepath.Join(CrumblerDir, "01-parent", "01-child")},
+		{path: ".crumbler", want: CrumblerDir},
+		{path: "02-missing", wantErr: ErrNotFound},
+		{path: "../etc", wantErr: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			got, err := snap.Find(tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Find() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got.RelPath != tt.want {
+				t.Errorf("Find() = %v, %v; want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// describe renders a crumb subtree for comparison in tests.
+func describe(c *Crumb) string {
+	if c == nil {
+		return "<nil>"
+	}
+	out := fmt.Sprintf("%s[%s %s leaf=%v]", c.RelPath, c.ID, c.Name, c.IsLeaf)
+	for i := range c.Children {
+		out += " " + describe(&c.Children[i])
+	}
+	return out
+}
+
+// countingStorage counts the calls made to a Storage.
+type countingStorage struct {
+	Storage
+	calls atomic.Int64
+}
+
+func (c *countingStorage) Stat(name string) (fs.FileInfo, error) {
+	c.calls.Add(1)
+	return c.Storage.Stat(name)
+}
+
+func (c *countingStorage) ReadDir(name string) ([]fs.DirEntry, error) {
+	c.calls.Add(1)
+	return c.Storage.ReadDir(name)
+}
+
+func (c *countingStorage) ReadFile(name string) ([]byte, error) {
+	c.calls.Add(1)
+	return c.Storage.ReadFile(name)
+}
+
+// buildLargeTree creates a full tree of the given fan-out and depth on disk,
+// returning a counting store for it and the project root.
+func buildLargeTree(b *testing.B, fanout, depth int) (*Store, *countingStorage, string) {
+	b.Helper()
+	root := b.TempDir()
+	var create func(dir string, level int)
+	create = func(dir string, level int) {
+		if err := OS.MkdirAll(dir); err != nil {
+			b.Fatal(err)
+		}
+		if err := OS.WriteFile(filepath.Join(dir, ReadmeFile), []byte("# Task\n")); err != nil {
+			b.Fatal(err)
+		}
+		if level == depth {
+			return
+		}
+		for i := 1; i <= fanout; i++ {
+			create(filepath.Join(dir, FormatDir(fmt.Sprintf("%02d", i), "task")), level+1)
+		}
+	}
+	create(filepath.Join(root, CrumblerDir), 0)
+
+	counting := &countingStorage{Storage: OS}
+	return NewStore(counting), counting, root
+}
+
+// BenchmarkStatus compares what 'crumbler status' reads: the separate Count,
+// List and GetCurrent walks plus each crumb's state, against one Snapshot.
+// The fscalls/op metric counts Stat, ReadDir and ReadFile calls.
+func BenchmarkStatus(b *testing.B) {
+	for _, size := range []struct{ fanout, depth int }{{5, 3}, {10, 3}} {
+		name := fmt.Sprintf("%dx%d", size.fanout, size.depth)
+
+		b.Run("separate/"+name, func(b *testing.B) {
+			s, counting, root := buildLargeTree(b, size.fanout, size.depth)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := s.Count(root); err != nil {
+					b.Fatal(err)
+				}
+				tree, err := s.List(root)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := s.GetCurrent(root); err != nil {
+					b.Fatal(err)
+				}
+				visitStates(tree)
+			}
+			b.ReportMetric(float64(counting.calls.Load())/float64(b.N), "fscalls/op")
+		})
+
+		b.Run("snapshot/"+name, func(b *testing.B) {
+			s, counting, root := buildLargeTree(b, size.fanout, size.depth)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				snap, err := s.TakeSnapshot(root)
+				if err != nil {
+					b.Fatal(err)
+				}
+				visitStates(snap.List())
+			}
+			b.ReportMetric(float64(counting.calls.Load())/float64(b.N), "fscalls/op")
+		})
+	}
+}
+
+// visitStates reads every crumb's state, as the tree displays do.
+func visitStates(c *Crumb) {
+	if c == nil {
+		return
+	}
+	c.State()
+	for i := range c.Children {
+		visitStates(&c.Children[i])
+	}
+}
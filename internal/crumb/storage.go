@@ -0,0 +1,65 @@
+package crumb
+
+import (
+	"io/fs"
+	"os"
+)
+
+// Storage is the backend a crumb tree lives in. Paths are filesystem-style
+// paths; errors for missing entries must match fs.ErrNotExist.
+type Storage interface {
+	// Stat describes the file or directory at name.
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir lists the entries of a directory, sorted by name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// ReadFile returns the contents of a file.
+	ReadFile(name string) ([]byte, error)
+	// WriteFile creates or replaces a file. Its directory must exist.
+	WriteFile(name string, data []byte) error
+	// MkdirAll creates a directory along with any missing parents.
+	MkdirAll(path string) error
+	// Rename moves a file or directory (with its contents).
+	Rename(oldpath, newpath string) error
+	// RemoveAll removes path and everything below it. Missing paths are not an error.
+	RemoveAll(path string) error
+}
+
+// OS is the Storage backed by the operating system's filesystem.
+var OS Storage = osStorage{}
+
+// osStorage implements Storage with the os package.
+type osStorage struct{}
+
+func (osStorage) Stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (osStorage) ReadDir(name string) ([]fs.DirEntry, error) { return os.ReadDir(name) }
+func (osStorage) ReadFile(name string) ([]byte, error)       { return os.ReadFile(name) }
+func (osStorage) WriteFile(name string, data []byte) error   { return os.WriteFile(name, data, 0644) }
+func (osStorage) MkdirAll(path string) error                 { return os.MkdirAll(path, 0755) }
+func (osStorage) Rename(oldpath, newpath string) error       { return os.Rename(oldpath, newpath) }
+func (osStorage) RemoveAll(path string) error                { return os.RemoveAll(path) }
+
+// Store performs crumb operations on a Storage backend.
+// The package-level functions use a Store backed by OS.
+type Store struct {
+	fs Storage
+}
+
+// NewStore returns a Store that keeps crumb trees in fs.
+func NewStore(fs Storage) *Store {
+	return &Store{fs: fs}
+}
+
+// Storage returns the store's backend.
+func (s *Store) Storage() Storage {
+	return s.fs
+}
+
+// disk is the Store behind the package-level functions.
+var disk = NewStore(OS)
+
+// exists reports whether path exists. Errors other than "not exist" count
+// as existing, so callers go on to report them from the next operation.
+func (s *Store) exists(path string) bool {
+	_, err := s.fs.Stat(path)
+	return !os.IsNotExist(err)
+}
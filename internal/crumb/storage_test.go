@@ -0,0 +1,176 @@
+package crumb
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStorage checks that both backends behave the same way.
+func TestStorage(t *testing.T) {
+	t.Parallel()
+
+	backends := []struct {
+		name string
+		new  func(t *testing.T) (Storage, string)
+	}{
+		{name: "os", new: func(t *testing.T) (Storage, string) { return OS, t.TempDir() }},
+		{name: "memory", new: func(t *testing.T) (Storage, string) {
+			return NewMemStorage(), filepath.Join(string(filepath.Separator), "tmp")
+		}},
+	}
+
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			t.Parallel()
+			testStorage(t, backend.new)
+		})
+	}
+}
+
+func testStorage(t *testing.T, newStorage func(t *testing.T) (Storage, string)) {
+	t.Run("files and directories", func(t *testing.T) {
+		t.Parallel()
+		fsys, dir := newStorage(t)
+		nested := filepath.Join(dir, "a", "b")
+		if err := fsys.MkdirAll(nested); err != nil {
+			t.Fatalf("MkdirAll() error: %v", err)
+		}
+		file := filepath.Join(nested, "file.txt")
+		if err := fsys.WriteFile(file, []byte("hello")); err != nil {
+			t.Fatalf("WriteFile() error: %v", err)
+		}
+
+		data, err := fsys.ReadFile(file)
+		if err != nil || string(data) != "hello" {
+			t.Errorf("ReadFile() = %q, %v", data, err)
+		}
+		info, err := fsys.Stat(file)
+		if err != nil || info.IsDir() || info.Size() != 5 || info.Name() != "file.txt" {
+			t.Errorf("Stat(file) = %v, %v", info, err)
+		}
+		if info, err := fsys.Stat(nested); err != nil || !info.IsDir() {
+			t.Errorf("Stat(dir) = %v, %v", info, err)
+		}
+	})
+
+	t.Run("missing entries", func(t *testing.T) {
+		t.Parallel()
+		fsys, dir := newStorage(t)
+		missing := filepath.Join(dir, "missing")
+		if _, err := fsys.Stat(missing); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat() error = %v, want fs.ErrNotExist", err)
+		}
+		if _, err := fsys.ReadDir(missing); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadDir() error = %v, want fs.ErrNotExist", err)
+		}
+		if _, err := fsys.ReadFile(missing); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("ReadFile() error = %v, want fs.ErrNotExist", err)
+		}
+		if err := fsys.WriteFile(filepath.Join(missing, "file"), nil); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("WriteFile() without parent error = %v, want fs.ErrNotExist", err)
+		}
+		if err := fsys.RemoveAll(missing); err != nil {
+			t.Errorf("RemoveAll() of missing path error: %v", err)
+		}
+	})
+
+	t.Run("read dir is sorted and shallow", func(t *testing.T) {
+		t.Parallel()
+		fsys, dir := newStorage(t)
+		for _, name := range []string{"02-b", "01-a/01-nested", "10-c"} {
+			if err := fsys.MkdirAll(filepath.Join(dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := fsys.WriteFile(filepath.Join(dir, "README.md"), nil); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("ReadDir() error: %v", err)
+		}
+		var names []string
+		for _, entry := range entries {
+			name := entry.Name()
+			if entry.IsDir() {
+				name += "/"
+			}
+			names = append(names, name)
+		}
+		if got := strings.Join(names, " "); got != "01-a/ 02-b/ 10-c/ README.md" {
+			t.Errorf("ReadDir() = %s", got)
+		}
+	})
+
+	t.Run("rename moves contents", func(t *testing.T) {
+		t.Parallel()
+		fsys, dir := newStorage(t)
+		oldPath := filepath.Join(dir, "01-old")
+		if err := fsys.MkdirAll(filepath.Join(oldPath, "01-child")); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.WriteFile(filepath.Join(oldPath, "01-child", "README.md"), []byte("x")); err != nil {
+			t.Fatal(err)
+		}
+
+		newPath := filepath.Join(dir, "01-new")
+		if err := fsys.Rename(oldPath, newPath); err != nil {
+			t.Fatalf("Rename() error: %v", err)
+		}
+		if _, err := fsys.Stat(oldPath); !errors.Is(err, fs.ErrNotExist) {
+			t.Error("old path should be gone")
+		}
+		if data, err := fsys.ReadFile(filepath.Join(newPath, "01-child", "README.md")); err != nil || string(data) != "x" {
+			t.Errorf("moved file = %q, %v", data, err)
+		}
+	})
+
+	t.Run("remove all removes subtree", func(t *testing.T) {
+		t.Parallel()
+		fsys, dir := newStorage(t)
+		sub := filepath.Join(dir, "sub")
+		if err := fsys.MkdirAll(filepath.Join(sub, "deep")); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.WriteFile(filepath.Join(sub, "deep", "f"), nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.RemoveAll(sub); err != nil {
+			t.Fatalf("RemoveAll() error: %v", err)
+		}
+		if _, err := fsys.Stat(filepath.Join(sub, "deep", "f")); !errors.Is(err, fs.ErrNotExist) {
+			t.Error("subtree should be removed")
+		}
+		if _, err := fsys.Stat(dir); err != nil {
+			t.Error("parent should be kept")
+		}
+	})
+}
+
+func TestMemStorageIsolation(t *testing.T) {
+	t.Parallel()
+
+	// Stores on separate MemStorages never see each other's crumbs
+	a, b := NewStore(NewMemStorage()), NewStore(NewMemStorage())
+	if _, err := a.Create("/project", "Only In A"); err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := b.IsDone("/project"); !done {
+		t.Error("second store should not see the first store's crumbs")
+	}
+
+	current, err := a.GetCurrent("/project")
+	if err != nil || current.Name != "only-in-a" {
+		t.Fatalf("GetCurrent() = %v, %v", current, err)
+	}
+	if err := current.WriteReadme("# A\n"); err != nil {
+		t.Fatal(err)
+	}
+	if readme, _ := current.GetReadme(); readme != "# A\n" || current.State() != StateExecute {
+		t.Errorf("README = %q, state %s", readme, current.State())
+	}
+}
@@ -1,6 +1,7 @@
 package crumb
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 )
@@ -11,44 +12,74 @@ import (
 // 2. If has children (01-*/), recurse into first (sorted by ID)
 // 3. If no children, this is the current crumb (leaf)
 // 4. If directory doesn't exist or has no README.md, return nil
-func traverse(dir string) (*Crumb, error) {
-	// Check if directory exists
-	info, err := os.Stat(dir)
+func (s *Store) traverse(dir string) (*Crumb, error) {
+	// One read per level tells both whether this is a valid crumb
+	// (has README.md) and which children it has
+	d, err := s.readCrumbDir(dir)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, err
 	}
-	if !info.IsDir() {
+	if !d.hasReadme {
 		return nil, nil
 	}
 
-	// Check if this is a valid crumb (has README.md)
-	readmePath := filepath.Join(dir, ReadmeFile)
-	if _, err := os.Stat(readmePath); os.IsNotExist(err) {
-		return nil, nil
+	// If has children, recurse into first child
+	if len(d.children) > 0 {
+		return s.traverse(d.children[0])
 	}
 
-	// Get child directories
-	children, err := ListChildDirs(dir)
+	// This is a leaf crumb
+	id, name := ParseDir(filepath.Base(dir))
+	return &Crumb{
+		Path:   dir,
+		Name:   name,
+		ID:     id,
+		IsLeaf: true,
+		fs:     s.fs,
+	}, nil
+}
+
+// crumbDir is the result of reading a crumb directory once.
+type crumbDir struct {
+	hasReadme bool     // README.md exists
+	children  []string // Child crumb directories, sorted by ID
+}
+
+// readCrumbDir lists dir once. A missing path or a file reads as an empty
+// directory without a README.
+func (s *Store) readCrumbDir(dir string) (crumbDir, error) {
+	var d crumbDir
+	entries, err := s.fs.ReadDir(dir)
 	if err != nil {
-		return nil, err
+		if os.IsNotExist(err) {
+			return d, nil
+		}
+		if info, serr := s.fs.Stat(dir); serr == nil && !info.IsDir() {
+			return d, nil
+		}
+		return d, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	// If has children, recurse into first child
-	if len(children) > 0 {
-		return traverse(children[0])
+	// Entries are sorted by name, which sorts children by ID
+	for _, entry := range entries {
+		if entry.Name() == ReadmeFile {
+			d.hasReadme = true
+			continue
+		}
+		if !entry.IsDir() {
+			continue
+		}
+		if id, _ := ParseDir(entry.Name()); id != "" {
+			d.children = append(d.children, filepath.Join(dir, entry.Name()))
+		}
 	}
-
-	// This is a leaf crumb - build and return it
-	return buildCrumb(dir)
+	return d, nil
 }
 
 // traverseAll collects all crumbs in the tree.
-func traverseAll(dir string) ([]Crumb, error) {
+func (s *Store) traverseAll(dir string) ([]Crumb, error) {
 	// Check if directory exists
-	info, err := os.Stat(dir)
+	info, err := s.fs.Stat(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return nil, nil
@@ -61,12 +92,12 @@ func traverseAll(dir string) ([]Crumb, error) {
 
 	// Check if this is a valid crumb (has README.md)
 	readmePath := filepath.Join(dir, ReadmeFile)
-	if _, err := os.Stat(readmePath); os.IsNotExist(err) {
+	if !s.exists(readmePath) {
 		return nil, nil
 	}
 
 	// Get child directories
-	children, err := ListChildDirs(dir)
+	children, err := s.ListChildDirs(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -75,7 +106,7 @@ func traverseAll(dir string) ([]Crumb, error) {
 
 	// Recursively collect children
 	for _, childPath := range children {
-		childCrumbs, err := traverseAll(childPath)
+		childCrumbs, err := s.traverseAll(childPath)
 		if err != nil {
 			return nil, err
 		}
@@ -83,7 +114,7 @@ func traverseAll(dir string) ([]Crumb, error) {
 	}
 
 	// Build crumb for this directory
-	crumb, err := buildCrumb(dir)
+	crumb, err := s.buildCrumb(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -96,15 +127,15 @@ func traverseAll(dir string) ([]Crumb, error) {
 }
 
 // countCrumbs counts all crumbs in the tree (excluding root).
-func countCrumbs(dir string) (int, error) {
-	children, err := ListChildDirs(dir)
+func (s *Store) countCrumbs(dir string) (int, error) {
+	children, err := s.ListChildDirs(dir)
 	if err != nil {
 		return 0, err
 	}
 
 	count := len(children)
 	for _, child := range children {
-		childCount, err := countCrumbs(child)
+		childCount, err := s.countCrumbs(child)
 		if err != nil {
 			return 0, err
 		}
@@ -115,9 +146,9 @@ func countCrumbs(dir string) (int, error) {
 }
 
 // buildCrumb creates a Crumb from a directory path.
-func buildCrumb(dir string) (*Crumb, error) {
+func (s *Store) buildCrumb(dir string) (*Crumb, error) {
 	// Get child directories to determine if leaf
-	children, err := ListChildDirs(dir)
+	children, err := s.ListChildDirs(dir)
 	if err != nil {
 		return nil, err
 	}
@@ -130,5 +161,6 @@ func buildCrumb(dir string) (*Crumb, error) {
 		Name:   name,
 		ID:     id,
 		IsLeaf: len(children) == 0,
+		fs:     s.fs,
 	}, nil
 }
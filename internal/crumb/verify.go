@@ -0,0 +1,171 @@
+package crumb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// VerifyFile lists verification commands for a crumb, one per line.
+	// Blank lines and lines starting with # are ignored.
+	VerifyFile = "VERIFY"
+	// VerifySkipLog records skipped verifications in the project root.
+	VerifySkipLog = ".crumbler-verify-skips.jsonl"
+	// DefaultVerifyTimeout bounds each verification command.
+	DefaultVerifyTimeout = 10 * time.Minute
+)
+
+// VerifyError reports a verification command that failed.
+type VerifyError struct {
+	Command string // Command that failed
+	Output  string // Combined stdout and stderr
+	Err     error  // Underlying exit or timeout error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("verification failed: %s: %v", e.Command, e.Err)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// VerifySkip is one entry in the verification skip log.
+type VerifySkip struct {
+	Time     time.Time `json:"time"`
+	Crumb    string    `json:"crumb"`
+	Reason   string    `json:"reason"`
+	Commands []string  `json:"commands"`
+}
+
+// VerifyCommands returns the verification commands for a crumb on disk.
+// See Store.VerifyCommands.
+func VerifyCommands(root, crumbPath string) ([]string, error) {
+	return disk.VerifyCommands(root, crumbPath)
+}
+
+// VerifyCommands returns the verification commands that apply to a crumb:
+// those declared by its ancestors (outermost first), then its own.
+func (s *Store) VerifyCommands(root, crumbPath string) ([]string, error) {
+	dirs := crumbAndAncestors(root, crumbPath)
+
+	var commands []string
+	for i := len(dirs) - 1; i >= 0; i-- {
+		content, err := s.fs.ReadFile(filepath.Join(dirs[i], VerifyFile))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read %s: %w", VerifyFile, err)
+		}
+		commands = append(commands, parseVerifyFile(string(content))...)
+	}
+
+	return commands, nil
+}
+
+// crumbAndAncestors returns crumbPath and its ancestors up to .crumbler,
+// innermost first.
+func crumbAndAncestors(root, crumbPath string) []string {
+	crumblerPath := filepath.Join(root, CrumblerDir)
+	var dirs []string
+	for dir := crumbPath; ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+		if dir == crumblerPath || !strings.HasPrefix(dir, crumblerPath) {
+			break
+		}
+	}
+	return dirs
+}
+
+// parseVerifyFile extracts commands from VERIFY file content.
+func parseVerifyFile(content string) []string {
+	var commands []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands
+}
+
+// RunVerify runs each command in the project root, stopping at the first failure.
+// Each command is killed after timeout. Returns a *VerifyError on failure.
+func RunVerify(root string, commands []string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = DefaultVerifyTimeout
+	}
+
+	for _, command := range commands {
+		if err := runVerifyCommand(root, command, timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runVerifyCommand runs a single verification command through the shell.
+func runVerifyCommand(root, command string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = ProjectRoot(root)
+	setProcessGroup(cmd)
+	// Don't wait on grandchildren that keep the output pipe open after a timeout
+	cmd.WaitDelay = time.Second
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return &VerifyError{Command: command, Output: output.String(), Err: err}
+	}
+	return nil
+}
+
+// RecordVerifySkip appends a skipped verification to the skip log in the
+// project directory, which for a named plan is the enclosing project.
+func RecordVerifySkip(root string, skip VerifySkip) error {
+	if skip.Time.IsZero() {
+		skip.Time = time.Now().UTC()
+	}
+
+	line, err := json.Marshal(skip)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(ProjectRoot(root), VerifySkipLog)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", VerifySkipLog, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to record skipped verification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package crumb
+
+import "os/exec"
+
+// setProcessGroup is a no-op where there are no process groups; a timeout
+// kills only the shell.
+func setProcessGroup(cmd *exec.Cmd) {}
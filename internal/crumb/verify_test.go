@@ -0,0 +1,188 @@
+package crumb
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerifyCommands(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no VERIFY files", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		crumbPath := filepath.Join(root, CrumblerDir, "01-task")
+		createCrumb(t, s, crumbPath)
+
+		commands, err := s.VerifyCommands(root, crumbPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(commands) != 0 {
+			t.Errorf("expected no commands, got %v", commands)
+		}
+	})
+
+	t.Run("inherits ancestor commands outermost first", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		parentPath := filepath.Join(root, CrumblerDir, "01-parent")
+		childPath := filepath.Join(parentPath, "01-child")
+		createCrumb(t, s, parentPath)
+		createCrumb(t, s, childPath)
+
+		writeVerify(t, s, filepath.Join(root, CrumblerDir), "go vet ./...\n")
+		writeVerify(t, s, parentPath, "# comment\n\ngo test ./...\n")
+		writeVerify(t, s, childPath, "make lint\n")
+
+		commands, err := s.VerifyCommands(root, childPath)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		expected := []string{"go vet ./...", "go test ./...", "make lint"}
+		if strings.Join(commands, "|") != strings.Join(expected, "|") {
+			t.Errorf("VerifyCommands() = %v, want %v", commands, expected)
+		}
+	})
+
+	t.Run("root crumb", func(t *testing.T) {
+		t.Parallel()
+
+		s, root := setupTestProject(t)
+		writeVerify(t, s, filepath.Join(root, CrumblerDir), "true\n")
+
+		commands, err := s.VerifyCommands(root, filepath.Join(root, CrumblerDir))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(commands) != 1 || commands[0] != "true" {
+			t.Errorf("VerifyCommands() = %v, want [true]", commands)
+		}
+	})
+}
+
+func TestRunVerify(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses POSIX shell commands")
+	}
+
+	t.Run("all commands pass", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		if err := RunVerify(root, []string{"true", "echo ok"}, time.Minute); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("failure reports command and output", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		err := RunVerify(root, []string{"true", "echo broken; exit 3", "touch never"}, time.Minute)
+
+		var verr *VerifyError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected *VerifyError, got %v", err)
+		}
+		if verr.Command != "echo broken; exit 3" {
+			t.Errorf("Command = %q", verr.Command)
+		}
+		if !strings.Contains(verr.Output, "broken") {
+			t.Errorf("Output = %q, want to contain %q", verr.Output, "broken")
+		}
+		if _, err := os.Stat(filepath.Join(root, "never")); !os.IsNotExist(err) {
+			t.Error("commands after a failure should not run")
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		err := RunVerify(root, []string{"sleep 5"}, 50*time.Millisecond)
+
+		var verr *VerifyError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected *VerifyError, got %v", err)
+		}
+		if !strings.Contains(verr.Error(), "timed out") {
+			t.Errorf("expected timeout error, got %v", verr)
+		}
+	})
+
+	t.Run("timeout kills background commands", func(t *testing.T) {
+		t.Parallel()
+
+		root := t.TempDir()
+		if err := RunVerify(root, []string{"(sleep 1; touch late) & wait"}, 50*time.Millisecond); err == nil {
+			t.Fatal("expected timeout error")
+		}
+		time.Sleep(1500 * time.Millisecond)
+		if _, err := os.Stat(filepath.Join(root, "late")); !os.IsNotExist(err) {
+			t.Error("background command should be killed with the shell")
+		}
+	})
+}
+
+func TestRecordVerifySkip(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	skip := VerifySkip{Crumb: ".crumbler/01-task", Reason: "flaky CI", Commands: []string{"go test ./..."}}
+	if err := RecordVerifySkip(root, skip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := RecordVerifySkip(root, skip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, VerifySkipLog))
+	if err != nil {
+		t.Fatalf("failed to read skip log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(lines))
+	}
+
+	var entry VerifySkip
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid log entry: %v", err)
+	}
+	if entry.Reason != "flaky CI" || entry.Crumb != ".crumbler/01-task" || entry.Time.IsZero() {
+		t.Errorf("unexpected log entry: %+v", entry)
+	}
+}
+
+func TestRecordVerifySkipNamedPlan(t *testing.T) {
+	t.Parallel()
+
+	// A plan's skips are logged with the project's
+	project := t.TempDir()
+	root := filepath.Join(project, PlansDir, "debt")
+	skip := VerifySkip{Crumb: ".crumbler-plans/debt/.crumbler/01-task", Reason: "flaky CI"}
+	if err := RecordVerifySkip(root, skip); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(project, VerifySkipLog)); err != nil {
+		t.Errorf("skip log not in the project directory: %v", err)
+	}
+}
+
+// writeVerify writes a VERIFY file into a crumb directory.
+func writeVerify(t *testing.T, s *Store, crumbPath, content string) {
+	t.Helper()
+	if err := s.fs.WriteFile(filepath.Join(crumbPath, VerifyFile), []byte(content)); err != nil {
+		t.Fatalf("failed to write VERIFY: %v", err)
+	}
+}
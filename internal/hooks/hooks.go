@@ -0,0 +1,138 @@
+// Package hooks runs user-provided executables around crumbler operations.
+// Hooks live in .crumbler/hooks/ and are named after the event they handle
+// (pre-create, post-create, pre-delete, post-delete, pre-prompt). Each hook
+// receives the crumb path and state in environment variables and a JSON
+// payload on stdin. A non-zero exit from a pre-hook aborts the operation.
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// Dir is the hook directory name inside .crumbler/.
+// It has no ID prefix, so it is never listed as a crumb.
+const Dir = "hooks"
+
+// Hook events.
+const (
+	PreCreate  = "pre-create"
+	PostCreate = "post-create"
+	PreDelete  = "pre-delete"
+	PostDelete = "post-delete"
+	PrePrompt  = "pre-prompt"
+)
+
+// Payload is the JSON document written to a hook's stdin.
+type Payload struct {
+	Event       string   `json:"event"`
+	ProjectRoot string   `json:"project_root"`      // Project directory, also for a named plan
+	Crumb       string   `json:"crumb"`             // Crumb path relative to project root ("" when done)
+	State       string   `json:"state"`             // DECOMPOSE, EXECUTE or DONE
+	Names       []string `json:"names,omitempty"`   // pre-create: names about to be created
+	Created     []string `json:"created,omitempty"` // post-create: created crumb paths
+
+	root string // Crumbler root the hooks are in, if not ProjectRoot
+}
+
+// Error reports a hook that exited unsuccessfully.
+type Error struct {
+	Event string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s hook failed: %v", e.Event, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Path returns the path of the hook for an event.
+func Path(root, event string) string {
+	return filepath.Join(root, crumb.CrumblerDir, Dir, event)
+}
+
+// NewPayload builds the payload for an event on a crumb under root, which
+// may be a named plan. A nil crumb means the project is done.
+func NewPayload(root, event string, c *crumb.Crumb) Payload {
+	projectRoot := crumb.ProjectRoot(root)
+	payload := Payload{Event: event, ProjectRoot: projectRoot, State: crumb.StateDone, root: root}
+	if c != nil {
+		payload.Crumb = relPath(projectRoot, c.Path)
+		payload.State = c.State()
+	}
+	return payload
+}
+
+// Run runs the hook for payload.Event if one is installed.
+// Hook output goes to stderr so it never mixes with command output
+// (e.g. the prompt). Returns an *Error if the hook exits non-zero.
+func Run(payload Payload) error {
+	return run(payload, os.Stderr)
+}
+
+// RunTo runs the hook like Run, sending its output to out instead, for
+// callers that own the terminal.
+func RunTo(payload Payload, out io.Writer) error {
+	return run(payload, out)
+}
+
+// run runs the hook, sending its output to out. Hooks run in the project
+// directory, but are looked up in the plan they belong to.
+func run(payload Payload, out io.Writer) error {
+	root := payload.root
+	if root == "" {
+		root = payload.ProjectRoot
+	}
+	path := Path(root, payload.Event)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil // No hook installed
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		fmt.Fprintf(out, "warning: ignoring %s hook (not executable): %s\n", payload.Event, path)
+		return nil
+	}
+
+	input, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(path)
+	cmd.Dir = payload.ProjectRoot
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.Env = append(os.Environ(),
+		"CRUMBLER_HOOK="+payload.Event,
+		"CRUMBLER_PROJECT_ROOT="+payload.ProjectRoot,
+		"CRUMBLER_CRUMB_PATH="+payload.Crumb,
+		"CRUMBLER_CRUMB_STATE="+payload.State,
+	)
+
+	if err := cmd.Run(); err != nil {
+		return &Error{Event: payload.Event, Err: err}
+	}
+	return nil
+}
+
+// relPath returns a path relative to the project root.
+func relPath(root, fullPath string) string {
+	rel, err := filepath.Rel(root, fullPath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fullPath
+	}
+	return rel
+}
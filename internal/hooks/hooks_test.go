@@ -0,0 +1,195 @@
+package hooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// setupProject creates a project with one leaf crumb and returns its root.
+func setupProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	taskPath := filepath.Join(root, crumb.CrumblerDir, "01-task")
+	if err := os.MkdirAll(taskPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{
+		filepath.Join(root, crumb.CrumblerDir, crumb.ReadmeFile),
+		filepath.Join(taskPath, crumb.ReadmeFile),
+	} {
+		if err := os.WriteFile(path, []byte("Do it"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// installHook writes a shell script hook for an event.
+func installHook(t *testing.T, root, event, script string, mode os.FileMode) {
+	t.Helper()
+	dir := filepath.Join(root, crumb.CrumblerDir, Dir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, event), []byte("#!/bin/sh\n"+script), mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script hooks")
+	}
+
+	t.Run("no hook installed", func(t *testing.T) {
+		root := setupProject(t)
+		if err := Run(Payload{Event: PreCreate, ProjectRoot: root}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("receives environment and payload", func(t *testing.T) {
+		root := setupProject(t)
+		installHook(t, root, PreDelete, `echo "$CRUMBLER_HOOK|$CRUMBLER_CRUMB_PATH|$CRUMBLER_CRUMB_STATE"; cat`, 0755)
+
+		current, err := crumb.GetCurrent(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		payload := NewPayload(root, PreDelete, current)
+
+		var out bytes.Buffer
+		if err := run(payload, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		lines := strings.SplitN(out.String(), "\n", 2)
+		expectedEnv := "pre-delete|" + filepath.Join(crumb.CrumblerDir, "01-task") + "|EXECUTE"
+		if lines[0] != expectedEnv {
+			t.Errorf("environment = %q, want %q", lines[0], expectedEnv)
+		}
+
+		var got Payload
+		if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+			t.Fatalf("stdin is not a JSON payload: %v", err)
+		}
+		if got.Event != PreDelete || got.Crumb != payload.Crumb || got.State != crumb.StateExecute {
+			t.Errorf("unexpected payload: %+v", got)
+		}
+	})
+
+	t.Run("non-zero exit returns error", func(t *testing.T) {
+		root := setupProject(t)
+		installHook(t, root, PreCreate, "exit 2\n", 0755)
+
+		err := run(Payload{Event: PreCreate, ProjectRoot: root}, &bytes.Buffer{})
+		var herr *Error
+		if !errors.As(err, &herr) {
+			t.Fatalf("expected *Error, got %v", err)
+		}
+		if herr.Event != PreCreate {
+			t.Errorf("Event = %q, want %q", herr.Event, PreCreate)
+		}
+	})
+
+	t.Run("non-executable hook is ignored", func(t *testing.T) {
+		root := setupProject(t)
+		installHook(t, root, PrePrompt, "exit 1\n", 0644)
+
+		var out bytes.Buffer
+		if err := run(Payload{Event: PrePrompt, ProjectRoot: root}, &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(out.String(), "not executable") {
+			t.Errorf("expected warning about non-executable hook, got %q", out.String())
+		}
+	})
+}
+
+func TestRunNamedPlan(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell script hooks")
+	}
+
+	// The plan's hooks run in the project, and see paths relative to it
+	project := t.TempDir()
+	root := filepath.Join(project, crumb.PlansDir, "debt")
+	taskPath := filepath.Join(root, crumb.CrumblerDir, "01-task")
+	if err := os.MkdirAll(taskPath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(taskPath, crumb.ReadmeFile), []byte("Do it"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	installHook(t, root, PreDelete, `echo "$(pwd -P)|$CRUMBLER_PROJECT_ROOT|$CRUMBLER_CRUMB_PATH"; cat`, 0755)
+
+	current, err := crumb.GetCurrent(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run(NewPayload(root, PreDelete, current), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	realProject, err := filepath.EvalSymlinks(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crumbPath := filepath.Join(crumb.PlansDir, "debt", crumb.CrumblerDir, "01-task")
+	lines := strings.SplitN(out.String(), "\n", 2)
+	if want := realProject + "|" + project + "|" + crumbPath; lines[0] != want {
+		t.Errorf("directory and environment = %q, want %q", lines[0], want)
+	}
+
+	var got Payload
+	if err := json.Unmarshal([]byte(lines[1]), &got); err != nil {
+		t.Fatalf("stdin is not a JSON payload: %v", err)
+	}
+	if got.ProjectRoot != project || got.Crumb != crumbPath {
+		t.Errorf("payload = %+v, want project_root %q and crumb %q", got, project, crumbPath)
+	}
+}
+
+func TestNewPayloadDone(t *testing.T) {
+	t.Parallel()
+
+	payload := NewPayload("/project", PrePrompt, nil)
+	if payload.State != crumb.StateDone || payload.Crumb != "" {
+		t.Errorf("unexpected payload for done project: %+v", payload)
+	}
+}
+
+func TestHookDirIsNotACrumb(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	installHook(t, root, PostCreate, "true\n", 0755)
+	crumblerPath := filepath.Join(root, crumb.CrumblerDir)
+
+	children, err := crumb.ListChildDirs(crumblerPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(children) != 1 || filepath.Base(children[0]) != "01-task" {
+		t.Errorf("ListChildDirs() = %v, want only 01-task", children)
+	}
+
+	count, err := crumb.Count(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("Count() = %d, want 2 (root + 01-task)", count)
+	}
+}
@@ -0,0 +1,154 @@
+// Package ledger records what agents spent working on each crumb.
+//
+// Entries are appended to a JSON Lines file in the project root, next to
+// .crumbler/ rather than inside it, so they survive deleting crumbs (and
+// the whole tree once the project is done). Crumbs are identified by their
+// path relative to the project root, so rolling the ledger up the tree also
+// counts descendants that have since been deleted.
+package ledger
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// File is the ledger's name in the project root.
+const File = ".crumbler-ledger.jsonl"
+
+// Entry credits usage to one crumb.
+type Entry struct {
+	Time                time.Time `json:"time"`
+	Crumb               string    `json:"crumb"` // Crumb path relative to project root
+	Session             string    `json:"session,omitempty"`
+	InputTokens         int       `json:"input_tokens"`
+	OutputTokens        int       `json:"output_tokens"`
+	CacheReadTokens     int       `json:"cache_read_tokens"`
+	CacheCreationTokens int       `json:"cache_creation_tokens"`
+	CostUSD             float64   `json:"cost_usd"`
+}
+
+// Totals sums entries.
+type Totals struct {
+	InputTokens         int     `json:"input_tokens"`
+	OutputTokens        int     `json:"output_tokens"`
+	CacheReadTokens     int     `json:"cache_read_tokens"`
+	CacheCreationTokens int     `json:"cache_creation_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+// Add adds an entry to the totals.
+func (t *Totals) Add(e Entry) {
+	t.InputTokens += e.InputTokens
+	t.OutputTokens += e.OutputTokens
+	t.CacheReadTokens += e.CacheReadTokens
+	t.CacheCreationTokens += e.CacheCreationTokens
+	t.CostUSD += e.CostUSD
+}
+
+// Tokens returns the total number of tokens.
+func (t Totals) Tokens() int {
+	return t.InputTokens + t.OutputTokens + t.CacheReadTokens + t.CacheCreationTokens
+}
+
+// Path returns the ledger's path for the project at root.
+func Path(root string) string {
+	return filepath.Join(root, File)
+}
+
+// Append adds entries to the ledger of the project at root.
+// Each entry is one line, written with a single append.
+func Append(root string, entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var data []byte
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("failed to encode ledger entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	file, err := os.OpenFile(Path(root), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open ledger: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write ledger: %w", err)
+	}
+	return file.Close()
+}
+
+// Read returns the entries in the ledger of the project at root.
+// A missing ledger has no entries.
+func Read(root string) ([]Entry, error) {
+	file, err := os.Open(Path(root))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to open ledger: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", File, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ledger: %w", err)
+	}
+	return entries, nil
+}
+
+// Rollup returns the totals for each crumb path in entries and for each of
+// its ancestors up to the root crumb, so every crumb's totals include its
+// descendants, deleted or not.
+func Rollup(entries []Entry) map[string]*Totals {
+	totals := make(map[string]*Totals)
+	for _, e := range entries {
+		for _, path := range Ancestors(e.Crumb) {
+			t, ok := totals[path]
+			if !ok {
+				t = &Totals{}
+				totals[path] = t
+			}
+			t.Add(e)
+		}
+	}
+	return totals
+}
+
+// Ancestors returns path and the paths of its ancestor crumbs, ending with
+// the root crumb (the .crumbler directory).
+func Ancestors(path string) []string {
+	path = filepath.Clean(path)
+	paths := []string{path}
+	for filepath.Base(path) != crumb.CrumblerDir {
+		parent := filepath.Dir(path)
+		if parent == path || parent == "." {
+			break
+		}
+		path = parent
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,104 @@
+package ledger
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendRead(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	entries, err := Read(root)
+	if err != nil || entries != nil {
+		t.Fatalf("Read() of missing ledger = %v, %v; want nil, nil", entries, err)
+	}
+
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []Entry{
+		{Time: at, Crumb: ".crumbler/01-a", Session: "s1", InputTokens: 10, OutputTokens: 5, CostUSD: 0.25},
+		{Time: at, Crumb: ".crumbler", CacheReadTokens: 100, CacheCreationTokens: 20},
+	}
+	if err := Append(root, want[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(root, want[1:]...); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(root); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %+v, want %+v", got, want)
+	}
+
+	// A damaged line is reported, not skipped
+	if err := os.WriteFile(Path(root), []byte("{\"crumb\":\".crumbler\"}\n\n{oops\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(root); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Read() error = %v, want it to name line 3", err)
+	}
+}
+
+func TestRollup(t *testing.T) {
+	t.Parallel()
+
+	totals := Rollup([]Entry{
+		{Crumb: ".crumbler/01-a/01-b", InputTokens: 1, CostUSD: 1},
+		{Crumb: ".crumbler/01-a", InputTokens: 2, CostUSD: 2},
+		{Crumb: ".crumbler/02-c", OutputTokens: 4, CostUSD: 4},
+		{Crumb: ".crumbler", CacheReadTokens: 8},
+	})
+
+	tests := []struct {
+		path   string
+		tokens int
+		cost   float64
+	}{
+		{path: ".crumbler", tokens: 15, cost: 7},
+		{path: ".crumbler/01-a", tokens: 3, cost: 3},
+		{path: ".crumbler/01-a/01-b", tokens: 1, cost: 1},
+		{path: ".crumbler/02-c", tokens: 4, cost: 4},
+	}
+	if len(totals) != len(tests) {
+		t.Errorf("Rollup() has %d paths, want %d", len(totals), len(tests))
+	}
+	for _, tt := range tests {
+		got := totals[tt.path]
+		if got == nil || got.Tokens() != tt.tokens || got.CostUSD != tt.cost {
+			t.Errorf("Rollup()[%q] = %+v, want %d tokens, $%v", tt.path, got, tt.tokens, tt.cost)
+		}
+	}
+}
+
+func TestAncestors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: ".crumbler", want: []string{".crumbler"}},
+		{path: ".crumbler/01-a/02-b", want: []string{".crumbler/01-a/02-b", ".crumbler/01-a", ".crumbler"}},
+		{path: ".crumbler-plans/p/.crumbler/01-a", want: []string{".crumbler-plans/p/.crumbler/01-a", ".crumbler-plans/p/.crumbler"}},
+		{path: "elsewhere", want: []string{"elsewhere"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+			if got := Ancestors(tt.path); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Ancestors(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+// Package mcp exposes crumbler operations as Model Context Protocol tools.
+// The server speaks JSON-RPC 2.0 over stdio, one message per line, so any
+// MCP-capable agent can drive crumbler without parsing human-oriented text.
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// ProtocolVersion is the MCP protocol revision this server implements.
+const ProtocolVersion = "2025-06-18"
+
+// JSON-RPC 2.0 error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request is an incoming JSON-RPC message. Notifications have no ID.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC message.
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is a protocol-level error. Tool failures are reported in the
+// tool result instead (see ToolResult.IsError).
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Server serves crumbler tools for a project.
+type Server struct {
+	project *crumbler.Project
+	mu      sync.Mutex // serializes writes to out
+	out     io.Writer
+}
+
+// New returns an MCP server for project.
+func New(project *crumbler.Project) *Server {
+	return &Server{project: project}
+}
+
+// Serve reads requests from in and writes responses to out until in is
+// closed. Requests are handled one at a time, in order.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+			continue
+		}
+
+		result, rerr := s.handle(&req)
+		if req.ID == nil {
+			continue // Notifications get no response
+		}
+		resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rerr}
+		if rerr == nil && result == nil {
+			resp.Result = struct{}{}
+		}
+		if err := s.write(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle dispatches a request to its method.
+func (s *Server) handle(req *request) (any, *rpcError) {
+	if req.JSONRPC != "2.0" {
+		return nil, &rpcError{Code: codeInvalidRequest, Message: `jsonrpc must be "2.0"`}
+	}
+
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]any{"tools": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "crumbler", "version": version()},
+			"instructions":    "Crumbler organizes work into crumbs: directories with README.md files, worked depth-first. Call get_prompt to learn what to do next.",
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return nil, nil
+	case "tools/list":
+		return map[string]any{"tools": toolDefinitions()}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		tool, ok := tools[params.Name]
+		if !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+		}
+		return s.callTool(tool, params.Arguments), nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+// write sends one message followed by a newline.
+func (s *Server) write(resp response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.out.Write(append(data, '\n'))
+	return err
+}
+
+// version returns the module version of the running binary.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "dev"
+}
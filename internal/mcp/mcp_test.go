@@ -0,0 +1,316 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// rpc sends requests (one per line) to a fresh server and returns the
+// decoded responses in order.
+func rpc(t *testing.T, root string, requests ...string) []map[string]any {
+	t.Helper()
+	project, err := crumbler.Open(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := New(project).Serve(strings.NewReader(strings.Join(requests, "\n")+"\n"), &out); err != nil {
+		t.Fatalf("Serve() error: %v", err)
+	}
+
+	var responses []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var resp map[string]any
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid response %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+// call invokes one tool and returns its result.
+func call(t *testing.T, root, name, args string) map[string]any {
+	t.Helper()
+	responses := rpc(t, root, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"`+name+`","arguments":`+args+`}}`)
+	if len(responses) != 1 {
+		t.Fatalf("got %d responses, want 1", len(responses))
+	}
+	result, ok := responses[0]["result"].(map[string]any)
+	if !ok {
+		t.Fatalf("no result in %v", responses[0])
+	}
+	return result
+}
+
+// structured returns the structured content of a tool result.
+func structured(t *testing.T, result map[string]any) map[string]any {
+	t.Helper()
+	content, ok := result["structuredContent"].(map[string]any)
+	if !ok {
+		t.Fatalf("no structured content in %v", result)
+	}
+	return content
+}
+
+// errorCode returns the error code of a failed tool result, or "".
+func errorCode(t *testing.T, result map[string]any) string {
+	t.Helper()
+	if result["isError"] != true {
+		return ""
+	}
+	return structured(t, result)["error"].(map[string]any)["code"].(string)
+}
+
+func TestProtocol(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	responses := rpc(t, root,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"bogus"}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"bogus"}}`,
+	)
+
+	// The notification gets no response
+	if len(responses) != 6 {
+		t.Fatalf("got %d responses, want 6: %v", len(responses), responses)
+	}
+
+	init := responses[0]["result"].(map[string]any)
+	if init["protocolVersion"] != ProtocolVersion {
+		t.Errorf("protocolVersion = %v", init["protocolVersion"])
+	}
+	if _, ok := init["capabilities"].(map[string]any)["tools"]; !ok {
+		t.Error("initialize should advertise the tools capability")
+	}
+
+	var names []string
+	for _, tool := range responses[1]["result"].(map[string]any)["tools"].([]any) {
+		tool := tool.(map[string]any)
+		if _, ok := tool["inputSchema"].(map[string]any); !ok {
+			t.Errorf("tool %v has no inputSchema", tool["name"])
+		}
+		names = append(names, tool["name"].(string))
+	}
+	if got := strings.Join(names, ","); got != "get_prompt,status,create,write_readme,delete,note" {
+		t.Errorf("tools = %s", got)
+	}
+
+	if _, ok := responses[2]["result"]; !ok {
+		t.Error("ping should return an empty result")
+	}
+
+	wantCodes := []float64{codeMethodNotFound, codeParseError, codeInvalidParams}
+	for i, want := range wantCodes {
+		rerr, ok := responses[3+i]["error"].(map[string]any)
+		if !ok || rerr["code"] != want {
+			t.Errorf("response %d error = %v, want code %v", 3+i, responses[3+i]["error"], want)
+		}
+	}
+}
+
+func TestTools(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+
+	// Create crumbs with README content (auto-initializes .crumbler)
+	result := call(t, root, "create", `{"crumbs":[{"name":"Setup DB","readme":"# Setup DB\n"},{"name":"Add Auth"}]}`)
+	if code := errorCode(t, result); code != "" {
+		t.Fatalf("create failed: %v", result)
+	}
+	created := structured(t, result)["created"].([]any)
+	if len(created) != 2 {
+		t.Fatalf("created %d crumbs, want 2", len(created))
+	}
+	first := created[0].(map[string]any)
+	if first["path"] != filepath.Join(crumb.CrumblerDir, "01-setup-db") || first["state"] != crumb.StateExecute {
+		t.Errorf("first created = %v", first)
+	}
+	if created[1].(map[string]any)["state"] != crumb.StateDecompose {
+		t.Error("crumb without README content should need decomposing")
+	}
+
+	// Status reports the tree and current crumb
+	status := structured(t, call(t, root, "status", `{}`))
+	if status["count"] != float64(3) {
+		t.Errorf("count = %v, want 3", status["count"])
+	}
+	if status["current"].(map[string]any)["path"] != first["path"] {
+		t.Errorf("current = %v", status["current"])
+	}
+
+	// The prompt's text content is the prompt itself
+	result = call(t, root, "get_prompt", `{"minimal":true}`)
+	text := result["content"].([]any)[0].(map[string]any)["text"].(string)
+	if !strings.Contains(text, "# Setup DB") {
+		t.Errorf("prompt should include the current README, got:\n%s", text)
+	}
+	if structured(t, result)["state"] != crumb.StateExecute {
+		t.Errorf("state = %v", structured(t, result)["state"])
+	}
+
+	// Note appends to the current README; write_readme replaces another
+	call(t, root, "note", `{"text":"Schema drafted."}`)
+	readme, _ := os.ReadFile(filepath.Join(root, crumb.CrumblerDir, "01-setup-db", crumb.ReadmeFile))
+	if string(readme) != "# Setup DB\n\nSchema drafted.\n" {
+		t.Errorf("README after note = %q", readme)
+	}
+	call(t, root, "write_readme", `{"path":"02-add-auth","content":"Add auth"}`)
+	readme, _ = os.ReadFile(filepath.Join(root, crumb.CrumblerDir, "02-add-auth", crumb.ReadmeFile))
+	if string(readme) != "Add auth" {
+		t.Errorf("README after write_readme = %q", readme)
+	}
+
+	// Delete moves on to the next crumb
+	deleted := structured(t, call(t, root, "delete", `{}`))
+	if deleted["deleted"] != first["path"] || deleted["next"].(map[string]any)["path"] != filepath.Join(crumb.CrumblerDir, "02-add-auth") {
+		t.Errorf("delete = %v", deleted)
+	}
+}
+
+func TestToolErrors(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) string {
+		root := t.TempDir()
+		if _, err := crumb.Create(root, "Parent"); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, root string)
+		tool  string
+		args  string
+		want  string
+	}{
+		{
+			name: "delete when done",
+			setup: func(t *testing.T, root string) {
+				os.RemoveAll(filepath.Join(root, crumb.CrumblerDir))
+			},
+			tool: "delete",
+			args: `{}`,
+			want: CodeNoCrumb,
+		},
+		{
+			name: "directory full",
+			tool: "create",
+			args: `{"crumbs":[` + strings.Repeat(`{"name":"task"},`, crumb.MaxChildren) + `{"name":"one too many"}]}`,
+			want: CodeDirectoryFull,
+		},
+		{
+			name: "invalid name",
+			tool: "create",
+			args: `{"crumbs":[{"name":"!!!"}]}`,
+			want: CodeInvalidName,
+		},
+		{
+			name: "missing crumb",
+			tool: "write_readme",
+			args: `{"path":"09-missing","content":"x"}`,
+			want: CodeNotFound,
+		},
+		{
+			name: "path outside .crumbler",
+			tool: "note",
+			args: `{"path":"../..","text":"x"}`,
+			want: CodeNotFound,
+		},
+		{
+			name: "missing content",
+			tool: "write_readme",
+			args: `{}`,
+			want: CodeInvalidArguments,
+		},
+		{
+			name: "bad arguments",
+			tool: "create",
+			args: `{"crumbs":"nope"}`,
+			want: CodeInvalidArguments,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			root := setup(t)
+			if tt.setup != nil {
+				tt.setup(t, root)
+			}
+			if got := errorCode(t, call(t, root, tt.tool, tt.args)); got != tt.want {
+				t.Errorf("error code = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToToolError(t *testing.T) {
+	t.Parallel()
+
+	// The current crumb is always a leaf, so the tools never hit
+	// ErrHasChildren; check the mapping directly.
+	root := t.TempDir()
+	parent, err := crumb.Create(root, "Parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := crumb.CreateAt(parent, "Child"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = fmt.Errorf("failed to delete crumb: %w", crumb.DeleteAt(parent))
+	if got := toToolError(err).Code; got != CodeHasChildren {
+		t.Errorf("code = %q, want %q", got, CodeHasChildren)
+	}
+	if got := toToolError(errors.New("boom")).Code; got != CodeInternal {
+		t.Errorf("code = %q, want %q", got, CodeInternal)
+	}
+}
+
+func TestVerificationFailureDetails(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	parent, err := crumb.Create(root, "Parent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(parent, crumb.VerifyFile), []byte("echo broken; exit 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	toolErr := structured(t, call(t, root, "delete", `{}`))["error"].(map[string]any)
+	if toolErr["command"] != "echo broken; exit 1" || !strings.Contains(toolErr["output"].(string), "broken") {
+		t.Errorf("error = %v", toolErr)
+	}
+	if _, err := os.Stat(parent); err != nil {
+		t.Error("crumb should not be deleted when verification fails")
+	}
+
+	// Skipping with a reason deletes and reports the skipped commands
+	deleted := structured(t, call(t, root, "delete", `{"skip_verify_reason":"flaky"}`))
+	if len(deleted["skipped_verify"].([]any)) != 1 {
+		t.Errorf("delete = %v", deleted)
+	}
+}
@@ -0,0 +1,488 @@
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// Error codes reported in failed tool results, mapped from crumbler errors.
+const (
+	CodeNoCrumb            = "no_crumb"
+	CodeHasChildren        = "has_children"
+	CodeDirectoryFull      = "directory_full"
+	CodeInvalidName        = "invalid_name"
+	CodeNotFound           = "not_found"
+	CodeExists             = "exists"
+	CodeVerificationFailed = "verification_failed"
+	CodeHookFailed         = "hook_failed"
+	CodeInvalidArguments   = "invalid_arguments"
+	CodeInternal           = "internal"
+)
+
+// errInvalidArguments marks tool arguments that fail to decode or validate.
+var errInvalidArguments = errors.New("invalid arguments")
+
+// tool is a crumbler operation exposed over MCP.
+type tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	call        func(s *Server, args json.RawMessage) (any, error)
+}
+
+// ToolResult is the result of a tools/call request.
+type ToolResult struct {
+	Content           []Content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+// Content is a text content block in a tool result.
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ToolError is the structured content of a failed tool call.
+type ToolError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Command string `json:"command,omitempty"` // verification_failed: the failing command
+	Output  string `json:"output,omitempty"`  // verification_failed: its combined output
+	Event   string `json:"event,omitempty"`   // hook_failed: the hook event
+}
+
+// texter is implemented by results whose text content is not plain JSON.
+type texter interface {
+	Text() string
+}
+
+// toolList defines the tools in the order tools/list reports them.
+var toolList = []*tool{
+	{
+		Name:        "get_prompt",
+		Description: "Get the agent prompt for the current crumb: what to work on and whether to decompose or execute it. Returns state DONE when no work remains.",
+		InputSchema: objectSchema(map[string]any{
+			"minimal":      boolSchema("Use the minimal preamble and postamble"),
+			"no_preamble":  boolSchema("Skip the preamble"),
+			"no_postamble": boolSchema("Skip the postamble"),
+			"no_context":   boolSchema("Skip README contents"),
+		}),
+		call: (*Server).getPrompt,
+	},
+	{
+		Name:        "status",
+		Description: "Get the crumb tree, the crumb count and the current crumb.",
+		InputSchema: objectSchema(map[string]any{}),
+		call:        (*Server).status,
+	},
+	{
+		Name:        "create",
+		Description: "Create sibling sub-crumbs under the current crumb, each with optional README content. At most 10 children per crumb.",
+		InputSchema: objectSchema(map[string]any{
+			"crumbs": map[string]any{
+				"type":        "array",
+				"description": "Crumbs to create, in order",
+				"minItems":    1,
+				"items": objectSchema(map[string]any{
+					"name":   stringSchema("Human-readable name, converted to kebab-case"),
+					"readme": stringSchema("README.md content"),
+				}, "name"),
+			},
+		}, "crumbs"),
+		call: (*Server).create,
+	},
+	{
+		Name:        "write_readme",
+		Description: "Replace a crumb's README.md. Defaults to the current crumb.",
+		InputSchema: objectSchema(map[string]any{
+			"path":    stringSchema("Crumb path, e.g. .crumbler/01-setup (default: current crumb)"),
+			"content": stringSchema("New README.md content"),
+		}, "content"),
+		call: (*Server).writeReadme,
+	},
+	{
+		Name:        "delete",
+		Description: "Delete the current crumb, marking its work done. Runs VERIFY commands first and refuses to delete if any fail.",
+		InputSchema: objectSchema(map[string]any{
+			"skip_verify_reason": stringSchema("Delete without verifying; the reason is recorded"),
+			"verify_timeout":     stringSchema("Timeout per verification command, e.g. 5m (default 10m)"),
+		}),
+		call: (*Server).delete,
+	},
+	{
+		Name:        "note",
+		Description: "Append a note to a crumb's README.md, e.g. progress or findings for the next agent. Defaults to the current crumb.",
+		InputSchema: objectSchema(map[string]any{
+			"path": stringSchema("Crumb path (default: current crumb)"),
+			"text": stringSchema("Note to append"),
+		}, "text"),
+		call: (*Server).note,
+	},
+}
+
+// tools indexes toolList by name.
+var tools = map[string]*tool{}
+
+func init() {
+	for _, t := range toolList {
+		tools[t.Name] = t
+	}
+}
+
+// toolDefinitions returns the tools for tools/list.
+func toolDefinitions() []*tool {
+	return toolList
+}
+
+// callTool runs a tool and wraps its result or error.
+func (s *Server) callTool(t *tool, args json.RawMessage) *ToolResult {
+	result, err := t.call(s, args)
+	if err != nil {
+		toolErr := toToolError(err)
+		return &ToolResult{
+			Content:           []Content{{Type: "text", Text: err.Error()}},
+			StructuredContent: map[string]any{"error": toolErr},
+			IsError:           true,
+		}
+	}
+
+	var text string
+	if r, ok := result.(texter); ok {
+		text = r.Text()
+	} else {
+		data, _ := json.MarshalIndent(result, "", "  ")
+		text = string(data)
+	}
+	return &ToolResult{Content: []Content{{Type: "text", Text: text}}, StructuredContent: result}
+}
+
+// toToolError maps an error to its structured form.
+func toToolError(err error) ToolError {
+	toolErr := ToolError{Code: CodeInternal, Message: err.Error()}
+
+	var verr *crumbler.VerifyError
+	var herr *crumbler.HookError
+	switch {
+	case errors.As(err, &verr):
+		toolErr.Code = CodeVerificationFailed
+		toolErr.Command = verr.Command
+		toolErr.Output = verr.Output
+	case errors.As(err, &herr):
+		toolErr.Code = CodeHookFailed
+		toolErr.Event = herr.Event
+	case errors.Is(err, crumbler.ErrNoCrumb):
+		toolErr.Code = CodeNoCrumb
+	case errors.Is(err, crumbler.ErrHasChildren):
+		toolErr.Code = CodeHasChildren
+	case errors.Is(err, crumbler.ErrDirectoryFull):
+		toolErr.Code = CodeDirectoryFull
+	case errors.Is(err, crumbler.ErrInvalidName):
+		toolErr.Code = CodeInvalidName
+	case errors.Is(err, crumbler.ErrNotFound):
+		toolErr.Code = CodeNotFound
+	case errors.Is(err, crumbler.ErrExists):
+		toolErr.Code = CodeExists
+	case errors.Is(err, errInvalidArguments):
+		toolErr.Code = CodeInvalidArguments
+	}
+	return toolErr
+}
+
+// decodeArgs unmarshals tool arguments. Missing arguments decode as {}.
+func decodeArgs(args json.RawMessage, v any) error {
+	if len(args) == 0 || string(args) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("%w: %v", errInvalidArguments, err)
+	}
+	return nil
+}
+
+// CrumbInfo describes a crumb in tool results.
+type CrumbInfo struct {
+	Path       string      `json:"path"`
+	ReadmePath string      `json:"readme_path"`
+	Name       string      `json:"name"`
+	State      string      `json:"state"`
+	IsLeaf     bool        `json:"is_leaf"`
+	Children   []CrumbInfo `json:"children,omitempty"`
+}
+
+// crumbInfo converts a crumb (and its subtree, if deep is set) to CrumbInfo.
+func crumbInfo(c *crumbler.Crumb, deep bool) CrumbInfo {
+	info := CrumbInfo{
+		Path:       c.RelPath,
+		ReadmePath: filepath.Join(c.RelPath, crumb.ReadmeFile),
+		Name:       c.DisplayName(),
+		State:      string(c.State()),
+		IsLeaf:     c.IsLeaf,
+	}
+	if deep {
+		for _, child := range c.Children {
+			info.Children = append(info.Children, crumbInfo(child, true))
+		}
+	}
+	return info
+}
+
+// target returns the crumb at path, or the current crumb if path is empty.
+func (s *Server) target(path string) (*crumbler.Crumb, error) {
+	if path != "" {
+		return s.project.Find(path)
+	}
+	current, err := s.project.Current()
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("%w: project is done", crumbler.ErrNoCrumb)
+	}
+	return current, nil
+}
+
+// PromptResult is the result of get_prompt.
+type PromptResult struct {
+	State  string `json:"state"`
+	Crumb  string `json:"crumb,omitempty"`
+	Prompt string `json:"prompt"`
+}
+
+// Text returns the prompt itself, so agents can read it directly.
+func (r *PromptResult) Text() string {
+	return r.Prompt
+}
+
+func (s *Server) getPrompt(args json.RawMessage) (any, error) {
+	var params struct {
+		Minimal     bool `json:"minimal"`
+		NoPreamble  bool `json:"no_preamble"`
+		NoPostamble bool `json:"no_postamble"`
+		NoContext   bool `json:"no_context"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+
+	text, err := s.project.Prompt(crumbler.PromptOptions{
+		Minimal:     params.Minimal,
+		NoPreamble:  params.NoPreamble,
+		NoPostamble: params.NoPostamble,
+		NoContext:   params.NoContext,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PromptResult{State: string(crumbler.StateDone), Prompt: text}
+	current, err := s.project.Current()
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		result.State = string(current.State())
+		result.Crumb = current.RelPath
+	}
+	return result, nil
+}
+
+// StatusResult is the result of status.
+type StatusResult struct {
+	Done    bool       `json:"done"`
+	Count   int        `json:"count"`
+	Current *CrumbInfo `json:"current,omitempty"`
+	Tree    *CrumbInfo `json:"tree,omitempty"`
+}
+
+func (s *Server) status(args json.RawMessage) (any, error) {
+	snap, err := s.project.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	tree, count, current := snap.List(), snap.Count(), snap.Current()
+	if tree == nil {
+		return &StatusResult{Done: true}, nil
+	}
+
+	treeInfo := crumbInfo(tree, true)
+	result := &StatusResult{Count: count, Tree: &treeInfo}
+	if current != nil {
+		info := crumbInfo(current, false)
+		result.Current = &info
+	}
+	return result, nil
+}
+
+// CreateResult is the result of create.
+type CreateResult struct {
+	Parent  string      `json:"parent"`
+	Created []CrumbInfo `json:"created"`
+}
+
+func (s *Server) create(args json.RawMessage) (any, error) {
+	var params struct {
+		Crumbs []struct {
+			Name   string `json:"name"`
+			Readme string `json:"readme"`
+		} `json:"crumbs"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if len(params.Crumbs) == 0 {
+		return nil, fmt.Errorf("%w: crumbs must list at least one crumb", errInvalidArguments)
+	}
+	var names []string
+	for _, c := range params.Crumbs {
+		names = append(names, c.Name)
+	}
+
+	created, err := s.project.Create(names...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CreateResult{Parent: filepath.Dir(created[0].RelPath)}
+	for i, c := range created {
+		if readme := params.Crumbs[i].Readme; readme != "" {
+			if err := c.WriteReadme(readme); err != nil {
+				return nil, err
+			}
+		}
+		result.Created = append(result.Created, crumbInfo(c, false))
+	}
+	return result, nil
+}
+
+func (s *Server) writeReadme(args json.RawMessage) (any, error) {
+	var params struct {
+		Path    string  `json:"path"`
+		Content *string `json:"content"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	if params.Content == nil {
+		return nil, fmt.Errorf("%w: content is required", errInvalidArguments)
+	}
+
+	c, err := s.target(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.WriteReadme(*params.Content); err != nil {
+		return nil, err
+	}
+	info := crumbInfo(c, false)
+	return &info, nil
+}
+
+// DeleteResult is the result of delete.
+type DeleteResult struct {
+	Deleted       string     `json:"deleted"`
+	Done          bool       `json:"done"`
+	Next          *CrumbInfo `json:"next,omitempty"`
+	Verified      []string   `json:"verified,omitempty"`       // Verification commands that passed
+	SkippedVerify []string   `json:"skipped_verify,omitempty"` // Verification commands skipped with a reason
+}
+
+func (s *Server) delete(args json.RawMessage) (any, error) {
+	var params struct {
+		SkipVerifyReason string `json:"skip_verify_reason"`
+		VerifyTimeout    string `json:"verify_timeout"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	opts := crumbler.DeleteOptions{SkipVerifyReason: strings.TrimSpace(params.SkipVerifyReason)}
+	if params.VerifyTimeout != "" {
+		d, err := time.ParseDuration(params.VerifyTimeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: invalid verify_timeout %q", errInvalidArguments, params.VerifyTimeout)
+		}
+		opts.VerifyTimeout = d
+	}
+
+	deleted, err := s.project.Delete(opts)
+	if err != nil {
+		return nil, err
+	}
+	result := &DeleteResult{
+		Deleted:       deleted.Deleted.RelPath,
+		Done:          deleted.Done,
+		Verified:      deleted.Verified,
+		SkippedVerify: deleted.Skipped,
+	}
+
+	next, err := s.project.Current()
+	if err != nil {
+		return nil, err
+	}
+	if next != nil {
+		info := crumbInfo(next, false)
+		result.Next = &info
+	}
+	return result, nil
+}
+
+func (s *Server) note(args json.RawMessage) (any, error) {
+	var params struct {
+		Path string `json:"path"`
+		Text string `json:"text"`
+	}
+	if err := decodeArgs(args, &params); err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(params.Text)
+	if text == "" {
+		return nil, fmt.Errorf("%w: text is required", errInvalidArguments)
+	}
+
+	c, err := s.target(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	readme, err := c.Readme()
+	if err != nil {
+		return nil, err
+	}
+
+	// Separate the note from existing content with a blank line
+	if trimmed := strings.TrimRight(readme, "\n"); trimmed != "" {
+		readme = trimmed + "\n\n"
+	} else {
+		readme = ""
+	}
+	if err := c.WriteReadme(readme + text + "\n"); err != nil {
+		return nil, err
+	}
+	info := crumbInfo(c, false)
+	return &info, nil
+}
+
+// objectSchema returns a JSON schema for an object with the given properties.
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// stringSchema returns a JSON schema for a described string.
+func stringSchema(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// boolSchema returns a JSON schema for a described boolean.
+func boolSchema(description string) map[string]any {
+	return map[string]any{"type": "boolean", "description": description}
+}
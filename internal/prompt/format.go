@@ -2,7 +2,6 @@ package prompt
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -10,8 +9,9 @@ import (
 )
 
 // formatContext generates the context section showing the current crumb.
-func formatContext(root string, current *crumb.Crumb) string {
+func formatContext(snap *crumb.Snapshot, current *crumb.Crumb) string {
 	var sb strings.Builder
+	root := snap.Root()
 
 	sb.WriteString("## Current Crumb\n\n")
 
@@ -19,7 +19,7 @@ func formatContext(root string, current *crumb.Crumb) string {
 	relPath := current.RelPath
 	if relPath == "" {
 		relPath = current.Path
-		if rel, err := filepath.Rel(root, current.Path); err == nil {
+		if rel, err := filepath.Rel(crumb.ProjectRoot(root), current.Path); err == nil {
 			relPath = rel
 		}
 	}
@@ -41,7 +41,7 @@ func formatContext(root string, current *crumb.Crumb) string {
 		sb.WriteString("**⚠️ README is empty**\n\n")
 
 		// Traverse up and show parent README(s) for context
-		parentContext := getParentReadmeContext(root, current)
+		parentContext := getParentReadmeContext(snap, current)
 		if parentContext != "" {
 			sb.WriteString("### Parent Context\n\n")
 			sb.WriteString(parentContext)
@@ -55,13 +55,23 @@ func formatContext(root string, current *crumb.Crumb) string {
 		sb.WriteString("```\n")
 	}
 
+	// Show verification commands that gate `crumbler delete`
+	if commands, err := crumb.VerifyCommands(root, current.Path); err == nil && len(commands) > 0 {
+		sb.WriteString("\n### Verification\n\n")
+		sb.WriteString("`crumbler delete` runs these commands first and refuses to delete if any fail:\n\n")
+		for _, command := range commands {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", command))
+		}
+	}
+
 	return sb.String()
 }
 
 // getParentReadmeContext traverses up the crumb hierarchy and returns
 // parent README content for context when the current README is empty.
-func getParentReadmeContext(root string, current *crumb.Crumb) string {
+func getParentReadmeContext(snap *crumb.Snapshot, current *crumb.Crumb) string {
 	var sb strings.Builder
+	root := snap.Root()
 	crumblerPath := filepath.Join(root, crumb.CrumblerDir)
 
 	// Get current path relative to .crumbler
@@ -77,16 +87,18 @@ func getParentReadmeContext(root string, current *crumb.Crumb) string {
 		}
 
 		// Try to read parent README
-		parentReadmePath := filepath.Join(parentPath, crumb.ReadmeFile)
-		content, err := os.ReadFile(parentReadmePath)
+		parent, err := snap.Find(parentPath)
+		if err != nil {
+			break
+		}
+		readmeContent, err := parent.GetReadme()
 		if err != nil {
 			break
 		}
 
-		readmeContent := string(content)
 		if strings.TrimSpace(readmeContent) != "" {
 			// Get relative path for display
-			relPath, _ := filepath.Rel(root, parentPath)
+			relPath, _ := filepath.Rel(crumb.ProjectRoot(root), parentPath)
 			sb.WriteString(fmt.Sprintf("**From %s/README.md:**\n", relPath))
 			sb.WriteString("```markdown\n")
 			sb.WriteString(readmeContent)
@@ -112,6 +124,27 @@ func FormatTree(root *crumb.Crumb, indent string, isLast bool) string {
 
 // FormatTreeWithCurrent formats a crumb tree for display, marking the current crumb.
 func FormatTreeWithCurrent(root *crumb.Crumb, indent string, isLast bool, currentPath string) string {
+	return formatTree(root, indent, isLast, currentLabel(currentPath))
+}
+
+// FormatTreeWithLabels formats a crumb tree for display, appending the
+// label returned for each crumb to its line (e.g. " ← current").
+func FormatTreeWithLabels(root *crumb.Crumb, label func(c *crumb.Crumb) string) string {
+	return formatTree(root, "", false, label)
+}
+
+// currentLabel returns a label function that marks the crumb at currentPath.
+func currentLabel(currentPath string) func(c *crumb.Crumb) string {
+	return func(c *crumb.Crumb) string {
+		if currentPath != "" && (c.Path == currentPath || c.RelPath == currentPath) {
+			return " ← current"
+		}
+		return ""
+	}
+}
+
+// formatTree formats a crumb tree, labelling each crumb with label.
+func formatTree(root *crumb.Crumb, indent string, isLast bool, label func(c *crumb.Crumb) string) string {
 	var sb strings.Builder
 
 	// Determine if this is the root node (indent is empty and ID is empty)
@@ -139,9 +172,7 @@ func FormatTreeWithCurrent(root *crumb.Crumb, indent string, isLast bool, curren
 		name = root.RelPath
 	}
 
-	if currentPath != "" && (root.Path == currentPath || root.RelPath == currentPath) {
-		name += " ← current"
-	}
+	name += label(root)
 
 	sb.WriteString(prefix + name + "\n")
 
@@ -168,9 +199,7 @@ func FormatTreeWithCurrent(root *crumb.Crumb, indent string, isLast bool, curren
 				sb.WriteString("├── ")
 			}
 			childName := fmt.Sprintf("%s-%s/", child.ID, child.Name)
-			if currentPath != "" && (child.Path == currentPath || child.RelPath == currentPath) {
-				childName += " ← current"
-			}
+			childName += label(&child)
 			sb.WriteString(childName + "\n")
 			// Recursively format grandchildren
 			grandchildIndent := "    "
@@ -179,10 +208,10 @@ func FormatTreeWithCurrent(root *crumb.Crumb, indent string, isLast bool, curren
 			}
 			for j, grandchild := range child.Children {
 				isGrandchildLast := j == len(child.Children)-1
-				sb.WriteString(FormatTreeWithCurrent(&grandchild, grandchildIndent, isGrandchildLast, currentPath))
+				sb.WriteString(formatTree(&grandchild, grandchildIndent, isGrandchildLast, label))
 			}
 		} else {
-			sb.WriteString(FormatTreeWithCurrent(&child, childIndent, isChildLast, currentPath))
+			sb.WriteString(formatTree(&child, childIndent, isChildLast, label))
 		}
 	}
 
@@ -25,36 +25,32 @@ type Config struct {
 
 // GeneratePrompt generates the AI agent prompt for the current project state.
 func GeneratePrompt(root string, config *Config) (string, error) {
-	if config == nil {
-		config = &Config{}
-	}
-
-	// Check if project is done
-	done, err := crumb.IsDone(root)
+	snap, err := crumb.TakeSnapshot(root)
 	if err != nil {
 		return "", err
 	}
+	return GenerateSnapshotPrompt(snap, config)
+}
 
-	if done {
-		return formatDonePrompt(config), nil
-	}
-
-	// Get current crumb
-	current, err := crumb.GetCurrent(root)
-	if err != nil {
-		return "", err
+// GenerateSnapshotPrompt generates the AI agent prompt for the tree in snap,
+// without reading the tree again.
+func GenerateSnapshotPrompt(snap *crumb.Snapshot, config *Config) (string, error) {
+	if config == nil {
+		config = &Config{}
 	}
 
-	if current == nil {
+	// Check if project is done
+	current := snap.Current()
+	if snap.Done() || current == nil {
 		return formatDonePrompt(config), nil
 	}
 
 	// Build the full prompt
-	return formatPrompt(root, current, config)
+	return formatPrompt(snap, current, config)
 }
 
 // formatPrompt builds the complete prompt string.
-func formatPrompt(root string, current *crumb.Crumb, config *Config) (string, error) {
+func formatPrompt(snap *crumb.Snapshot, current *crumb.Crumb, config *Config) (string, error) {
 	var sb strings.Builder
 
 	// Preamble
@@ -65,7 +61,7 @@ func formatPrompt(root string, current *crumb.Crumb, config *Config) (string, er
 
 	// Context
 	if !config.NoContext {
-		sb.WriteString(formatContext(root, current))
+		sb.WriteString(formatContext(snap, current))
 		sb.WriteString("\n")
 	}
 
@@ -159,6 +159,52 @@ func TestGeneratePrompt(t *testing.T) {
 			t.Error("expected parent README content in prompt")
 		}
 	})
+
+	t.Run("snapshot prompt reads nothing again", func(t *testing.T) {
+		root := setupTestProject(t)
+		parentPath := filepath.Join(root, crumb.CrumblerDir, "01-parent")
+		createCrumb(t, parentPath, "Parent task instructions")
+		createCrumb(t, filepath.Join(parentPath, "01-child"), "")
+
+		snap, err := crumb.TakeSnapshot(root)
+		if err != nil {
+			t.Fatalf("failed to take snapshot: %v", err)
+		}
+		// Changes after the snapshot don't show up in its prompt
+		createCrumb(t, parentPath, "Changed instructions")
+
+		prompt, err := GenerateSnapshotPrompt(snap, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(prompt, "Parent task instructions") {
+			t.Error("expected parent README content from the snapshot in prompt")
+		}
+		if strings.Contains(prompt, "Changed instructions") {
+			t.Error("prompt should not read the tree again")
+		}
+	})
+
+	t.Run("verification commands shown", func(t *testing.T) {
+		root := setupTestProject(t)
+		parentPath := filepath.Join(root, crumb.CrumblerDir, "01-parent")
+		createCrumb(t, parentPath, "Parent")
+		createCrumb(t, filepath.Join(parentPath, "01-child"), "Child")
+		if err := os.WriteFile(filepath.Join(parentPath, crumb.VerifyFile), []byte("go test ./...\n"), 0644); err != nil {
+			t.Fatalf("failed to write VERIFY: %v", err)
+		}
+
+		prompt, err := GeneratePrompt(root, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(prompt, "### Verification") {
+			t.Error("expected verification section in prompt")
+		}
+		if !strings.Contains(prompt, "`go test ./...`") {
+			t.Error("expected inherited verification command in prompt")
+		}
+	})
 }
 
 func TestFormatTree(t *testing.T) {
@@ -218,6 +264,40 @@ func TestFormatTree(t *testing.T) {
 	})
 }
 
+func TestFormatTreeWithLabels(t *testing.T) {
+	t.Parallel()
+
+	root := &crumb.Crumb{
+		RelPath: ".crumbler",
+		Children: []crumb.Crumb{
+			{
+				ID:      "01",
+				Name:    "setup",
+				RelPath: ".crumbler/01-setup",
+				Children: []crumb.Crumb{
+					{ID: "01", Name: "database", RelPath: ".crumbler/01-setup/01-database", IsLeaf: true},
+				},
+			},
+			{ID: "02", Name: "features", RelPath: ".crumbler/02-features", IsLeaf: true},
+		},
+	}
+
+	tree := FormatTreeWithLabels(root, func(c *crumb.Crumb) string {
+		if c.IsLeaf {
+			return " [leaf]"
+		}
+		return ""
+	})
+
+	expected := ".crumbler/\n" +
+		"├── 01-setup/\n" +
+		"│   └── 01-database/ [leaf]\n" +
+		"└── 02-features/ [leaf]\n"
+	if tree != expected {
+		t.Errorf("FormatTreeWithLabels() =\n%s\nwant:\n%s", tree, expected)
+	}
+}
+
 func TestWorkflow(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,254 @@
+// Package redact hides credentials in agent transcripts.
+//
+// Tool output often contains secrets: keys read from .env files, tokens
+// echoed by scripts, private keys cat'ed by mistake. A Redactor replaces
+// them with a marker naming what was found, using built-in detectors for
+// common credentials and high-entropy strings, plus patterns the project
+// lists in its redact file.
+package redact
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// File lists a project's own patterns, one regular expression per line, in
+// the project root. Blank lines and lines starting with # are ignored. If
+// a pattern has a group, only the first group is redacted.
+const File = ".crumbler-redact"
+
+// Entropy detection applies to runs of token characters at least
+// minEntropyLen long, with letters and digits in at least minDigitRuns
+// places (one, for a run with base64's /, + or =), whose Shannon entropy is
+// at least minEntropy bits per character. A run that looks like a path is
+// scored a name at a time. Random base64 and alphanumeric keys pass;
+// words, hex hashes, and identifiers or paths with a number in them don't.
+const (
+	minEntropyLen = 24
+	minEntropy    = 4.0
+	minDigitRuns  = 2
+)
+
+// detector finds one kind of secret.
+type detector struct {
+	kind string
+	re   *regexp.Regexp
+}
+
+// builtin are the detectors every Redactor uses, most specific first.
+var builtin = []detector{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----|[\s\S]*)`)},
+	{"aws-access-key", regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA)[0-9A-Z]{16}\b`)},
+	{"aws-secret-key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`)},
+	{"github-token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+}
+
+// tokenPattern matches candidates for entropy detection: runs of the
+// characters keys are made of, with base64 padding.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]{` + fmt.Sprint(minEntropyLen) + `,}={0,2}`)
+
+// Redactor replaces secrets in text. A nil Redactor leaves text alone.
+type Redactor struct {
+	detectors []detector
+}
+
+// New returns a Redactor using the built-in detectors and patterns.
+func New(patterns ...*regexp.Regexp) *Redactor {
+	r := &Redactor{detectors: append([]detector(nil), builtin...)}
+	for _, re := range patterns {
+		r.detectors = append(r.detectors, detector{kind: "custom", re: re})
+	}
+	return r
+}
+
+// Load returns a Redactor for the project at root, adding the patterns in
+// its redact file if there is one.
+func Load(root string) (*Redactor, error) {
+	content, err := os.ReadFile(filepath.Join(root, File))
+	if os.IsNotExist(err) {
+		return New(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", File, err)
+	}
+	patterns, err := parseFile(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", File, err)
+	}
+	return New(patterns...), nil
+}
+
+// parseFile compiles the patterns in redact file content.
+func parseFile(content string) ([]*regexp.Regexp, error) {
+	var patterns []*regexp.Regexp
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return patterns, scanner.Err()
+}
+
+// String returns s with secrets replaced by "[REDACTED kind]".
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+	for _, d := range r.detectors {
+		s = replace(s, d)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range tokenPattern.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		b.WriteString(s[last:start])
+		b.WriteString(redactToken(s[start:end], isPath(s, start, end)))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// redactToken redacts a token if it looks random. The names in a path are
+// scored on their own, since together they can look as random as a key.
+func redactToken(token string, path bool) string {
+	if !path {
+		if highEntropy(token) {
+			return marker("high-entropy")
+		}
+		return token
+	}
+	segments := strings.Split(token, "/")
+	for i, segment := range segments {
+		if len(segment) >= minEntropyLen && highEntropy(segment) {
+			segments[i] = marker("high-entropy")
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isPath reports whether the token s[start:end] is part of a path rather
+// than base64: it has slashes, and a dot next to it, as in "github.com/..."
+// or ".../main.go", or starts like "/tmp/", or has mostly lowercase names.
+// Names shorter than 3 characters don't count either way.
+func isPath(s string, start, end int) bool {
+	names := strings.Split(s[start:end], "/")
+	if len(names) == 1 {
+		return false
+	}
+	if start > 0 && s[start-1] == '.' || end+1 < len(s) && s[end] == '.' && isAlnum(s[end+1]) {
+		return true
+	}
+	if names[0] == "" && len(names[1]) >= 3 && lowerName(names[1]) {
+		return true
+	}
+	counted, lower := 0, 0
+	for _, name := range names {
+		if len(name) < 3 {
+			continue
+		}
+		counted++
+		if lowerName(name) {
+			lower++
+		}
+	}
+	return lower*2 > counted
+}
+
+// lowerName reports whether a name has no capitals after its first letter.
+func lowerName(name string) bool {
+	return strings.ToLower(name[1:]) == name[1:]
+}
+
+// replace redacts a detector's matches, or their first group if the
+// pattern has one.
+func replace(s string, d detector) string {
+	matches := d.re.FindAllStringSubmatchIndex(s, -1)
+	if matches == nil {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if len(m) >= 4 {
+			start, end = m[2], m[3]
+		}
+		if start < 0 || start == end {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(marker(d.kind))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// marker is the text that replaces a secret.
+func marker(kind string) string {
+	return "[REDACTED " + kind + "]"
+}
+
+// highEntropy reports whether a token looks like a random key. Digits in
+// one place, like the 2 in "Auth2Factor" or the suffix of a temporary
+// directory, are how names look, not keys.
+func highEntropy(token string) bool {
+	runs := minDigitRuns
+	if strings.ContainsAny(token, "/+=") {
+		runs = 1
+	}
+	if digitRuns(token) < runs ||
+		!strings.ContainsAny(strings.ToLower(token), "abcdefghijklmnopqrstuvwxyz") {
+		return false
+	}
+	return entropy(token) >= minEntropy
+}
+
+// digitRuns returns how many runs of digits s has.
+func digitRuns(s string) int {
+	runs := 0
+	for i := 0; i < len(s); i++ {
+		if isDigit(s[i]) && (i == 0 || !isDigit(s[i-1])) {
+			runs++
+		}
+	}
+	return runs
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isAlnum(c byte) bool {
+	return isDigit(c) || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// entropy returns the Shannon entropy of s in bits per byte.
+func entropy(s string) float64 {
+	var counts [256]int
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	var h float64
+	n := float64(len(s))
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / n
+			h -= p * math.Log2(p)
+		}
+	}
+	return h
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// inlineCode matches `code` spans (after HTML escaping).
+	inlineCode = regexp.MustCompile("`([^`]+)`")
+	// strong matches **bold** text.
+	strong = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	// orderedItem matches "1. item" list lines.
+	orderedItem = regexp.MustCompile(`^\d+\.\s+`)
+)
+
+// renderMarkdown converts README markdown to HTML.
+// It supports the subset crumb READMEs use: headings, paragraphs, lists,
+// fenced code blocks, inline code and bold. All text is HTML-escaped, so
+// raw HTML in a README is shown, never executed.
+func renderMarkdown(src string) string {
+	var sb strings.Builder
+	var paragraph []string
+	list := "" // "ul", "ol" or "" when not in a list
+	inCode := false
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString("<p>" + renderInline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			sb.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(kind string) {
+		if list != kind {
+			closeList()
+			sb.WriteString("<" + kind + ">\n")
+			list = kind
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if inCode {
+				sb.WriteString("</code></pre>\n")
+				inCode = false
+			} else {
+				flushParagraph()
+				closeList()
+				sb.WriteString("<pre><code>")
+				inCode = true
+			}
+			continue
+		}
+		if inCode {
+			sb.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			closeList()
+		case strings.HasPrefix(trimmed, "#"):
+			flushParagraph()
+			closeList()
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 6 {
+				level = 6
+			}
+			text := strings.TrimSpace(trimmed[level:])
+			tag := "h" + string(rune('0'+level))
+			sb.WriteString("<" + tag + ">" + renderInline(text) + "</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushParagraph()
+			openList("ul")
+			sb.WriteString("<li>" + renderInline(trimmed[2:]) + "</li>\n")
+		case orderedItem.MatchString(trimmed):
+			flushParagraph()
+			openList("ol")
+			sb.WriteString("<li>" + renderInline(orderedItem.ReplaceAllString(trimmed, "")) + "</li>\n")
+		default:
+			closeList()
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+
+	if inCode {
+		sb.WriteString("</code></pre>\n")
+	}
+	flushParagraph()
+	closeList()
+
+	return sb.String()
+}
+
+// renderInline escapes text and renders inline code and bold.
+func renderInline(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = inlineCode.ReplaceAllString(escaped, "<code>$1</code>")
+	return strong.ReplaceAllString(escaped, "<strong>$1</strong>")
+}
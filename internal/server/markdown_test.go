@@ -0,0 +1,54 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		contains []string
+	}{
+		{
+			name:     "heading and paragraph",
+			input:    "# Title\n\nSome text\ncontinues here.",
+			contains: []string{"<h1>Title</h1>", "<p>Some text continues here.</p>"},
+		},
+		{
+			name:     "lists",
+			input:    "- one\n- two\n\n1. first\n2. second",
+			contains: []string{"<ul>\n<li>one</li>\n<li>two</li>\n</ul>", "<ol>\n<li>first</li>\n<li>second</li>\n</ol>"},
+		},
+		{
+			name:     "code block",
+			input:    "```go\nif a < b {}\n```",
+			contains: []string{"<pre><code>if a &lt; b {}\n</code></pre>"},
+		},
+		{
+			name:     "inline",
+			input:    "Run `make` **now**",
+			contains: []string{"<p>Run <code>make</code> <strong>now</strong></p>"},
+		},
+		{
+			name:     "escapes html",
+			input:    "<img src=x onerror=alert(1)>",
+			contains: []string{"&lt;img src=x onerror=alert(1)&gt;"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := renderMarkdown(tt.input)
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("renderMarkdown() = %q, missing %q", got, want)
+				}
+			}
+		})
+	}
+}
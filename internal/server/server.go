@@ -0,0 +1,281 @@
+// Package server serves a read-only web dashboard for a crumbler project.
+// It shows the crumb tree, the current crumb, rendered READMEs and recently
+// completed crumbs from git history, and pushes tree changes to the browser
+// with server-sent events.
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+const (
+	// DefaultAddr is the default listen address (local only).
+	DefaultAddr = "127.0.0.1:8080"
+	// DefaultInterval is how often the tree is polled for server-sent events.
+	DefaultInterval = time.Second
+	// maxCompleted is how many recently completed crumbs are shown.
+	maxCompleted = 20
+)
+
+// Node is a crumb in the dashboard state.
+type Node struct {
+	RelPath    string        `json:"rel_path"`
+	ID         string        `json:"id"`
+	Name       string        `json:"name"`
+	Title      string        `json:"title"`
+	IsLeaf     bool          `json:"is_leaf"`
+	IsCurrent  bool          `json:"is_current"`
+	Readme     string        `json:"readme"`
+	ReadmeHTML template.HTML `json:"-"`
+	Children   []*Node       `json:"children,omitempty"`
+}
+
+// Completed is a crumb whose deletion was committed to git.
+type Completed struct {
+	RelPath string    `json:"rel_path"`
+	Commit  string    `json:"commit"`
+	Subject string    `json:"subject"`
+	Time    time.Time `json:"time"`
+}
+
+// State is everything the dashboard shows.
+type State struct {
+	Done      bool        `json:"done"`
+	Count     int         `json:"count"`
+	Current   *Node       `json:"current,omitempty"`
+	Tree      *Node       `json:"tree,omitempty"`
+	Completed []Completed `json:"completed"`
+}
+
+// Server is an http.Handler for the dashboard. It never modifies the project.
+type Server struct {
+	root     string
+	interval time.Duration
+	mux      *http.ServeMux
+}
+
+// New returns a dashboard server for the project at root.
+// interval controls how often the tree is polled for live updates.
+func New(root string, interval time.Duration) *Server {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	s := &Server{root: root, interval: interval, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/fragment", s.handleFragment)
+	s.mux.HandleFunc("/api/state", s.handleState)
+	s.mux.HandleFunc("/events", s.handleEvents)
+	return s
+}
+
+// ServeHTTP implements http.Handler. Only GET and HEAD are allowed.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "read-only dashboard", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleIndex serves the full dashboard page.
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	s.render(w, "page")
+}
+
+// handleFragment serves the dashboard body, used by the page to refresh itself.
+func (s *Server) handleFragment(w http.ResponseWriter, r *http.Request) {
+	s.render(w, "dashboard")
+}
+
+// render executes a dashboard template with the current state.
+func (s *Server) render(w http.ResponseWriter, name string) {
+	state, err := s.State()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, state); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// handleState serves the dashboard state as JSON.
+func (s *Server) handleState(w http.ResponseWriter, r *http.Request) {
+	state, err := s.State()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// handleEvents streams a "tree" event whenever the crumb tree changes.
+// The first event is sent immediately with the current fingerprint.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	last := ""
+	for {
+		fingerprint, err := s.fingerprint()
+		if err != nil {
+			fmt.Fprintf(w, "event: error\ndata: %s\n\n", strings.ReplaceAll(err.Error(), "\n", " "))
+		} else if fingerprint != last {
+			fmt.Fprintf(w, "event: tree\ndata: %s\n\n", fingerprint)
+			last = fingerprint
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fingerprint returns a hash that changes whenever the tree or a README
+// changes. It runs on every tick for every client, so it reads only the
+// tree: git history changes don't need a live push.
+func (s *Server) fingerprint() (string, error) {
+	snap, err := crumb.TakeSnapshot(s.root)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(s.treeState(snap))
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// State reads the current dashboard state from disk.
+func (s *Server) State() (*State, error) {
+	snap, err := crumb.TakeSnapshot(s.root)
+	if err != nil {
+		return nil, err
+	}
+	state := s.treeState(snap)
+	state.Completed = s.completed()
+	return state, nil
+}
+
+// treeState returns the dashboard state of a snapshot, without the
+// completed crumbs from git history.
+func (s *Server) treeState(snap *crumb.Snapshot) *State {
+	tree, current := snap.List(), snap.Current()
+
+	state := &State{Done: tree == nil, Count: snap.Count()}
+	if tree == nil {
+		return state
+	}
+
+	currentPath := ""
+	if current != nil {
+		currentPath = current.Path
+	}
+	state.Tree = s.buildNode(tree, currentPath, &state.Current)
+	return state
+}
+
+// buildNode converts a crumb tree into dashboard nodes, recording the current
+// node.
+func (s *Server) buildNode(c *crumb.Crumb, currentPath string, current **Node) *Node {
+	readme, _ := c.GetReadme()
+	node := &Node{
+		RelPath:    c.RelPath,
+		ID:         c.ID,
+		Name:       c.Name,
+		Title:      c.DisplayName(),
+		IsLeaf:     c.IsLeaf,
+		IsCurrent:  c.Path == currentPath,
+		Readme:     readme,
+		ReadmeHTML: template.HTML(renderMarkdown(readme)),
+	}
+	if node.IsCurrent {
+		*current = node
+	}
+	for i := range c.Children {
+		node.Children = append(node.Children, s.buildNode(&c.Children[i], currentPath, current))
+	}
+	return node
+}
+
+// completed returns recently completed crumbs from git history: commits that
+// deleted a crumb README. Returns an empty list outside a git repository.
+func (s *Server) completed() []Completed {
+	// Named plans live below the project; report paths relative to it
+	projectRoot := crumb.ProjectRoot(s.root)
+	crumblerRel, err := filepath.Rel(projectRoot, filepath.Join(s.root, crumb.CrumblerDir))
+	if err != nil {
+		return []Completed{}
+	}
+
+	cmd := exec.Command("git", "log", "--diff-filter=D", "--name-only", "--relative",
+		"--format=%x1e%H%x1f%ct%x1f%s", "-n", strconv.Itoa(maxCompleted), "--", crumblerRel)
+	cmd.Dir = projectRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return []Completed{}
+	}
+	return parseGitLog(string(out))
+}
+
+// parseGitLog parses `git log --name-only` output produced with the
+// "%x1e%H%x1f%ct%x1f%s" format, keeping deleted crumb READMEs.
+func parseGitLog(out string) []Completed {
+	completed := []Completed{}
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		header := strings.SplitN(lines[0], "\x1f", 3)
+		if len(header) != 3 {
+			continue
+		}
+		seconds, _ := strconv.ParseInt(header[1], 10, 64)
+		for _, file := range lines[1:] {
+			file = strings.TrimSpace(file)
+			if !strings.HasSuffix(file, "/"+crumb.ReadmeFile) {
+				continue
+			}
+			completed = append(completed, Completed{
+				RelPath: strings.TrimSuffix(file, "/"+crumb.ReadmeFile),
+				Commit:  header[0],
+				Subject: header[2],
+				Time:    time.Unix(seconds, 0).UTC(),
+			})
+			if len(completed) == maxCompleted {
+				return completed
+			}
+		}
+	}
+	return completed
+}
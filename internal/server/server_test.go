@@ -0,0 +1,209 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/testutil"
+)
+
+func TestServerPages(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.NewTestProject(t).
+		WithCrumb("01-setup", "# Setup\n\nInstall **deps** with `go mod download`.\n").
+		WithCrumb("02-build", "<script>alert(1)</script>").
+		Build()
+	ts := httptest.NewServer(New(root, 10*time.Millisecond))
+	defer ts.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		status      int
+		contentType string
+		contains    []string
+		excludes    []string
+	}{
+		{
+			name:        "index",
+			path:        "/",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"<!DOCTYPE html>", "EventSource", "Setup", "← current", "<strong>deps</strong>", "<code>go mod download</code>"},
+			excludes:    []string{"<script>alert(1)</script>"},
+		},
+		{
+			name:        "fragment",
+			path:        "/fragment",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"3 crumbs", "Build", "&lt;script&gt;"},
+			excludes:    []string{"<!DOCTYPE html>"},
+		},
+		{
+			name:   "unknown path",
+			path:   "/nope",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(ts.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body := readAll(t, resp)
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.contentType != "" && !strings.HasPrefix(resp.Header.Get("Content-Type"), tt.contentType) {
+				t.Errorf("Content-Type = %q, want %s", resp.Header.Get("Content-Type"), tt.contentType)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body should not contain %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestServerState(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.NewTestProject(t).
+		WithCrumb("01-setup", "setup").
+		WithCrumb("01-setup/01-database", "").
+		Build()
+
+	rec := httptest.NewRecorder()
+	New(root, 0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/state", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+
+	var state State
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Done || state.Count != 3 {
+		t.Errorf("state = done %v count %d, want not done with 3 crumbs", state.Done, state.Count)
+	}
+	if state.Current == nil || state.Current.RelPath != filepath.Join(crumb.CrumblerDir, "01-setup", "01-database") {
+		t.Errorf("current = %+v, want the database crumb", state.Current)
+	}
+}
+
+func TestServerDone(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	rec := httptest.NewRecorder()
+	New(root, 0).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "All work complete") {
+		t.Error("done project should say all work is complete")
+	}
+}
+
+func TestServerReadOnly(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.NewTestProject(t).WithCrumb("01-task", "").Build()
+	for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+		rec := httptest.NewRecorder()
+		New(root, 0).ServeHTTP(rec, httptest.NewRequest(method, "/api/state", nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s status = %d, want 405", method, rec.Code)
+		}
+	}
+}
+
+func TestServerEvents(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.NewTestProject(t).WithCrumb("01-task", "").Build()
+	ts := httptest.NewServer(New(root, 10*time.Millisecond))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	nextEvent := func() string {
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), "data: ") {
+				return strings.TrimPrefix(lines.Text(), "data: ")
+			}
+		}
+		t.Fatalf("event stream ended: %v", lines.Err())
+		return ""
+	}
+
+	first := nextEvent()
+	readme := filepath.Join(root, crumb.CrumblerDir, "01-task", crumb.ReadmeFile)
+	if err := os.WriteFile(readme, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if second := nextEvent(); second == first {
+		t.Error("README change should produce a new fingerprint")
+	}
+}
+
+func TestParseGitLog(t *testing.T) {
+	t.Parallel()
+
+	out := "\x1eabc123\x1f1700000000\x1fFinish setup\n\n.crumbler/01-setup/README.md\n.crumbler/01-setup/notes.txt\n" +
+		"\x1edef456\x1f1690000000\x1fUnrelated\n\nmain.go\n"
+	completed := parseGitLog(out)
+	if len(completed) != 1 {
+		t.Fatalf("got %d completed, want 1: %+v", len(completed), completed)
+	}
+	got := completed[0]
+	if got.RelPath != ".crumbler/01-setup" || got.Commit != "abc123" || got.Subject != "Finish setup" {
+		t.Errorf("completed = %+v", got)
+	}
+	if !got.Time.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("time = %v", got.Time)
+	}
+}
+
+// readAll reads the response body as a string.
+func readAll(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
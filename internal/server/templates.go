@@ -0,0 +1,77 @@
+package server
+
+import "html/template"
+
+// templates holds the dashboard page and its live-refreshed body.
+var templates = template.Must(template.New("page").Parse(pageTemplate))
+
+func init() {
+	template.Must(templates.New("dashboard").Parse(dashboardTemplate))
+	template.Must(templates.New("node").Parse(nodeTemplate))
+}
+
+// pageTemplate is the full HTML page. It has no external assets; the script
+// re-fetches /fragment whenever /events reports a tree change.
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>crumbler</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+main { display: grid; grid-template-columns: minmax(16rem, 1fr) 2fr; gap: 2rem; }
+ul.tree { list-style: none; padding-left: 1.2rem; }
+ul.tree > li > ul.tree { border-left: 1px solid #ddd; }
+.current > .label { font-weight: bold; color: #b35900; }
+.id { color: #888; font-family: monospace; }
+.readme { border: 1px solid #ddd; border-radius: 4px; padding: 0 1rem; margin-bottom: 1rem; }
+pre { background: #f5f5f5; padding: 0.5rem; overflow-x: auto; }
+.muted { color: #888; }
+details summary { cursor: pointer; }
+</style>
+</head>
+<body>
+<h1>crumbler</h1>
+<div id="dashboard">{{template "dashboard" .}}</div>
+<script>
+const events = new EventSource("/events");
+events.addEventListener("tree", async () => {
+  const res = await fetch("/fragment");
+  if (res.ok) document.getElementById("dashboard").innerHTML = await res.text();
+});
+</script>
+</body>
+</html>
+`
+
+// dashboardTemplate is the refreshable part of the page.
+const dashboardTemplate = `{{if .Done}}<p><strong>All work complete.</strong> No crumbs remain.</p>
+{{else}}<p class="muted">{{.Count}} crumbs</p>
+<main>
+<section>
+<h2>Tree</h2>
+<ul class="tree">{{template "node" .Tree}}</ul>
+</section>
+<section>
+<h2>Current</h2>
+{{with .Current}}<h3>{{.Title}} <span class="muted">{{.RelPath}}</span></h3>
+<div class="readme">{{if .Readme}}{{.ReadmeHTML}}{{else}}<p class="muted">Empty README</p>{{end}}</div>
+{{else}}<p class="muted">No current crumb.</p>{{end}}
+</section>
+</main>
+{{end}}<h2>Recently completed</h2>
+{{if .Completed}}<ul>
+{{range .Completed}}<li><code>{{.RelPath}}</code> <span class="muted">{{.Time.Format "2006-01-02 15:04"}} {{.Subject}}</span></li>
+{{end}}</ul>
+{{else}}<p class="muted">No completed crumbs in git history.</p>
+{{end}}`
+
+// nodeTemplate renders a crumb and its children. Each README is collapsed
+// into a details element.
+const nodeTemplate = `<li{{if .IsCurrent}} class="current"{{end}}>
+<details{{if .IsCurrent}} open{{end}}><summary class="label">{{if .ID}}<span class="id">{{.ID}}</span> {{end}}{{.Title}}{{if .IsCurrent}} ← current{{end}}</summary>
+{{if .Readme}}<div class="readme">{{.ReadmeHTML}}</div>{{end}}
+</details>
+{{if .Children}}<ul class="tree">{{range .Children}}{{template "node" .}}{{end}}</ul>{{end}}
+</li>
+`
@@ -14,6 +14,7 @@ import (
 // TestProjectBuilder provides a fluent API for building test project structures.
 type TestProjectBuilder struct {
 	t           *testing.T
+	storage     crumb.Storage
 	projectRoot string
 	crumbs      map[string]*crumbConfig
 	cleaned     bool
@@ -52,6 +53,7 @@ func NewTestProject(t *testing.T) *TestProjectBuilder {
 
 	builder := &TestProjectBuilder{
 		t:           t,
+		storage:     crumb.OS,
 		projectRoot: testRoot,
 		crumbs:      make(map[string]*crumbConfig),
 	}
@@ -66,6 +68,26 @@ func NewTestProject(t *testing.T) *TestProjectBuilder {
 	return builder
 }
 
+// NewMemTestProject creates a TestProjectBuilder that builds the project in
+// memory instead of under .test/. Use Store to run crumb operations on it;
+// nothing is written to disk, so tests can run in parallel freely.
+func NewMemTestProject(t *testing.T) *TestProjectBuilder {
+	t.Helper()
+
+	storage := crumb.NewMemStorage()
+	testRoot := filepath.Join(string(filepath.Separator), "project")
+	if err := storage.MkdirAll(testRoot); err != nil {
+		t.Fatalf("failed to create test directory: %v", err)
+	}
+
+	return &TestProjectBuilder{
+		t:           t,
+		storage:     storage,
+		projectRoot: testRoot,
+		crumbs:      make(map[string]*crumbConfig),
+	}
+}
+
 // findProjectRoot finds the project root by looking for go.mod
 func findProjectRoot(t *testing.T) string {
 	t.Helper()
@@ -109,25 +131,25 @@ func (b *TestProjectBuilder) Build() string {
 
 	// Create .crumbler directory
 	crumblerDir := filepath.Join(b.projectRoot, crumb.CrumblerDir)
-	if err := os.MkdirAll(crumblerDir, 0755); err != nil {
+	if err := b.storage.MkdirAll(crumblerDir); err != nil {
 		b.t.Fatalf("failed to create .crumbler directory: %v", err)
 	}
 
 	// Create root README.md
 	rootReadme := filepath.Join(crumblerDir, crumb.ReadmeFile)
-	if err := os.WriteFile(rootReadme, []byte{}, 0644); err != nil {
+	if err := b.storage.WriteFile(rootReadme, []byte{}); err != nil {
 		b.t.Fatalf("failed to create root README.md: %v", err)
 	}
 
 	// Create each crumb
 	for relPath, config := range b.crumbs {
 		crumbPath := filepath.Join(crumblerDir, relPath)
-		if err := os.MkdirAll(crumbPath, 0755); err != nil {
+		if err := b.storage.MkdirAll(crumbPath); err != nil {
 			b.t.Fatalf("failed to create crumb directory %s: %v", relPath, err)
 		}
 
 		readmePath := filepath.Join(crumbPath, crumb.ReadmeFile)
-		if err := os.WriteFile(readmePath, []byte(config.readme), 0644); err != nil {
+		if err := b.storage.WriteFile(readmePath, []byte(config.readme)); err != nil {
 			b.t.Fatalf("failed to create README.md for %s: %v", relPath, err)
 		}
 	}
@@ -149,3 +171,14 @@ func (b *TestProjectBuilder) CrumblerDir() string {
 func (b *TestProjectBuilder) CrumbPath(relPath string) string {
 	return filepath.Join(b.projectRoot, crumb.CrumblerDir, relPath)
 }
+
+// Storage returns the storage the project is built in: crumb.OS for
+// NewTestProject, an in-memory storage for NewMemTestProject.
+func (b *TestProjectBuilder) Storage() crumb.Storage {
+	return b.storage
+}
+
+// Store returns a crumb.Store for running crumb operations on the project.
+func (b *TestProjectBuilder) Store() *crumb.Store {
+	return crumb.NewStore(b.storage)
+}
@@ -153,3 +153,39 @@ func TestCleanup(t *testing.T) {
 	// The cleanup function is registered via t.Cleanup(), which will
 	// be called after this test function completes
 }
+
+func TestMemTestProject(t *testing.T) {
+	t.Parallel()
+
+	builder := NewMemTestProject(t)
+	root := builder.
+		WithCrumb("01-setup", "Setup content").
+		WithCrumb("01-setup/01-database", "").
+		WithCrumb("02-features", "").
+		Build()
+
+	// Nothing is written to disk
+	AssertDirNotExists(t, filepath.Join(root, crumb.CrumblerDir))
+
+	store := builder.Store()
+	count, err := store.Count(root)
+	if err != nil {
+		t.Fatalf("Count() error: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Count() = %d, want 4", count)
+	}
+
+	current, err := store.GetCurrent(root)
+	if err != nil {
+		t.Fatalf("GetCurrent() error: %v", err)
+	}
+	if current.Name != "database" {
+		t.Errorf("current = %q, want database", current.Name)
+	}
+
+	readme, err := builder.Storage().ReadFile(filepath.Join(builder.CrumbPath("01-setup"), crumb.ReadmeFile))
+	if err != nil || string(readme) != "Setup content" {
+		t.Errorf("README = %q, %v", readme, err)
+	}
+}
@@ -0,0 +1,43 @@
+package tui
+
+import "unicode/utf8"
+
+// ParseKeys splits raw terminal input into key names.
+// Arrow keys become "up", "down", "left" and "right"; control keys become
+// "enter", "esc", "backspace" and "ctrl+c"; other input is one key per rune.
+func ParseKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		switch {
+		case len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O'):
+			switch input[2] {
+			case 'A':
+				keys = append(keys, "up")
+			case 'B':
+				keys = append(keys, "down")
+			case 'C':
+				keys = append(keys, "right")
+			case 'D':
+				keys = append(keys, "left")
+			}
+			input = input[3:]
+		case input[0] == 0x1b:
+			keys = append(keys, "esc")
+			input = input[1:]
+		case input[0] == '\r' || input[0] == '\n':
+			keys = append(keys, "enter")
+			input = input[1:]
+		case input[0] == 0x7f || input[0] == 0x08:
+			keys = append(keys, "backspace")
+			input = input[1:]
+		case input[0] == 0x03:
+			keys = append(keys, "ctrl+c")
+			input = input[1:]
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, string(r))
+			input = input[size:]
+		}
+	}
+	return keys
+}
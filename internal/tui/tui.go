@@ -0,0 +1,588 @@
+// Package tui implements the interactive crumb tree browser behind 'crumbler tui'.
+// The Model holds browser state and turns key presses into crumb operations;
+// it never touches the terminal, so the CLI owns raw mode, drawing and $EDITOR.
+// Every change goes through internal/crumb, and creates and deletes run the
+// same hooks, so the same limits, validation and hooks apply as on the
+// command line.
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/hooks"
+)
+
+// Command tells the caller what to do after a key press.
+type Command int
+
+const (
+	// CommandNone means redraw and keep reading keys.
+	CommandNone Command = iota
+	// CommandQuit means exit the browser.
+	CommandQuit
+	// CommandEdit means open EditPath in the user's editor, then call Reload.
+	CommandEdit
+)
+
+// mode is what the browser is currently doing with key presses.
+type mode int
+
+const (
+	modeBrowse mode = iota
+	modeRename
+	modeCreate
+	modeConfirmDelete
+)
+
+// row is one visible line of the tree pane.
+type row struct {
+	crumb *crumb.Crumb
+	depth int
+}
+
+// Model is the state of the tree browser.
+type Model struct {
+	root      string
+	tree      *crumb.Crumb
+	current   string          // Path of the current crumb
+	collapsed map[string]bool // Crumb paths that are collapsed
+	rows      []row
+	cursor    int
+	offset    int // First visible row
+	mode      mode
+	input     []rune
+	status    string
+
+	// EditPath is the README to open when Update returns CommandEdit.
+	EditPath string
+
+	// Width and Height are the terminal size used by View.
+	Width, Height int
+}
+
+// New loads the crumb tree under root with every crumb expanded.
+func New(root string) (*Model, error) {
+	m := &Model{
+		root:      root,
+		collapsed: make(map[string]bool),
+		Width:     80,
+		Height:    24,
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Reload re-reads the tree from disk, keeping the cursor on the same crumb if it still exists.
+func (m *Model) Reload() error {
+	var selected string
+	if c := m.Selected(); c != nil {
+		selected = c.Path
+	}
+
+	snap, err := crumb.TakeSnapshot(m.root)
+	if err != nil {
+		return err
+	}
+	tree, current := snap.List(), snap.Current()
+
+	m.tree = tree
+	m.current = ""
+	if current != nil {
+		m.current = current.Path
+	}
+	m.buildRows()
+	m.selectPath(selected)
+	return nil
+}
+
+// Selected returns the crumb under the cursor, or nil if the tree is empty.
+func (m *Model) Selected() *crumb.Crumb {
+	if m.cursor < 0 || m.cursor >= len(m.rows) {
+		return nil
+	}
+	return m.rows[m.cursor].crumb
+}
+
+// Update handles one key press (as returned by ParseKeys).
+func (m *Model) Update(key string) Command {
+	switch m.mode {
+	case modeRename, modeCreate:
+		m.updateInput(key)
+		return CommandNone
+	case modeConfirmDelete:
+		m.updateConfirmDelete(key)
+		return CommandNone
+	}
+
+	m.status = ""
+	selected := m.Selected()
+
+	switch key {
+	case "q", "ctrl+c":
+		return CommandQuit
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "right", "l":
+		if selected != nil && len(selected.Children) > 0 {
+			if m.collapsed[selected.Path] {
+				delete(m.collapsed, selected.Path)
+				m.buildRows()
+			} else {
+				m.moveCursor(1)
+			}
+		}
+	case "left", "h":
+		if selected != nil && len(selected.Children) > 0 && !m.collapsed[selected.Path] {
+			m.collapsed[selected.Path] = true
+			m.buildRows()
+		} else {
+			m.selectParent()
+		}
+	case "enter", " ":
+		if selected != nil && len(selected.Children) > 0 {
+			m.collapsed[selected.Path] = !m.collapsed[selected.Path]
+			m.buildRows()
+		}
+	case "K":
+		m.reorder(-1)
+	case "J":
+		m.reorder(1)
+	case "r":
+		if selected != nil {
+			m.mode = modeRename
+			m.input = []rune(selected.Name)
+		}
+	case "a":
+		if selected != nil {
+			m.mode = modeCreate
+			m.input = nil
+		}
+	case "d":
+		if selected != nil {
+			m.mode = modeConfirmDelete
+		}
+	case "e":
+		if selected != nil {
+			m.EditPath = filepath.Join(selected.Path, crumb.ReadmeFile)
+			return CommandEdit
+		}
+	}
+	return CommandNone
+}
+
+// updateInput handles keys while typing a name.
+func (m *Model) updateInput(key string) {
+	switch key {
+	case "esc", "ctrl+c":
+		m.mode = modeBrowse
+		m.status = "cancelled"
+	case "backspace":
+		if len(m.input) > 0 {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case "enter":
+		name := string(m.input)
+		editMode := m.mode
+		m.mode = modeBrowse
+		if editMode == modeRename {
+			m.rename(name)
+		} else {
+			m.create(name)
+		}
+	default:
+		if r := []rune(key); len(r) == 1 && r[0] >= ' ' {
+			m.input = append(m.input, r[0])
+		}
+	}
+}
+
+// updateConfirmDelete handles the answer to the delete confirmation.
+func (m *Model) updateConfirmDelete(key string) {
+	m.mode = modeBrowse
+	if key != "y" && key != "Y" {
+		m.status = "delete cancelled"
+		return
+	}
+
+	selected := m.Selected()
+	payload := hooks.NewPayload(m.root, hooks.PreDelete, selected)
+	if err := runHook(payload); err != nil {
+		m.status = "error: delete aborted: " + err.Error()
+		return
+	}
+	if err := crumb.DeleteTree(selected.Path); err != nil {
+		m.status = "error: " + err.Error()
+		return
+	}
+	m.status = "deleted " + relPath(m.root, selected.Path)
+	payload.Event = hooks.PostDelete
+	if err := runHook(payload); err != nil {
+		m.status += " (warning: " + err.Error() + ")"
+	}
+	m.cursor--
+	m.reload()
+}
+
+// rename renames the selected crumb.
+func (m *Model) rename(name string) {
+	selected := m.Selected()
+	newPath, err := crumb.Rename(selected.Path, name)
+	if err != nil {
+		m.status = "error: " + err.Error()
+		return
+	}
+	m.renameCollapsed(selected.Path, newPath)
+	m.status = "renamed to " + filepath.Base(newPath)
+	m.reloadAt(newPath)
+}
+
+// create adds a child crumb under the selected crumb.
+func (m *Model) create(name string) {
+	selected := m.Selected()
+	if crumb.Kebabify(name) == "" {
+		m.status = fmt.Sprintf("error: invalid crumb name %q", name)
+		return
+	}
+	payload := hooks.NewPayload(m.root, hooks.PreCreate, selected)
+	payload.Names = []string{name}
+	if err := runHook(payload); err != nil {
+		m.status = "error: create aborted: " + err.Error()
+		return
+	}
+	newPath, err := crumb.CreateAt(selected.Path, name)
+	if err != nil {
+		m.status = "error: " + err.Error()
+		return
+	}
+	delete(m.collapsed, selected.Path)
+	m.status = "created " + relPath(m.root, newPath)
+	payload.Event, payload.Names = hooks.PostCreate, nil
+	payload.Created = []string{relPath(m.root, newPath)}
+	if err := runHook(payload); err != nil {
+		m.status += " (warning: " + err.Error() + ")"
+	}
+	m.reloadAt(newPath)
+}
+
+// reorder moves the selected crumb among its siblings.
+func (m *Model) reorder(delta int) {
+	selected := m.Selected()
+	if selected == nil {
+		return
+	}
+	oldPath := selected.Path
+	siblingDir := filepath.Dir(oldPath)
+
+	// Remember which siblings were collapsed by name, since IDs change
+	before := m.childCollapsed(siblingDir)
+
+	newPath, err := crumb.Reorder(oldPath, delta)
+	if err != nil {
+		m.status = "error: " + err.Error()
+		return
+	}
+	if newPath == oldPath {
+		return
+	}
+
+	m.restoreChildCollapsed(siblingDir, before)
+	m.status = "moved to " + filepath.Base(newPath)
+	m.reloadAt(newPath)
+}
+
+// childCollapsed returns which of a directory's children are collapsed, keyed by crumb name.
+func (m *Model) childCollapsed(dir string) map[string]bool {
+	state := make(map[string]bool)
+	children, _ := crumb.ListChildDirs(dir)
+	for _, child := range children {
+		_, name := crumb.ParseDir(filepath.Base(child))
+		state[name] = m.collapsed[child]
+	}
+	return state
+}
+
+// restoreChildCollapsed reapplies collapsed state by crumb name after IDs change.
+func (m *Model) restoreChildCollapsed(dir string, state map[string]bool) {
+	children, _ := crumb.ListChildDirs(dir)
+	for _, child := range children {
+		_, name := crumb.ParseDir(filepath.Base(child))
+		if state[name] {
+			m.collapsed[child] = true
+		} else {
+			delete(m.collapsed, child)
+		}
+	}
+}
+
+// renameCollapsed carries collapsed state from an old crumb path to a new one.
+func (m *Model) renameCollapsed(oldPath, newPath string) {
+	for path := range m.collapsed {
+		if path == oldPath || strings.HasPrefix(path, oldPath+string(filepath.Separator)) {
+			delete(m.collapsed, path)
+			m.collapsed[newPath+strings.TrimPrefix(path, oldPath)] = true
+		}
+	}
+}
+
+// reload reloads the tree, reporting failures in the status line.
+func (m *Model) reload() {
+	if err := m.Reload(); err != nil {
+		m.status = "error: " + err.Error()
+	}
+}
+
+// reloadAt reloads the tree and moves the cursor to path.
+func (m *Model) reloadAt(path string) {
+	m.reload()
+	m.selectPath(path)
+}
+
+// moveCursor moves the cursor by delta rows, clamped to the tree.
+func (m *Model) moveCursor(delta int) {
+	m.cursor += delta
+	m.clampCursor()
+}
+
+// clampCursor keeps the cursor on a visible row.
+func (m *Model) clampCursor() {
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+}
+
+// selectPath moves the cursor to the crumb at path, expanding its ancestors.
+func (m *Model) selectPath(path string) {
+	if path == "" {
+		m.clampCursor()
+		return
+	}
+	for dir := filepath.Dir(path); strings.HasPrefix(dir, m.root) && dir != m.root; dir = filepath.Dir(dir) {
+		delete(m.collapsed, dir)
+	}
+	m.buildRows()
+	for i, r := range m.rows {
+		if r.crumb.Path == path {
+			m.cursor = i
+			return
+		}
+	}
+	m.clampCursor()
+}
+
+// selectParent moves the cursor to the parent of the selected crumb.
+func (m *Model) selectParent() {
+	if m.cursor >= len(m.rows) {
+		return
+	}
+	depth := m.rows[m.cursor].depth
+	for i := m.cursor - 1; i >= 0; i-- {
+		if m.rows[i].depth < depth {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// buildRows flattens the expanded part of the tree into visible rows.
+func (m *Model) buildRows() {
+	m.rows = m.rows[:0]
+	var walk func(c *crumb.Crumb, depth int)
+	walk = func(c *crumb.Crumb, depth int) {
+		m.rows = append(m.rows, row{crumb: c, depth: depth})
+		if m.collapsed[c.Path] {
+			return
+		}
+		for i := range c.Children {
+			walk(&c.Children[i], depth+1)
+		}
+	}
+	if m.tree != nil {
+		walk(m.tree, 0)
+	}
+	m.clampCursor()
+}
+
+// View renders the browser: tree pane, README preview pane and status lines.
+func (m *Model) View() string {
+	width, height := m.Width, m.Height
+	if width < 40 {
+		width = 40
+	}
+	if height < 8 {
+		height = 8
+	}
+
+	bodyHeight := height - 3 // header + status + help
+	treeWidth := width * 2 / 5
+	previewWidth := width - treeWidth - 3
+
+	// Keep the cursor visible
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+bodyHeight {
+		m.offset = m.cursor - bodyHeight + 1
+	}
+
+	treeLines := m.treeLines(treeWidth)
+	previewLines := m.previewLines(previewWidth)
+
+	var sb strings.Builder
+	sb.WriteString(fit(fmt.Sprintf("crumbler tui - %d crumb(s)", len(m.allPaths())), width) + "\n")
+	for i := 0; i < bodyHeight; i++ {
+		left := ""
+		if i+m.offset < len(treeLines) {
+			left = treeLines[i+m.offset]
+		}
+		right := ""
+		if i < len(previewLines) {
+			right = previewLines[i]
+		}
+		sb.WriteString(fit(left, treeWidth) + " │ " + fit(right, previewWidth) + "\n")
+	}
+	sb.WriteString(fit(m.statusLine(), width) + "\n")
+	sb.WriteString(fit("↑↓ move  ←→ collapse/expand  J/K reorder  r rename  a add  d delete  e edit  q quit", width))
+	return sb.String()
+}
+
+// treeLines renders every visible row of the tree pane.
+func (m *Model) treeLines(width int) []string {
+	lines := make([]string, len(m.rows))
+	for i, r := range m.rows {
+		marker := "  "
+		if len(r.crumb.Children) > 0 {
+			if m.collapsed[r.crumb.Path] {
+				marker = "▸ "
+			} else {
+				marker = "▾ "
+			}
+		}
+		name := crumb.CrumblerDir + "/"
+		if r.crumb.ID != "" {
+			name = crumb.FormatDir(r.crumb.ID, r.crumb.Name) + "/"
+		}
+		if r.crumb.Path == m.current {
+			name += " ←"
+		}
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		lines[i] = cursor + strings.Repeat("  ", r.depth) + marker + name
+	}
+	return lines
+}
+
+// previewLines renders the README of the selected crumb, wrapped to width.
+func (m *Model) previewLines(width int) []string {
+	selected := m.Selected()
+	if selected == nil {
+		return []string{"(no crumbs)"}
+	}
+
+	lines := []string{relPath(m.root, filepath.Join(selected.Path, crumb.ReadmeFile)), ""}
+	content, err := selected.GetReadme()
+	if err != nil {
+		return append(lines, "error: "+err.Error())
+	}
+	if strings.TrimSpace(content) == "" {
+		return append(lines, "(README is empty)")
+	}
+	for _, line := range strings.Split(strings.TrimRight(content, "\n"), "\n") {
+		lines = append(lines, wrap(strings.ReplaceAll(line, "\t", "    "), width)...)
+	}
+	return lines
+}
+
+// statusLine returns the prompt for the current mode or the last status message.
+func (m *Model) statusLine() string {
+	switch m.mode {
+	case modeRename:
+		return "Rename to: " + string(m.input) + "_"
+	case modeCreate:
+		return "New child crumb name: " + string(m.input) + "_"
+	case modeConfirmDelete:
+		selected := m.Selected()
+		descendants := len(pathsUnder(selected)) - 1
+		return fmt.Sprintf("Delete %s and %d descendant(s)? (y/n)", relPath(m.root, selected.Path), descendants)
+	}
+	return m.status
+}
+
+// allPaths returns the paths of every crumb in the tree.
+func (m *Model) allPaths() []string {
+	if m.tree == nil {
+		return nil
+	}
+	return pathsUnder(m.tree)
+}
+
+// pathsUnder returns the paths of a crumb and all of its descendants.
+func pathsUnder(c *crumb.Crumb) []string {
+	paths := []string{c.Path}
+	for i := range c.Children {
+		paths = append(paths, pathsUnder(&c.Children[i])...)
+	}
+	return paths
+}
+
+// fit truncates or pads s to exactly width runes.
+func fit(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		if width <= 1 {
+			return string(r[:width])
+		}
+		return string(r[:width-1]) + "…"
+	}
+	return s + strings.Repeat(" ", width-len(r))
+}
+
+// wrap splits a line into chunks of at most width runes.
+func wrap(line string, width int) []string {
+	r := []rune(line)
+	if len(r) <= width || width <= 0 {
+		return []string{line}
+	}
+	var lines []string
+	for len(r) > width {
+		lines = append(lines, string(r[:width]))
+		r = r[width:]
+	}
+	return append(lines, string(r))
+}
+
+// runHook runs a hook with its output kept off the browser's screen. A
+// failing hook's error includes the last line it printed.
+func runHook(payload hooks.Payload) error {
+	var out bytes.Buffer
+	err := hooks.RunTo(payload, &out)
+	if err == nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if last := lines[len(lines)-1]; last != "" {
+		return fmt.Errorf("%w: %s", err, last)
+	}
+	return err
+}
+
+// relPath returns a path relative to the project root.
+func relPath(root, fullPath string) string {
+	rel, err := filepath.Rel(crumb.ProjectRoot(root), fullPath)
+	if err != nil {
+		return fullPath
+	}
+	return rel
+}
@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+// setupProject creates a project with a small crumb tree.
+//
+//	.crumbler/
+//	├── 01-setup/
+//	│   └── 01-database/
+//	└── 02-features/
+func setupProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{"", "01-setup", "01-setup/01-database", "02-features"} {
+		path := filepath.Join(root, crumb.CrumblerDir, dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "# " + filepath.Base(path)
+		if err := os.WriteFile(filepath.Join(path, crumb.ReadmeFile), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// press sends a sequence of keys to the model.
+func press(m *Model, keys ...string) Command {
+	var cmd Command
+	for _, key := range keys {
+		cmd = m.Update(key)
+	}
+	return cmd
+}
+
+// typeText sends each rune of text as a key.
+func typeText(m *Model, text string) {
+	for _, r := range text {
+		m.Update(string(r))
+	}
+}
+
+func TestNavigation(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if got := len(m.rows); got != 4 {
+		t.Fatalf("expected all 4 crumbs visible, got %d", got)
+	}
+
+	press(m, "down", "down")
+	if m.Selected().Name != "database" {
+		t.Errorf("selected = %q, want database", m.Selected().Name)
+	}
+
+	// Left on a leaf goes to its parent, left again collapses it
+	press(m, "left")
+	if m.Selected().Name != "setup" {
+		t.Errorf("selected = %q, want setup", m.Selected().Name)
+	}
+	press(m, "left")
+	if got := len(m.rows); got != 3 {
+		t.Errorf("expected 3 rows after collapse, got %d", got)
+	}
+	press(m, "right")
+	if got := len(m.rows); got != 4 {
+		t.Errorf("expected 4 rows after expand, got %d", got)
+	}
+
+	if cmd := press(m, "q"); cmd != CommandQuit {
+		t.Errorf("q should quit, got %v", cmd)
+	}
+}
+
+func TestView(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	m.Width, m.Height = 100, 12
+	press(m, "down")
+
+	view := m.View()
+	lines := strings.Split(view, "\n")
+	if len(lines) != 12 {
+		t.Errorf("view has %d lines, want 12", len(lines))
+	}
+	if !strings.Contains(view, "> ") || !strings.Contains(view, "01-setup/") {
+		t.Errorf("expected cursor on 01-setup, got:\n%s", view)
+	}
+	if !strings.Contains(view, "01-database/ ←") {
+		t.Errorf("expected current crumb marker, got:\n%s", view)
+	}
+	if !strings.Contains(view, "# 01-setup") {
+		t.Errorf("expected README preview of selected crumb, got:\n%s", view)
+	}
+}
+
+func TestRenameAndCreate(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	// Rename 02-features
+	press(m, "down", "down", "down", "r")
+	for range "features" {
+		press(m, "backspace")
+	}
+	typeText(m, "Polish UI")
+	press(m, "enter")
+
+	renamed := filepath.Join(root, crumb.CrumblerDir, "02-polish-ui")
+	if _, err := os.Stat(renamed); err != nil {
+		t.Fatalf("expected renamed crumb: %v (status %q)", err, m.status)
+	}
+	if m.Selected().Path != renamed {
+		t.Errorf("cursor should follow renamed crumb, got %s", m.Selected().Path)
+	}
+
+	// Add a child under it
+	press(m, "a")
+	typeText(m, "Buttons")
+	press(m, "enter")
+	if _, err := os.Stat(filepath.Join(renamed, "01-buttons", crumb.ReadmeFile)); err != nil {
+		t.Fatalf("expected created child: %v (status %q)", err, m.status)
+	}
+}
+
+func TestCreateRespectsChildLimit(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	parent := filepath.Join(root, crumb.CrumblerDir, "02-features")
+	for i := 0; i < crumb.MaxChildren; i++ {
+		if _, err := crumb.CreateAt(parent, "task"+string(rune('a'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	m.selectPath(parent)
+	press(m, "a")
+	typeText(m, "One Too Many")
+	press(m, "enter")
+
+	if !strings.Contains(m.status, "full") {
+		t.Errorf("expected child limit error, got status %q", m.status)
+	}
+}
+
+func TestReorder(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	press(m, "down", "J")
+	moved := filepath.Join(root, crumb.CrumblerDir, "02-setup")
+	if m.Selected().Path != moved {
+		t.Fatalf("selected = %s, want %s (status %q)", m.Selected().Path, moved, m.status)
+	}
+	if _, err := os.Stat(filepath.Join(root, crumb.CrumblerDir, "01-features")); err != nil {
+		t.Errorf("sibling should take the old ID: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(moved, "01-database")); err != nil {
+		t.Errorf("children should move with the crumb: %v", err)
+	}
+}
+
+func TestDeleteSubtree(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	setup := filepath.Join(root, crumb.CrumblerDir, "01-setup")
+
+	// Anything but y cancels
+	press(m, "down", "d", "n")
+	if _, err := os.Stat(setup); err != nil {
+		t.Fatal("crumb should survive a cancelled delete")
+	}
+
+	press(m, "d")
+	if !strings.Contains(m.View(), "1 descendant(s)") {
+		t.Errorf("expected confirmation prompt, got:\n%s", m.View())
+	}
+	press(m, "y")
+	if _, err := os.Stat(setup); !os.IsNotExist(err) {
+		t.Error("subtree should be deleted")
+	}
+	if got := len(m.rows); got != 2 {
+		t.Errorf("expected 2 rows after delete, got %d", got)
+	}
+}
+
+// writeHook installs an executable hook script in the project.
+func writeHook(t *testing.T, root, event, script string) {
+	t.Helper()
+	dir := filepath.Join(root, crumb.CrumblerDir, "hooks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, event), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+
+	t.Run("pre-hooks abort", func(t *testing.T) {
+		t.Parallel()
+		root := setupProject(t)
+		writeHook(t, root, "pre-create", "echo not now; exit 1")
+		writeHook(t, root, "pre-delete", "echo not now; exit 1")
+		m, err := New(root)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+
+		press(m, "down", "down", "down", "a")
+		typeText(m, "Buttons")
+		press(m, "enter")
+		if _, err := os.Stat(filepath.Join(root, crumb.CrumblerDir, "02-features", "01-buttons")); !os.IsNotExist(err) {
+			t.Error("pre-create hook should abort the create")
+		}
+		if !strings.Contains(m.status, "create aborted") || !strings.Contains(m.status, "not now") {
+			t.Errorf("status = %q, want the hook failure", m.status)
+		}
+
+		press(m, "d", "y")
+		if _, err := os.Stat(filepath.Join(root, crumb.CrumblerDir, "02-features")); err != nil {
+			t.Error("pre-delete hook should abort the delete")
+		}
+		if !strings.Contains(m.status, "delete aborted") {
+			t.Errorf("status = %q, want the hook failure", m.status)
+		}
+	})
+
+	t.Run("post-hooks run", func(t *testing.T) {
+		t.Parallel()
+		root := setupProject(t)
+		writeHook(t, root, "post-create", `echo "created $CRUMBLER_CRUMB_PATH" >> hooks.log; echo noise`)
+		writeHook(t, root, "post-delete", `echo "deleted $CRUMBLER_CRUMB_PATH" >> hooks.log; exit 1`)
+		m, err := New(root)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+
+		press(m, "down", "down", "down", "a")
+		typeText(m, "Buttons")
+		press(m, "enter")
+		press(m, "d", "y")
+		if !strings.Contains(m.status, "deleted") || !strings.Contains(m.status, "warning") {
+			t.Errorf("status = %q, want a post-delete warning", m.status)
+		}
+
+		log, err := os.ReadFile(filepath.Join(root, "hooks.log"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "created .crumbler/02-features\ndeleted .crumbler/02-features/01-buttons\n"
+		if string(log) != want {
+			t.Errorf("hooks.log = %q, want %q", log, want)
+		}
+	})
+}
+
+func TestEdit(t *testing.T) {
+	t.Parallel()
+
+	root := setupProject(t)
+	m, err := New(root)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	if cmd := press(m, "down", "e"); cmd != CommandEdit {
+		t.Fatalf("e should request edit, got %v", cmd)
+	}
+	expected := filepath.Join(root, crumb.CrumblerDir, "01-setup", crumb.ReadmeFile)
+	if m.EditPath != expected {
+		t.Errorf("EditPath = %s, want %s", m.EditPath, expected)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	keys := ParseKeys([]byte("\x1b[A\x1b[Bj\r\x7f\x03\x1bé"))
+	expected := []string{"up", "down", "j", "enter", "backspace", "ctrl+c", "esc", "é"}
+	if strings.Join(keys, ",") != strings.Join(expected, ",") {
+		t.Errorf("ParseKeys() = %v, want %v", keys, expected)
+	}
+}
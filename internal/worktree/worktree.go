@@ -0,0 +1,262 @@
+// Package worktree manages git worktrees for working leaf crumbs in parallel.
+// Each leaf crumb checked out for parallel work gets its own branch and
+// worktree, so agents running side by side never share a checkout.
+package worktree
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+const (
+	// BranchPrefix is the prefix of every branch created for a crumb.
+	BranchPrefix = "crumb/"
+
+	// pathSeparator joins crumb path segments in a branch name.
+	// Kebabify collapses repeated hyphens, so "--" never appears inside a segment.
+	pathSeparator = "--"
+)
+
+// Worktree is a git worktree checked out for a leaf crumb.
+type Worktree struct {
+	Path   string // Worktree directory
+	Branch string // Branch name (without refs/heads/)
+	Crumb  string // Crumb path relative to project root (e.g. .crumbler/01-setup/01-db)
+}
+
+// BranchName returns the branch name for a crumb path relative to the project root.
+// ".crumbler/01-setup/01-db" → "crumb/01-setup--01-db"
+func BranchName(crumbRel string) string {
+	rel := strings.TrimPrefix(filepath.ToSlash(crumbRel), crumb.CrumblerDir)
+	rel = strings.Trim(rel, "/")
+	return BranchPrefix + strings.ReplaceAll(rel, "/", pathSeparator)
+}
+
+// CrumbPath returns the crumb path (relative to project root) for a branch name.
+// Returns false if the branch was not created for a crumb.
+// "crumb/01-setup--01-db" → ".crumbler/01-setup/01-db"
+func CrumbPath(branch string) (string, bool) {
+	if !strings.HasPrefix(branch, BranchPrefix) {
+		return "", false
+	}
+	rel := strings.TrimPrefix(branch, BranchPrefix)
+	if rel == "" {
+		return "", false
+	}
+	segments := strings.Split(rel, pathSeparator)
+	for _, segment := range segments {
+		if id, _ := crumb.ParseDir(segment); id == "" {
+			return "", false
+		}
+	}
+	return filepath.Join(append([]string{crumb.CrumblerDir}, segments...)...), true
+}
+
+// DefaultDir returns the default worktree directory for a crumb: a sibling
+// of the project root named after the project and the crumb.
+// "/src/app" + ".crumbler/01-setup/01-db" → "/src/app-crumb-01-setup--01-db"
+func DefaultDir(root, crumbRel string) string {
+	slug := strings.TrimPrefix(BranchName(crumbRel), BranchPrefix)
+	return filepath.Join(filepath.Dir(root), filepath.Base(root)+"-crumb-"+slug)
+}
+
+// Start creates a branch and worktree for the leaf crumb at crumbPath.
+// If dir is empty, DefaultDir is used.
+func Start(root, crumbPath, dir string) (*Worktree, error) {
+	crumbRel, err := leafRelPath(root, crumbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	branch := BranchName(crumbRel)
+	if dir == "" {
+		dir = DefaultDir(root, crumbRel)
+	}
+
+	existing, err := List(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, wt := range existing {
+		if wt.Branch == branch {
+			return nil, fmt.Errorf("crumb %s is already checked out at %s", crumbRel, wt.Path)
+		}
+	}
+
+	if _, err := git(root, "worktree", "add", "-b", branch, dir); err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+
+	return &Worktree{Path: absDir, Branch: branch, Crumb: crumbRel}, nil
+}
+
+// Finish deletes the crumb in the main tree once its branch has been
+// merged, then removes its worktree and branch. deleteCrumb does the
+// delete, given the main tree's project root, so it can verify and run
+// hooks like any other; if it fails, the worktree and branch are kept.
+// root may be the project root in the main tree or in any worktree.
+// If crumbRel is empty, the crumb is inferred from the branch checked out in root.
+func Finish(root, crumbRel string, deleteCrumb func(root, crumbRel string) error) (*Worktree, error) {
+	if crumbRel == "" {
+		branch, err := git(root, "rev-parse", "--abbrev-ref", "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		rel, ok := CrumbPath(branch)
+		if !ok {
+			return nil, fmt.Errorf("branch %q is not a crumb branch (expected %s*)", branch, BranchPrefix)
+		}
+		crumbRel = rel
+	}
+	branch := BranchName(crumbRel)
+
+	mainRoot, err := mainProjectRoot(root)
+	if err != nil {
+		return nil, err
+	}
+
+	worktrees, err := List(mainRoot)
+	if err != nil {
+		return nil, err
+	}
+	var wt *Worktree
+	for i := range worktrees {
+		if worktrees[i].Branch == branch {
+			wt = &worktrees[i]
+			break
+		}
+	}
+	if wt == nil {
+		return nil, fmt.Errorf("no worktree found for crumb %s (branch %s)", crumbRel, branch)
+	}
+
+	// The branch must be merged into whatever the main tree has checked out
+	if _, err := git(mainRoot, "merge-base", "--is-ancestor", branch, "HEAD"); err != nil {
+		return nil, fmt.Errorf("branch %s is not merged into the main tree yet", branch)
+	}
+
+	// The merge may already have removed the crumb if the agent deleted it
+	if _, err := os.Stat(filepath.Join(mainRoot, crumbRel)); err == nil {
+		if err := deleteCrumb(mainRoot, crumbRel); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := git(mainRoot, "worktree", "remove", wt.Path); err != nil {
+		return nil, err
+	}
+	if _, err := git(mainRoot, "branch", "-d", branch); err != nil {
+		return nil, err
+	}
+
+	return wt, nil
+}
+
+// List returns the worktrees that have a crumb branch checked out.
+func List(root string) ([]Worktree, error) {
+	out, err := git(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktreeList(out), nil
+}
+
+// parseWorktreeList parses `git worktree list --porcelain` output,
+// keeping only worktrees on crumb branches.
+func parseWorktreeList(out string) []Worktree {
+	var worktrees []Worktree
+	var path string
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path = strings.TrimPrefix(line, "worktree ")
+		case strings.HasPrefix(line, "branch "):
+			branch := strings.TrimPrefix(strings.TrimPrefix(line, "branch "), "refs/heads/")
+			if crumbRel, ok := CrumbPath(branch); ok {
+				worktrees = append(worktrees, Worktree{Path: path, Branch: branch, Crumb: crumbRel})
+			}
+		}
+	}
+	return worktrees
+}
+
+// mainProjectRoot maps a project root inside any worktree to the
+// corresponding project root in the main worktree.
+func mainProjectRoot(root string) (string, error) {
+	out, err := git(root, "worktree", "list", "--porcelain")
+	if err != nil {
+		return "", err
+	}
+	// The main worktree is always listed first
+	mainTop := strings.TrimPrefix(strings.SplitN(out, "\n", 2)[0], "worktree ")
+
+	top, err := git(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(canonical(top), canonical(root))
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(mainTop, rel), nil
+}
+
+// leafRelPath validates that crumbPath is a leaf crumb under root and returns
+// its path relative to root.
+func leafRelPath(root, crumbPath string) (string, error) {
+	crumblerPath := filepath.Join(root, crumb.CrumblerDir)
+	rel, err := filepath.Rel(crumblerPath, crumbPath)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("%s is not a crumb under %s", crumbPath, crumblerPath)
+	}
+
+	if _, err := os.Stat(filepath.Join(crumbPath, crumb.ReadmeFile)); err != nil {
+		return "", fmt.Errorf("%s is not a crumb (no %s)", crumbPath, crumb.ReadmeFile)
+	}
+
+	children, err := crumb.ListChildDirs(crumbPath)
+	if err != nil {
+		return "", err
+	}
+	if len(children) > 0 {
+		return "", fmt.Errorf("only leaf crumbs can be checked out (has %d children)", len(children))
+	}
+
+	return filepath.Join(crumb.CrumblerDir, rel), nil
+}
+
+// canonical resolves symlinks so paths from git and the OS compare equal.
+func canonical(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// git runs a git command in dir and returns its trimmed stdout.
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
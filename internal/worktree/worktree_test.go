@@ -0,0 +1,222 @@
+package worktree
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+)
+
+func TestBranchName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		crumbRel string
+		expected string
+	}{
+		{".crumbler/01-setup", "crumb/01-setup"},
+		{".crumbler/01-setup/01-db", "crumb/01-setup--01-db"},
+		{".crumbler/02-features/03-auth/01-login", "crumb/02-features--03-auth--01-login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.crumbRel, func(t *testing.T) {
+			if got := BranchName(tt.crumbRel); got != tt.expected {
+				t.Errorf("BranchName(%q) = %q, want %q", tt.crumbRel, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCrumbPath(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		branch   string
+		expected string
+		ok       bool
+	}{
+		{"crumb/01-setup--01-db", filepath.Join(".crumbler", "01-setup", "01-db"), true},
+		{"crumb/01-setup", filepath.Join(".crumbler", "01-setup"), true},
+		{"main", "", false},
+		{"crumb/", "", false},
+		{"crumb/feature-x", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got, ok := CrumbPath(tt.branch)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("CrumbPath(%q) = (%q, %v), want (%q, %v)", tt.branch, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseWorktreeList(t *testing.T) {
+	t.Parallel()
+
+	out := `worktree /src/app
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/app-crumb-01-setup--01-db
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/crumb/01-setup--01-db
+
+worktree /src/app-detached
+HEAD 3333333333333333333333333333333333333333
+detached
+`
+
+	worktrees := parseWorktreeList(out)
+	if len(worktrees) != 1 {
+		t.Fatalf("expected 1 crumb worktree, got %d", len(worktrees))
+	}
+	wt := worktrees[0]
+	if wt.Path != "/src/app-crumb-01-setup--01-db" {
+		t.Errorf("Path = %q", wt.Path)
+	}
+	if wt.Branch != "crumb/01-setup--01-db" {
+		t.Errorf("Branch = %q", wt.Branch)
+	}
+	if wt.Crumb != filepath.Join(".crumbler", "01-setup", "01-db") {
+		t.Errorf("Crumb = %q", wt.Crumb)
+	}
+}
+
+// setupGitProject creates a git repository with a committed crumb tree.
+func setupGitProject(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := filepath.Join(t.TempDir(), "app")
+	for _, dir := range []string{
+		filepath.Join(root, crumb.CrumblerDir, "01-setup", "01-db"),
+		filepath.Join(root, crumb.CrumblerDir, "01-setup", "02-api"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, dir := range []string{"", "01-setup", "01-setup/01-db", "01-setup/02-api"} {
+		path := filepath.Join(root, crumb.CrumblerDir, dir, crumb.ReadmeFile)
+		if err := os.WriteFile(path, []byte("task"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runGit(t, root, "init", "-q", "-b", "main")
+	runGit(t, root, "add", "-A")
+	runGit(t, root, "commit", "-q", "-m", "plan")
+	return root
+}
+
+// deleteAt deletes a crumb without verifying or running hooks.
+func deleteAt(root, crumbRel string) error {
+	return crumb.DeleteAt(filepath.Join(root, crumbRel))
+}
+
+// runGit runs a git command with a fixed identity, failing the test on error.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestStartAndFinish(t *testing.T) {
+	t.Parallel()
+
+	root := setupGitProject(t)
+	leaf := filepath.Join(root, crumb.CrumblerDir, "01-setup", "02-api")
+
+	wt, err := Start(root, leaf, "")
+	if err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	if wt.Branch != "crumb/01-setup--02-api" {
+		t.Errorf("Branch = %q", wt.Branch)
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, crumb.CrumblerDir)); err != nil {
+		t.Errorf("worktree should contain the crumb tree: %v", err)
+	}
+
+	worktrees, err := List(root)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(worktrees) != 1 || worktrees[0].Crumb != wt.Crumb {
+		t.Fatalf("List() = %+v, want the started worktree", worktrees)
+	}
+
+	// Starting the same crumb twice is refused
+	if _, err := Start(root, leaf, ""); err == nil {
+		t.Error("expected error starting an already checked out crumb")
+	}
+
+	// Finishing before the branch is merged is refused
+	if err := os.WriteFile(filepath.Join(wt.Path, "api.go"), []byte("package api\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, wt.Path, "add", "-A")
+	runGit(t, wt.Path, "commit", "-q", "-m", "api")
+	if _, err := Finish(root, wt.Crumb, deleteAt); err == nil {
+		t.Fatal("expected error finishing an unmerged branch")
+	}
+
+	runGit(t, root, "merge", "-q", "--no-edit", wt.Branch)
+
+	// A failed delete keeps the worktree and branch to try again
+	failed := errors.New("verification failed")
+	refuse := func(string, string) error { return failed }
+	if _, err := Finish(root, wt.Crumb, refuse); !errors.Is(err, failed) {
+		t.Fatalf("Finish() error = %v, want the delete's", err)
+	}
+	if _, err := os.Stat(wt.Path); err != nil {
+		t.Errorf("worktree should be kept when the delete fails: %v", err)
+	}
+
+	// Finish from inside the worktree infers the crumb from the branch
+	var deleted string
+	del := func(mainRoot, crumbRel string) error {
+		deleted = crumbRel
+		return deleteAt(mainRoot, crumbRel)
+	}
+	if _, err := Finish(wt.Path, "", del); err != nil {
+		t.Fatalf("Finish() error: %v", err)
+	}
+	if deleted != wt.Crumb {
+		t.Errorf("deleted %q, want %q", deleted, wt.Crumb)
+	}
+	if _, err := os.Stat(leaf); !os.IsNotExist(err) {
+		t.Error("crumb should be deleted in the main tree")
+	}
+	if _, err := os.Stat(wt.Path); !os.IsNotExist(err) {
+		t.Error("worktree directory should be removed")
+	}
+	worktrees, err = List(root)
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	if len(worktrees) != 0 {
+		t.Errorf("expected no worktrees after finish, got %+v", worktrees)
+	}
+}
+
+func TestStartRejectsBranch(t *testing.T) {
+	t.Parallel()
+
+	root := setupGitProject(t)
+	branch := filepath.Join(root, crumb.CrumblerDir, "01-setup")
+
+	if _, err := Start(root, branch, ""); err == nil {
+		t.Error("expected error starting a worktree for a branch crumb")
+	}
+}
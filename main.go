@@ -8,6 +8,6 @@ import (
 
 func main() {
 	if err := crumbler.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(crumbler.ExitCode(err))
 	}
 }
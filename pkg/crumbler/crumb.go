@@ -0,0 +1,117 @@
+package crumbler
+
+import (
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/prompt"
+)
+
+// State is what an agent should do with the current crumb.
+type State string
+
+// Crumb states.
+const (
+	// StateDecompose means the crumb's README is empty: plan it first.
+	StateDecompose State = crumb.StateDecompose
+	// StateExecute means the crumb's README has content: do the work.
+	StateExecute State = crumb.StateExecute
+	// StateDone means no crumbs remain.
+	StateDone State = crumb.StateDone
+)
+
+// Crumb is a unit of work: a directory with a README.md.
+type Crumb struct {
+	Path     string   // Full filesystem path
+	RelPath  string   // Path relative to the project root
+	ID       string   // Two-digit ID (01-10); empty for the root crumb
+	Name     string   // Kebab-case name; empty for the root crumb
+	IsLeaf   bool     // True if the crumb has no children
+	Children []*Crumb // Child crumbs in ID order (only filled by List and Find)
+
+	src *crumb.Crumb // Internal crumb this was converted from
+}
+
+// fromInternal converts an internal crumb tree.
+func fromInternal(c *crumb.Crumb) *Crumb {
+	out := &Crumb{
+		Path:    c.Path,
+		RelPath: c.RelPath,
+		ID:      c.ID,
+		Name:    c.Name,
+		IsLeaf:  c.IsLeaf,
+		src:     c,
+	}
+	for i := range c.Children {
+		out.Children = append(out.Children, fromInternal(&c.Children[i]))
+	}
+	return out
+}
+
+// toInternal converts back to an internal crumb tree.
+func (c *Crumb) toInternal() *crumb.Crumb {
+	out := &crumb.Crumb{
+		Path:    c.Path,
+		RelPath: c.RelPath,
+		ID:      c.ID,
+		Name:    c.Name,
+		IsLeaf:  c.IsLeaf,
+	}
+	for _, child := range c.Children {
+		out.Children = append(out.Children, *child.toInternal())
+	}
+	return out
+}
+
+// internal returns the internal crumb for c, reusing the one it was
+// converted from (which may carry a Snapshot's README) if c still names it.
+func (c *Crumb) internal() *crumb.Crumb {
+	if c.src != nil && c.src.Path == c.Path && c.src.Name == c.Name && c.src.ID == c.ID {
+		return c.src
+	}
+	return c.toInternal()
+}
+
+// Readme returns the contents of the crumb's README.md.
+// Crumbs from a Snapshot return the contents as of the snapshot.
+func (c *Crumb) Readme() (string, error) {
+	return c.internal().GetReadme()
+}
+
+// WriteReadme replaces the contents of the crumb's README.md.
+func (c *Crumb) WriteReadme(content string) error {
+	return c.internal().WriteReadme(content)
+}
+
+// State returns StateDecompose if the crumb's README is empty and
+// StateExecute otherwise.
+func (c *Crumb) State() State {
+	return State(c.internal().State())
+}
+
+// DisplayName returns a human-readable name ("add-auth" → "Add Auth").
+func (c *Crumb) DisplayName() string {
+	return c.internal().DisplayName()
+}
+
+// Tree renders the crumb and its children as the text tree printed by
+// 'crumbler status', marking the crumb at currentPath (full path) with
+// "← current".
+func (c *Crumb) Tree(currentPath string) string {
+	return prompt.FormatTreeWithCurrent(c.toInternal(), "", false, currentPath)
+}
+
+// TreeWithLabels renders the tree like Tree, appending the label returned
+// for each crumb to its line instead of the current marker.
+func (c *Crumb) TreeWithLabels(label func(c *Crumb) string) string {
+	byPath := make(map[string]*Crumb)
+	var index func(c *Crumb)
+	index = func(c *Crumb) {
+		byPath[c.Path] = c
+		for _, child := range c.Children {
+			index(child)
+		}
+	}
+	index(c)
+	return prompt.FormatTreeWithLabels(c.toInternal(), func(ic *crumb.Crumb) string {
+		return label(byPath[ic.Path])
+	})
+}
@@ -0,0 +1,303 @@
+// Package crumbler is the public Go API for crumbler projects, for tools
+// that want to drive a crumb tree without shelling out to the CLI.
+//
+// A Project is opened from a root directory (the directory containing
+// .crumbler/). Its methods behave exactly like the matching CLI commands,
+// including VERIFY checks and lifecycle hooks; the crumbler CLI itself is a
+// thin client of this package.
+//
+// Compatibility: this package follows semantic versioning. Within a major
+// version, exported identifiers are not removed or changed incompatibly, and
+// the errors listed in errors.go keep matching with errors.Is / errors.As.
+// New methods, fields and options may be added in minor versions.
+package crumbler
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/hooks"
+	"github.com/waynenilsen/crumbler/internal/prompt"
+)
+
+// Project is a crumbler project rooted at a directory.
+// A Project holds no state besides its root: every method reads the tree
+// from disk, so several Projects (or processes) can share one root.
+type Project struct {
+	root string
+
+	// Stderr receives warnings that don't fail an operation, such as a
+	// failing post-create or post-delete hook. Defaults to os.Stderr.
+	Stderr io.Writer
+}
+
+// Open returns the project rooted at root. The root does not need a
+// .crumbler directory yet; Create initializes it.
+func Open(root string) (*Project, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project root: %w", err)
+	}
+	return &Project{root: abs, Stderr: os.Stderr}, nil
+}
+
+// OpenPlan returns a named plan of the project rooted at projectRoot.
+// The plan lives in .crumbler-plans/<name>/; "" or "default" opens the
+// project's own .crumbler/ tree.
+func OpenPlan(projectRoot, name string) (*Project, error) {
+	p, err := Open(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if p.root, err = crumb.PlanRoot(p.root, name); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Root returns the project's root directory.
+func (p *Project) Root() string {
+	return p.root
+}
+
+// Done reports whether all work is complete (no .crumbler directory).
+func (p *Project) Done() (bool, error) {
+	return crumb.IsDone(p.root)
+}
+
+// Current returns the crumb to work on next: the first leaf in depth-first
+// order. Returns nil when the project is done.
+func (p *Project) Current() (*Crumb, error) {
+	snap, err := p.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Current(), nil
+}
+
+// List returns the whole crumb tree, starting at the root crumb.
+// Returns nil when the project is done.
+func (p *Project) List() (*Crumb, error) {
+	snap, err := p.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	return snap.List(), nil
+}
+
+// Count returns the number of crumbs, including the root crumb.
+func (p *Project) Count() (int, error) {
+	snap, err := p.Snapshot()
+	if err != nil {
+		return 0, err
+	}
+	return snap.Count(), nil
+}
+
+// Find returns the crumb at path, given relative to the project root
+// (".crumbler/01-setup") or to the .crumbler directory ("01-setup").
+// Returns an error matching ErrNotFound if there is no such crumb.
+func (p *Project) Find(path string) (*Crumb, error) {
+	c, err := crumb.Find(p.root, path)
+	if err != nil {
+		return nil, err
+	}
+	return fromInternal(c), nil
+}
+
+// Create creates sibling crumbs under the current crumb, initializing the
+// project if needed, and returns them in order. The pre-create hook runs
+// first and can abort; the post-create hook runs afterwards.
+func (p *Project) Create(names ...string) ([]*Crumb, error) {
+	parent, err := crumb.GetCurrent(p.root)
+	if err != nil {
+		return nil, err
+	}
+	payload := hooks.NewPayload(p.root, hooks.PreCreate, parent)
+	payload.Names = names
+	if err := hooks.Run(payload); err != nil {
+		return nil, fmt.Errorf("create aborted: %w", hookError(err))
+	}
+
+	paths, err := crumb.CreateMultiple(p.root, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create crumb(s): %w", err)
+	}
+
+	var created []*Crumb
+	payload.Event = hooks.PostCreate
+	payload.Names = nil
+	for _, path := range paths {
+		c, err := crumb.Find(p.root, path)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, fromInternal(c))
+		payload.Created = append(payload.Created, hookPath(p.root, path))
+	}
+	p.warn(hooks.Run(payload))
+
+	return created, nil
+}
+
+// DeleteOptions controls Delete.
+type DeleteOptions struct {
+	// Path deletes the crumb at this path, as accepted by Find, instead of
+	// the current crumb. It must be a leaf.
+	Path string
+
+	// SkipVerifyReason deletes without running VERIFY commands. The reason
+	// is recorded in the project's skip log. Ignored when empty.
+	SkipVerifyReason string
+
+	// VerifyTimeout limits each verification command (default 10 minutes).
+	VerifyTimeout time.Duration
+
+	// OnVerify, if set, is called before each verification command runs.
+	OnVerify func(command string)
+}
+
+// DeleteResult describes a completed Delete.
+type DeleteResult struct {
+	// Deleted is the crumb that was deleted.
+	Deleted *Crumb
+	// Verified lists the verification commands that passed.
+	Verified []string
+	// Skipped lists the verification commands skipped with a reason.
+	Skipped []string
+	// Done reports whether the project is now complete.
+	Done bool
+}
+
+// Delete deletes the current crumb (or opts.Path), marking its work done.
+//
+// Verification commands declared in VERIFY files by the crumb and its
+// ancestors run first; if one fails the crumb is kept and the error is a
+// *VerifyError. The pre-delete hook then runs and can abort, and the
+// post-delete hook runs after deleting. Returns an error matching ErrNoCrumb
+// when the project is already done.
+func (p *Project) Delete(opts DeleteOptions) (*DeleteResult, error) {
+	var current *crumb.Crumb
+	var err error
+	if opts.Path != "" {
+		current, err = crumb.Find(p.root, opts.Path)
+	} else {
+		current, err = crumb.GetCurrent(p.root)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNoCrumb
+	}
+	// Refuse before verifying, rather than after running the hooks
+	if len(current.Children) > 0 {
+		return nil, fmt.Errorf("%w (has %d children)", ErrHasChildren, len(current.Children))
+	}
+	result := &DeleteResult{Deleted: fromInternal(current)}
+
+	commands, err := crumb.VerifyCommands(p.root, current.Path)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) > 0 {
+		if opts.SkipVerifyReason != "" {
+			skip := crumb.VerifySkip{Crumb: result.Deleted.RelPath, Reason: opts.SkipVerifyReason, Commands: commands}
+			if err := crumb.RecordVerifySkip(p.root, skip); err != nil {
+				return nil, err
+			}
+			result.Skipped = commands
+		} else {
+			for _, command := range commands {
+				if opts.OnVerify != nil {
+					opts.OnVerify(command)
+				}
+				if err := crumb.RunVerify(p.root, []string{command}, opts.VerifyTimeout); err != nil {
+					return nil, fmt.Errorf("refusing to delete crumb: %w", verifyError(err))
+				}
+			}
+			result.Verified = commands
+		}
+	}
+
+	payload := hooks.NewPayload(p.root, hooks.PreDelete, current)
+	if err := hooks.Run(payload); err != nil {
+		return nil, fmt.Errorf("delete aborted: %w", hookError(err))
+	}
+
+	if err := crumb.DeleteAt(current.Path); err != nil {
+		return nil, fmt.Errorf("failed to delete crumb: %w", err)
+	}
+
+	// Post-delete receives the deleted crumb and the state it had.
+	// It cannot run once the root crumb (and with it .crumbler/hooks) is gone.
+	payload.Event = hooks.PostDelete
+	p.warn(hooks.Run(payload))
+
+	if result.Done, err = crumb.IsDone(p.root); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PromptOptions controls Prompt.
+type PromptOptions struct {
+	// NoPreamble skips the preamble section.
+	NoPreamble bool
+	// NoPostamble skips the postamble section.
+	NoPostamble bool
+	// NoContext skips the context section (README contents).
+	NoContext bool
+	// Minimal uses the minimal preamble and postamble.
+	Minimal bool
+}
+
+// Prompt generates the agent prompt for the current crumb, the same text
+// 'crumbler prompt' prints. The pre-prompt hook runs first and can abort.
+// The tree is read once, before the hook runs.
+func (p *Project) Prompt(opts PromptOptions) (string, error) {
+	snap, err := crumb.TakeSnapshot(p.root)
+	if err != nil {
+		return "", err
+	}
+	if err := hooks.Run(hooks.NewPayload(p.root, hooks.PrePrompt, snap.Current())); err != nil {
+		return "", fmt.Errorf("prompt aborted: %w", hookError(err))
+	}
+
+	output, err := prompt.GenerateSnapshotPrompt(snap, &prompt.Config{
+		NoPreamble:  opts.NoPreamble,
+		NoPostamble: opts.NoPostamble,
+		NoContext:   opts.NoContext,
+		Minimal:     opts.Minimal,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate prompt: %w", err)
+	}
+	return output, nil
+}
+
+// warn reports a non-fatal error.
+func (p *Project) warn(err error) {
+	if err == nil {
+		return
+	}
+	out := p.Stderr
+	if out == nil {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "warning: %v\n", err)
+}
+
+// hookPath returns a path relative to the project directory, as hooks see
+// it.
+func hookPath(root, fullPath string) string {
+	rel, err := filepath.Rel(crumb.ProjectRoot(root), fullPath)
+	if err != nil {
+		return fullPath
+	}
+	return rel
+}
@@ -0,0 +1,269 @@
+package crumbler_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/waynenilsen/crumbler/pkg/crumbler"
+)
+
+// open returns a project in a fresh temp directory.
+func open(t *testing.T) *crumbler.Project {
+	t.Helper()
+	p, err := crumbler.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Stderr = &bytes.Buffer{}
+	return p
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	p := open(t)
+	if done, err := p.Done(); err != nil || !done {
+		t.Fatalf("Done() = %v, %v; want true for an empty project", done, err)
+	}
+	if current, err := p.Current(); err != nil || current != nil {
+		t.Fatalf("Current() = %v, %v; want nil", current, err)
+	}
+
+	created, err := p.Create("Setup DB", "Add Auth")
+	if err != nil {
+		t.Fatalf("Create() error: %v", err)
+	}
+	if len(created) != 2 || created[0].RelPath != filepath.Join(".crumbler", "01-setup-db") {
+		t.Fatalf("Create() = %v", created)
+	}
+	if err := created[0].WriteReadme("# Setup DB\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := p.Count()
+	if err != nil || count != 3 {
+		t.Errorf("Count() = %d, %v; want 3", count, err)
+	}
+
+	current, err := p.Current()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Name != "setup-db" || current.State() != crumbler.StateExecute {
+		t.Errorf("Current() = %+v, state %s", current, current.State())
+	}
+
+	tree, err := p.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Children) != 2 || !strings.Contains(tree.Tree(current.Path), "← current") {
+		t.Errorf("List() tree:\n%s", tree.Tree(current.Path))
+	}
+	labeled := tree.TreeWithLabels(func(c *crumbler.Crumb) string { return " #" + c.ID })
+	if !strings.Contains(labeled, "02-add-auth/ #02") {
+		t.Errorf("TreeWithLabels():\n%s", labeled)
+	}
+
+	found, err := p.Find("02-add-auth")
+	if err != nil || found.DisplayName() != "Add Auth" || found.State() != crumbler.StateDecompose {
+		t.Errorf("Find() = %+v, %v", found, err)
+	}
+
+	snap, err := p.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error: %v", err)
+	}
+	if snap.Done() || snap.Count() != 3 || snap.Current().Path != current.Path || len(snap.List().Children) != 2 {
+		t.Errorf("Snapshot() = done %v, count %d, current %v", snap.Done(), snap.Count(), snap.Current())
+	}
+	if readme, _ := snap.Current().Readme(); readme != "# Setup DB\n" {
+		t.Errorf("snapshot README = %q", readme)
+	}
+
+	text, err := p.Prompt(crumbler.PromptOptions{Minimal: true})
+	if err != nil || !strings.Contains(text, "# Setup DB") {
+		t.Errorf("Prompt() = %q, %v", text, err)
+	}
+
+	result, err := p.Delete(crumbler.DeleteOptions{})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted.Name != "setup-db" || result.Done {
+		t.Errorf("Delete() = %+v", result)
+	}
+}
+
+func TestOpenPlan(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	p, err := crumbler.OpenPlan(dir, "debt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, ".crumbler-plans", "debt"); p.Root() != filepath.Clean(want) {
+		t.Errorf("Root() = %s, want %s", p.Root(), want)
+	}
+
+	created, err := p.Create("Task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(".crumbler-plans", "debt", ".crumbler", "01-task"); created[0].RelPath != want {
+		t.Errorf("RelPath = %s, want %s", created[0].RelPath, want)
+	}
+
+	if _, err := crumbler.OpenPlan(dir, "Not Kebab"); err == nil {
+		t.Error("OpenPlan() should reject invalid plan names")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		run  func(p *crumbler.Project) error
+		want error
+	}{
+		{
+			name: "delete when done",
+			run: func(p *crumbler.Project) error {
+				_, err := p.Delete(crumbler.DeleteOptions{})
+				return err
+			},
+			want: crumbler.ErrNoCrumb,
+		},
+		{
+			name: "invalid name",
+			run: func(p *crumbler.Project) error {
+				_, err := p.Create("!!!")
+				return err
+			},
+			want: crumbler.ErrInvalidName,
+		},
+		{
+			name: "missing crumb",
+			run: func(p *crumbler.Project) error {
+				if _, err := p.Create("Task"); err != nil {
+					return err
+				}
+				_, err := p.Find("09-missing")
+				return err
+			},
+			want: crumbler.ErrNotFound,
+		},
+		{
+			name: "directory full",
+			run: func(p *crumbler.Project) error {
+				_, err := p.Create(strings.Split(strings.Repeat("task,", 11), ",")[:11]...)
+				return err
+			},
+			want: crumbler.ErrDirectoryFull,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if err := tt.run(open(t)); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteVerify(t *testing.T) {
+	t.Parallel()
+
+	p := open(t)
+	created, err := p.Create("Task")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(created[0].Path, "VERIFY"), []byte("echo broken; exit 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = p.Delete(crumbler.DeleteOptions{})
+	var verr *crumbler.VerifyError
+	if !errors.As(err, &verr) || !strings.Contains(verr.Output, "broken") {
+		t.Fatalf("Delete() error = %v, want a VerifyError", err)
+	}
+	if _, err := os.Stat(created[0].Path); err != nil {
+		t.Error("crumb should be kept when verification fails")
+	}
+
+	result, err := p.Delete(crumbler.DeleteOptions{SkipVerifyReason: "flaky"})
+	if err != nil {
+		t.Fatalf("Delete() with skip reason error: %v", err)
+	}
+	if len(result.Skipped) != 1 || len(result.Verified) != 0 {
+		t.Errorf("Delete() = %+v", result)
+	}
+}
+
+func TestDeletePath(t *testing.T) {
+	t.Parallel()
+
+	p := open(t)
+	created, err := p.Create("First", "Second")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(created[1].Path, "VERIFY"), []byte("exit 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A crumb other than the current one is verified like it
+	var verr *crumbler.VerifyError
+	if _, err := p.Delete(crumbler.DeleteOptions{Path: created[1].RelPath}); !errors.As(err, &verr) {
+		t.Fatalf("Delete() error = %v, want a VerifyError", err)
+	}
+	result, err := p.Delete(crumbler.DeleteOptions{Path: created[1].RelPath, SkipVerifyReason: "flaky"})
+	if err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if result.Deleted.RelPath != created[1].RelPath {
+		t.Errorf("deleted %s, want %s", result.Deleted.RelPath, created[1].RelPath)
+	}
+	if _, err := os.Stat(created[0].Path); err != nil {
+		t.Error("the current crumb should be kept")
+	}
+
+	if _, err := p.Delete(crumbler.DeleteOptions{Path: ".crumbler"}); !errors.Is(err, crumbler.ErrHasChildren) {
+		t.Errorf("Delete() of a parent error = %v, want ErrHasChildren", err)
+	}
+}
+
+func TestHookError(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks are shell scripts")
+	}
+
+	p := open(t)
+	if _, err := p.Create("Task"); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(p.Root(), ".crumbler", "hooks")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pre-delete"), []byte("#!/bin/sh\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := p.Delete(crumbler.DeleteOptions{})
+	var herr *crumbler.HookError
+	if !errors.As(err, &herr) || herr.Event != "pre-delete" {
+		t.Fatalf("Delete() error = %v, want a HookError", err)
+	}
+}
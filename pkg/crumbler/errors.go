@@ -0,0 +1,76 @@
+package crumbler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/waynenilsen/crumbler/internal/crumb"
+	"github.com/waynenilsen/crumbler/internal/hooks"
+)
+
+// Errors returned (wrapped) by Project methods. Match them with errors.Is.
+var (
+	// ErrNoCrumb means there is no crumb to operate on: the project is done.
+	ErrNoCrumb = crumb.ErrNoCrumb
+	// ErrHasChildren means a crumb cannot be deleted until its children are.
+	ErrHasChildren = crumb.ErrHasChildren
+	// ErrDirectoryFull means a crumb already has the maximum of 10 children.
+	ErrDirectoryFull = crumb.ErrDirectoryFull
+	// ErrInvalidName means a crumb name is empty after kebabifying.
+	ErrInvalidName = crumb.ErrInvalidName
+	// ErrNotFound means a path does not name a crumb.
+	ErrNotFound = crumb.ErrNotFound
+	// ErrExists means a crumb with the same directory name already exists.
+	ErrExists = crumb.ErrExists
+)
+
+// VerifyError reports a failed or timed-out verification command.
+// Match it with errors.As; Command and Output describe the failure.
+type VerifyError struct {
+	Command string // Command that failed
+	Output  string // Combined stdout and stderr
+	Err     error  // Underlying exit or timeout error
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("verification failed: %s: %v", e.Command, e.Err)
+}
+
+func (e *VerifyError) Unwrap() error {
+	return e.Err
+}
+
+// HookError reports a lifecycle hook that exited unsuccessfully.
+// Match it with errors.As; Event names the hook.
+type HookError struct {
+	Event string // Hook event, e.g. pre-create
+	Err   error  // Underlying exit error
+}
+
+func (e *HookError) Error() string {
+	return fmt.Sprintf("%s hook failed: %v", e.Event, e.Err)
+}
+
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// verifyError converts a verification failure from internal/crumb to a
+// *VerifyError. Other errors are returned unchanged.
+func verifyError(err error) error {
+	var verr *crumb.VerifyError
+	if !errors.As(err, &verr) {
+		return err
+	}
+	return &VerifyError{Command: verr.Command, Output: verr.Output, Err: verr.Err}
+}
+
+// hookError converts a hook failure from internal/hooks to a *HookError.
+// Other errors are returned unchanged.
+func hookError(err error) error {
+	var herr *hooks.Error
+	if !errors.As(err, &herr) {
+		return err
+	}
+	return &HookError{Event: herr.Event, Err: herr.Err}
+}
@@ -0,0 +1,58 @@
+package crumbler
+
+import "github.com/waynenilsen/crumbler/internal/crumb"
+
+// Snapshot is the crumb tree as read in a single walk. It answers Current,
+// Count, List, Find and each crumb's README from memory, which makes it the
+// cheapest way to show a whole tree. A Snapshot does not follow later
+// changes; take a new one instead.
+type Snapshot struct {
+	snap    *crumb.Snapshot
+	tree    *Crumb
+	current *Crumb
+}
+
+// Snapshot reads the project's crumb tree in one walk.
+func (p *Project) Snapshot() (*Snapshot, error) {
+	snap, err := crumb.TakeSnapshot(p.root)
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{snap: snap}
+	if tree := snap.List(); tree != nil {
+		s.tree = fromInternal(tree)
+	}
+	if current := snap.Current(); current != nil {
+		s.current = fromInternal(current)
+	}
+	return s, nil
+}
+
+// Done reports whether all work was complete.
+func (s *Snapshot) Done() bool {
+	return s.snap.Done()
+}
+
+// Current returns the crumb to work on next, or nil when the project is done.
+func (s *Snapshot) Current() *Crumb {
+	return s.current
+}
+
+// List returns the whole crumb tree, or nil when the project is done.
+func (s *Snapshot) List() *Crumb {
+	return s.tree
+}
+
+// Count returns the number of crumbs, including the root crumb.
+func (s *Snapshot) Count() int {
+	return s.snap.Count()
+}
+
+// Find returns the crumb at path, accepting the same paths as Project.Find.
+func (s *Snapshot) Find(path string) (*Crumb, error) {
+	c, err := s.snap.Find(path)
+	if err != nil {
+		return nil, err
+	}
+	return fromInternal(c), nil
+}